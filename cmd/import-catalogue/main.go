@@ -0,0 +1,54 @@
+// Command import-catalogue imports products into the local product catalogue
+// that barcodes are looked up in, from a CSV with a header row. Open Food
+// Facts CSV dumps (which are tab separated) can be imported as is:
+//
+//	go run ./cmd/import-catalogue -file en.openfoodfacts.org.products.csv -delimiter tab
+//
+// Other CSVs need a code (or barcode/upc/ean) column and a product_name (or
+// name) column, and can have brand, size and category columns
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"unicode/utf8"
+
+	// Autoload env variables from .env
+	_ "github.com/joho/godotenv/autoload"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/catalogue"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+)
+
+func main() {
+	path := flag.String("file", "", "path to the CSV to import")
+	delimiter := flag.String("delimiter", ",", "field delimiter (use \"tab\" for tab separated files)")
+	flag.Parse()
+
+	if *path == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	comma := '\t'
+	if *delimiter != "tab" {
+		r, size := utf8.DecodeRuneInString(*delimiter)
+		if size == 0 || size != len(*delimiter) {
+			log.Fatal("[import-catalogue] delimiter must be a single character or \"tab\"")
+		}
+		comma = r
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		log.Fatal("[import-catalogue] ", err)
+	}
+	defer file.Close()
+
+	db.Factory()
+	result, err := catalogue.ImportCSV(file, comma)
+	if err != nil {
+		log.Fatal("[import-catalogue] ", err)
+	}
+	log.Printf("[import-catalogue] Imported %d products (skipped %d rows)\n", result.Imported, result.Skipped)
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/storage"
+)
+
+// BlobHandler serves blobs stored by the local storage backend, as long as
+// the request's URL was signed by it and hasn't expired
+func BlobHandler() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		backend, err := storage.Default()
+		local, ok := backend.(*storage.Local)
+		if err != nil || !ok {
+			http.NotFound(response, request)
+			return
+		}
+
+		key := strings.TrimPrefix(request.URL.Path, storage.LocalPathPrefix)
+		query := request.URL.Query()
+		file, err := local.Open(key, query.Get("expires"), query.Get("signature"))
+		if err != nil {
+			http.Error(response, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		response.Header().Set("Cache-Control", "private, max-age=3600")
+		http.ServeFile(response, request, file)
+	}
+}
@@ -18,8 +18,9 @@ func GraphQLHandler() http.HandlerFunc {
 		opts := handler.NewRequestOptions(request)
 
 		rootValue := map[string]interface{}{
-			"Authorization": request.Header.Get("Authorization"),
-			"App-Scheme":    request.Header.Get("App-Scheme"),
+			"Authorization":   request.Header.Get("Authorization"),
+			"App-Scheme":      request.Header.Get("App-Scheme"),
+			"Accept-Language": request.Header.Get("Accept-Language"),
 		}
 		params := graphql.Params{
 			Schema:         gql.Schema,
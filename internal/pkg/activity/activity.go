@@ -0,0 +1,208 @@
+package activity
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"reflect"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// Entity types recorded in the store activity log
+const (
+	EntityItem       = "item"
+	EntityTrip       = "trip"
+	EntityStore      = "store"
+	EntityStoreUser  = "store_user"
+	EntityStapleItem = "staple_item"
+)
+
+// DefaultLimit and MaxLimit bound the number of entries returned by RetrieveStoreActivity
+const (
+	DefaultLimit = 25
+	MaxLimit     = 100
+)
+
+// Entity identifies the record that an action was performed on
+type Entity struct {
+	Type string
+	ID   uuid.UUID
+	Name string
+}
+
+// Change holds the before and after values of a changed attribute
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Record appends an entry to a store's activity log. Pass the transaction the
+// action was performed in (or db.Manager) as tx, so that the entry is only
+// kept if the action is
+//
+// The activity log is secondary to the action being recorded, so failures
+// are logged rather than returned
+func Record(tx *gorm.DB, storeID uuid.UUID, userID uuid.UUID, action string, entity Entity, changes map[string]Change) {
+	entry := models.StoreActivity{
+		StoreID:    storeID,
+		UserID:     userID,
+		Action:     action,
+		EntityType: entity.Type,
+		EntityName: entity.Name,
+	}
+	if entity.ID != uuid.Nil {
+		entry.EntityID = &entity.ID
+	}
+	if len(changes) > 0 {
+		encoded, err := json.Marshal(changes)
+		if err != nil {
+			log.Printf("[activity] %v: %v\n", action, err)
+			return
+		}
+		entry.Changes = encoded
+	}
+	create := func(tx *gorm.DB) error {
+		return tx.Create(&entry).Error
+	}
+	var err error
+	if _, ok := tx.Statement.ConnPool.(gorm.TxCommitter); ok {
+		// A failed insert would abort the caller's transaction, so the entry
+		// is created in a savepoint
+		err = tx.Transaction(create)
+	} else {
+		err = create(tx)
+	}
+	if err != nil {
+		log.Printf("[activity] %v: %v\n", action, err)
+	}
+}
+
+// RecordForTrip appends an entry to the activity log of the store that the
+// trip belongs to
+func RecordForTrip(tx *gorm.DB, tripID uuid.UUID, userID uuid.UUID, action string, entity Entity, changes map[string]Change) {
+	var trip models.GroceryTrip
+	if err := tx.Select("store_id").Where("id = ?", tripID).First(&trip).Error; err != nil {
+		log.Printf("[activity] %v: %v\n", action, err)
+		return
+	}
+	Record(tx, trip.StoreID, userID, action, entity, changes)
+}
+
+// Diff compares the before and after values of each attribute and returns
+// the ones that changed
+func Diff(before map[string]interface{}, after map[string]interface{}) map[string]Change {
+	changes := make(map[string]Change)
+	for key, afterValue := range after {
+		beforeValue := before[key]
+		if !reflect.DeepEqual(beforeValue, afterValue) {
+			changes[key] = Change{Before: beforeValue, After: afterValue}
+		}
+	}
+	for key, beforeValue := range before {
+		if _, ok := after[key]; !ok {
+			changes[key] = Change{Before: beforeValue, After: nil}
+		}
+	}
+	return changes
+}
+
+// ItemAttributes returns the attributes of an item that are tracked in the activity log
+func ItemAttributes(item models.Item) map[string]interface{} {
+	attributes := map[string]interface{}{
+		"name":     item.Name,
+		"quantity": item.Quantity,
+		"position": item.Position,
+	}
+	if item.Completed != nil {
+		attributes["completed"] = *item.Completed
+	}
+	if item.Notes != nil {
+		attributes["notes"] = *item.Notes
+	}
+	if item.CategoryID != nil {
+		attributes["categoryId"] = item.CategoryID.String()
+	}
+	if item.DecimalQuantity != nil {
+		attributes["decimalQuantity"] = *item.DecimalQuantity
+	}
+	if item.Unit != nil {
+		attributes["unit"] = *item.Unit
+	}
+	if item.UnitPrice != nil {
+		attributes["unitPrice"] = *item.UnitPrice
+	}
+	if item.Currency != nil {
+		attributes["currency"] = *item.Currency
+	}
+	if item.AssigneeID != nil {
+		attributes["assigneeId"] = item.AssigneeID.String()
+	}
+	return attributes
+}
+
+// StapleItemAttributes returns the attributes of a staple item that are
+// tracked in the activity log
+func StapleItemAttributes(staple models.StoreStapleItem) map[string]interface{} {
+	attributes := map[string]interface{}{
+		"name":     staple.Name,
+		"quantity": staple.Quantity,
+		"cadence":  staple.Cadence,
+		"interval": staple.Interval,
+		"weekdays": staple.Weekdays,
+	}
+	if staple.DecimalQuantity != nil {
+		attributes["decimalQuantity"] = *staple.DecimalQuantity
+	}
+	if staple.Unit != nil {
+		attributes["unit"] = *staple.Unit
+	}
+	if staple.Notes != nil {
+		attributes["notes"] = *staple.Notes
+	}
+	if staple.StoreCategoryID != nil {
+		attributes["storeCategoryId"] = staple.StoreCategoryID.String()
+	}
+	return attributes
+}
+
+// RetrieveStoreActivity retrieves a page of a store's activity log, newest
+// first. The user must be an active member of the store
+func RetrieveStoreActivity(storeID interface{}, userID uuid.UUID, limit int, offset int) (entries []models.StoreActivity, err error) {
+	var count int64
+	memberQuery := db.Manager.
+		Model(&models.StoreUser{}).
+		Where("store_id = ? AND user_id = ? AND active = ?", storeID, userID, true).
+		Count(&count).
+		Error
+	if err := memberQuery; err != nil {
+		return entries, err
+	}
+	if count == 0 {
+		return entries, errors.New("user is not active in this store")
+	}
+
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	query := db.Manager.
+		Where("store_id = ?", storeID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).
+		Error
+	if err := query; err != nil {
+		return entries, err
+	}
+	return entries, nil
+}
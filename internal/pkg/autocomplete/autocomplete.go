@@ -0,0 +1,144 @@
+package autocomplete
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Sources of completions, from the store's own history to the vocabulary of
+// the embedded FoodClassification.json file
+const (
+	SourceHistory         = "history"
+	SourceStaple          = "staple"
+	SourceCategorySetting = "categorySetting"
+	SourceVocabulary      = "vocabulary"
+)
+
+const (
+	// DefaultLimit is how many completions are returned by default
+	DefaultLimit = 10
+	// MaxLimit is the most completions that can be returned
+	MaxLimit = 50
+)
+
+// Completion is a completed item name, along with the category the item
+// would be added to
+type Completion struct {
+	Name         string
+	CategoryName string
+	Source       string
+}
+
+// candidate is a term matching a prefix, and how it ranks
+type candidate struct {
+	term  *term
+	full  bool
+	score int
+	store bool
+}
+
+// Complete returns the completions of an item name prefix for a store, best
+// first. The store's own item names (in the user's spelling, if they've added
+// them) rank above the vocabulary, and names that start with the prefix rank
+// above names with a later word that does
+func Complete(userID uuid.UUID, storeID interface{}, prefix string, limit int) (completions []Completion, err error) {
+	store, err := stores.RetrieveStoreForUser(storeID, userID)
+	if err != nil {
+		return completions, errors.New("store not found")
+	}
+	completions = []Completion{}
+	prefix = normalise(prefix)
+	if prefix == "" {
+		return completions, nil
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	ix, err := storeIndexFor(store.ID)
+	if err != nil {
+		return completions, err
+	}
+	var candidates []candidate
+	for _, m := range ix.search(prefix) {
+		candidates = append(candidates, candidate{term: m.term, full: m.full, score: score(m.term, userID), store: true})
+	}
+	for _, m := range vocabulary().search(prefix) {
+		if _, ok := ix.byKey[m.term.key]; ok {
+			continue
+		}
+		candidates = append(candidates, candidate{term: m.term, full: m.full})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.store != b.store {
+			return a.store
+		}
+		if a.full != b.full {
+			return a.full
+		}
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if len(a.term.key) != len(b.term.key) {
+			return len(a.term.key) < len(b.term.key)
+		}
+		return a.term.key < b.term.key
+	})
+
+	for i := 0; i < len(candidates) && i < limit; i++ {
+		t := candidates[i].term
+		name := t.name
+		if spelling, ok := t.spellings[userID]; ok {
+			name = spelling
+		}
+		completions = append(completions, Completion{
+			Name:         name,
+			CategoryName: ix.categoryName(t),
+			Source:       t.source,
+		})
+	}
+	return completions, nil
+}
+
+// score ranks a store's term for a user: the user's own additions count
+// double, and staple items and saved category settings get a boost
+func score(t *term, userID uuid.UUID) int {
+	score := t.count + t.counts[userID]
+	if t.staple {
+		score += 5
+	}
+	if t.storeCategoryID != nil {
+		score += 2
+	}
+	return score
+}
+
+// categoryName predicts the category an item with the term's name would be
+// added to, the same way models.TokenCategorizer does: the store settings,
+// then the food classifier (if the store has that category), then Misc.
+func (ix *storeIndex) categoryName(t *term) string {
+	if t.storeCategoryID != nil {
+		if name, ok := ix.categories[*t.storeCategoryID]; ok {
+			return name
+		}
+		return models.MiscCategoryName
+	}
+	if result, ok := models.FoodClassifier().Classify(t.key); ok && ix.categoryNames[result.Label] {
+		return result.Label
+	}
+	return models.MiscCategoryName
+}
+
+// normalise normalises an item name for completion
+func normalise(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
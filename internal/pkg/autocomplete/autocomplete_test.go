@@ -0,0 +1,165 @@
+package autocomplete
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type Suite struct {
+	suite.Suite
+
+	DB   *gorm.DB
+	mock sqlmock.Sqlmock
+}
+
+func (s *Suite) SetupSuite() {
+	var (
+		dbMock *sql.DB
+		err    error
+	)
+
+	dbMock, s.mock, err = sqlmock.New()
+	require.NoError(s.T(), err)
+	s.DB, err = gorm.Open(postgres.New(postgres.Config{Conn: dbMock}), &gorm.Config{})
+	require.NoError(s.T(), err)
+
+	db.Manager = s.DB
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) expectStoreForUser(storeID uuid.UUID, userID uuid.UUID) {
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(storeID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+}
+
+func (s *Suite) expectStoreIndex(storeID uuid.UUID, userID uuid.UUID) {
+	s.mock.ExpectQuery("^SELECT \"id\" FROM \"grocery_trips\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4().String()))
+
+	now := time.Now()
+	s.mock.ExpectQuery("^SELECT items.name, items.user_id, COUNT(.+) FROM \"items\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "user_id", "count", "last_added_at"}).
+			AddRow("oat milk", userID, 1, now.Add(-48*time.Hour)).
+			AddRow("Oat Milk", uuid.NewV4(), 2, now))
+	s.mock.ExpectQuery("^SELECT \"name\" FROM \"store_staple_items\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Oatly Barista"))
+
+	bakeryID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT id, name FROM \"store_categories\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(uuid.NewV4(), "Dairy").
+			AddRow(bakeryID, "Bakery").
+			AddRow(uuid.NewV4(), "Misc."))
+	items := fmt.Sprintf(`{"oat bread": "%s"}`, bakeryID)
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_item_category_settings\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "items"}).AddRow(uuid.NewV4(), storeID, items))
+}
+
+func (s *Suite) TestComplete_RanksAndPredictsCategories() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.expectStoreForUser(storeID, userID)
+	s.expectStoreIndex(storeID, userID)
+
+	completions, err := Complete(userID, storeID, " OAT", 4)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []Completion{
+		{Name: "Oatly Barista", CategoryName: "Misc.", Source: SourceStaple},
+		{Name: "oat milk", CategoryName: "Dairy", Source: SourceHistory},
+		{Name: "oat bread", CategoryName: "Bakery", Source: SourceCategorySetting},
+		{Name: "oats", CategoryName: "Misc.", Source: SourceVocabulary},
+	}, completions)
+}
+
+func (s *Suite) TestComplete_UsesCachedIndexUntilInvalidated() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.expectStoreForUser(storeID, userID)
+	s.expectStoreIndex(storeID, userID)
+	_, err := Complete(userID, storeID, "oat", 0)
+	require.NoError(s.T(), err)
+
+	// The second search only checks the user can access the store
+	otherUserID := uuid.NewV4()
+	s.expectStoreForUser(storeID, otherUserID)
+	completions, err := Complete(otherUserID, storeID, "milk", 0)
+	require.NoError(s.T(), err)
+	require.NotEmpty(s.T(), completions)
+	assert.Equal(s.T(), "Oat Milk", completions[0].Name)
+
+	Invalidate(storeID)
+	s.expectStoreForUser(storeID, userID)
+	s.expectStoreIndex(storeID, userID)
+	_, err = Complete(userID, storeID, "oat", 0)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestComplete_RebuildsExpiredIndex() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.expectStoreForUser(storeID, userID)
+	s.expectStoreIndex(storeID, userID)
+	_, err := Complete(userID, storeID, "oat", 0)
+	require.NoError(s.T(), err)
+
+	// Another instance may have written to the store, so an index older than
+	// cacheTTL is rebuilt
+	cache.Lock()
+	cache.stores[storeID].builtAt = time.Now().Add(-cacheTTL)
+	cache.Unlock()
+	s.expectStoreForUser(storeID, userID)
+	s.expectStoreIndex(storeID, userID)
+	_, err = Complete(userID, storeID, "oat", 0)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestComplete_StoreNotFound() {
+	storeID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	_, err := Complete(uuid.NewV4(), storeID, "oat", 0)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "store not found", err.Error())
+}
+
+func (s *Suite) TestIndexSearch_MatchesLaterWords() {
+	ix := &index{byKey: map[string]*term{}}
+	ix.add("Almond Milk", SourceHistory)
+	ix.add("milk chocolate", SourceHistory)
+	ix.add("Bread", SourceHistory)
+	ix.sort()
+
+	matches := ix.search("milk")
+	require.Len(s.T(), matches, 2)
+	assert.Equal(s.T(), "Almond Milk", matches[0].term.name)
+	assert.False(s.T(), matches[0].full)
+	assert.Equal(s.T(), "milk chocolate", matches[1].term.name)
+	assert.True(s.T(), matches[1].full)
+}
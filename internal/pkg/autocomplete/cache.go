@@ -0,0 +1,179 @@
+package autocomplete
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// cacheTTL is how long a store's index is used before it's rebuilt
+const cacheTTL = 5 * time.Minute
+
+// cache holds the index of each store that has been searched since it was
+// last written to. version changes whenever an index is invalidated, so an
+// index built while the store was being written to isn't cached.
+//
+// The cache is per process: it's only invalidated by writes made through this
+// process's gorm callbacks, so writes made by other instances are only seen
+// once an index is older than cacheTTL and is rebuilt
+var cache = struct {
+	sync.RWMutex
+	stores  map[uuid.UUID]*storeIndex
+	trips   map[uuid.UUID]uuid.UUID
+	version int
+}{
+	stores: map[uuid.UUID]*storeIndex{},
+	trips:  map[uuid.UUID]uuid.UUID{},
+}
+
+// storeIndexFor returns the cached index of a store, building it if needed
+func storeIndexFor(storeID uuid.UUID) (*storeIndex, error) {
+	cache.RLock()
+	ix, ok := cache.stores[storeID]
+	version := cache.version
+	cache.RUnlock()
+	if ok && time.Since(ix.builtAt) < cacheTTL {
+		return ix, nil
+	}
+
+	ix, err := buildStoreIndex(storeID)
+	if err != nil {
+		return nil, err
+	}
+	cache.Lock()
+	defer cache.Unlock()
+	if cache.version == version {
+		cache.stores[storeID] = ix
+		for _, tripID := range ix.tripIDs {
+			cache.trips[tripID] = storeID
+		}
+	}
+	return ix, nil
+}
+
+// Invalidate drops the cached index of a store, so it's rebuilt the next time
+// the store is searched
+func Invalidate(storeID uuid.UUID) {
+	cache.Lock()
+	defer cache.Unlock()
+	invalidate(storeID)
+}
+
+// invalidate drops the cached index of a store. The cache must be locked
+func invalidate(storeID uuid.UUID) {
+	cache.version++
+	ix, ok := cache.stores[storeID]
+	if !ok {
+		return
+	}
+	for _, tripID := range ix.tripIDs {
+		delete(cache.trips, tripID)
+	}
+	delete(cache.stores, storeID)
+}
+
+// invalidateAll drops every cached index
+func invalidateAll() {
+	cache.Lock()
+	defer cache.Unlock()
+	cache.version++
+	cache.stores = map[uuid.UUID]*storeIndex{}
+	cache.trips = map[uuid.UUID]uuid.UUID{}
+}
+
+// RegisterCallbacks registers gorm callbacks that invalidate the cached
+// index of a store whenever its items, staple items, categories, category
+// settings or trips are written to
+func RegisterCallbacks(db *gorm.DB) error {
+	callback := func(tx *gorm.DB) {
+		if tx.Error == nil {
+			invalidateFor(tx.Statement, false)
+		}
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("autocomplete:invalidate", callback); err != nil {
+		return err
+	}
+	update := func(tx *gorm.DB) {
+		if tx.Error == nil {
+			invalidateFor(tx.Statement, true)
+		}
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("autocomplete:invalidate", update); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("autocomplete:invalidate", callback)
+}
+
+// invalidateFor invalidates the cached indexes of the stores written to by a
+// statement. When the store can't be told from the statement (i.e. a batch
+// update by condition) every index is invalidated
+func invalidateFor(stmt *gorm.Statement, update bool) {
+	if stmt.Schema == nil {
+		return
+	}
+	switch stmt.Schema.Table {
+	case "items":
+		tripIDs, ok := fieldValues(stmt, "GroceryTripID")
+		if !ok {
+			invalidateAll()
+			return
+		}
+		cache.Lock()
+		defer cache.Unlock()
+		for _, tripID := range tripIDs {
+			// A trip that isn't known belongs to a store that isn't cached
+			if storeID, ok := cache.trips[tripID]; ok {
+				invalidate(storeID)
+			}
+		}
+	case "grocery_trips", "store_staple_items", "store_categories", "store_item_category_settings":
+		storeIDs, ok := fieldValues(stmt, "StoreID")
+		if !ok {
+			// Trips are touched by condition whenever their items change,
+			// which doesn't change which trips belong to which store
+			if update && stmt.Schema.Table == "grocery_trips" {
+				return
+			}
+			invalidateAll()
+			return
+		}
+		cache.Lock()
+		defer cache.Unlock()
+		for _, storeID := range storeIDs {
+			invalidate(storeID)
+		}
+	}
+}
+
+// fieldValues returns the values of a UUID field of the records written to
+// by a statement, and whether they're all known
+func fieldValues(stmt *gorm.Statement, name string) (values []uuid.UUID, ok bool) {
+	field := stmt.Schema.LookUpField(name)
+	if field == nil {
+		return values, false
+	}
+	value := func(rv reflect.Value) bool {
+		v, zero := field.ValueOf(reflect.Indirect(rv))
+		id, isUUID := v.(uuid.UUID)
+		if zero || !isUUID {
+			return false
+		}
+		values = append(values, id)
+		return true
+	}
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			if !value(stmt.ReflectValue.Index(i)) {
+				return values, false
+			}
+		}
+		return values, len(values) > 0
+	case reflect.Struct:
+		return values, value(stmt.ReflectValue)
+	}
+	return values, false
+}
@@ -0,0 +1,235 @@
+package autocomplete
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// term is an item name that can be completed
+type term struct {
+	key  string
+	name string
+	// spellings is the most recent spelling of the name by each user who
+	// has added it, and counts how many times each of them has added it
+	spellings map[uuid.UUID]string
+	counts    map[uuid.UUID]int
+	count     int
+	staple    bool
+	// storeCategoryID is the category saved for the name in the store
+	// settings, if there is one
+	storeCategoryID *uuid.UUID
+	source          string
+}
+
+// prefixEntry maps a searchable key of a term to the term. A term is
+// searchable by its name and by its name from each later word onwards, so
+// "milk" completes "almond milk" as well as "milk chocolate"
+type prefixEntry struct {
+	key  string
+	term int
+	// full is whether key is the whole name of the term
+	full bool
+}
+
+// match is a term that matches a prefix
+type match struct {
+	term *term
+	full bool
+}
+
+// index is a sorted prefix index of terms
+type index struct {
+	terms   []*term
+	byKey   map[string]*term
+	entries []prefixEntry
+}
+
+// storeIndex is the index of a store's own item names, along with what's
+// needed to predict the category each one would be added to
+type storeIndex struct {
+	index
+	tripIDs []uuid.UUID
+	// categories maps the store's category IDs to their names, and
+	// categoryNames holds the names
+	categories    map[uuid.UUID]string
+	categoryNames map[string]bool
+	// builtAt is when the index was built, so it can be rebuilt once it's
+	// older than cacheTTL
+	builtAt time.Time
+}
+
+// add adds a term to the index (or returns the existing term with the same
+// key). The index must be sorted once all terms have been added
+func (ix *index) add(name string, source string) *term {
+	key := normalise(name)
+	if t, ok := ix.byKey[key]; ok {
+		return t
+	}
+	t := &term{key: key, name: strings.TrimSpace(name), source: source}
+	ix.terms = append(ix.terms, t)
+	ix.byKey[key] = t
+	return t
+}
+
+// sort builds the sorted prefix entries of the index
+func (ix *index) sort() {
+	ix.entries = ix.entries[:0]
+	for i, t := range ix.terms {
+		ix.entries = append(ix.entries, prefixEntry{key: t.key, term: i, full: true})
+		words := strings.Fields(t.key)
+		for w := 1; w < len(words); w++ {
+			ix.entries = append(ix.entries, prefixEntry{key: strings.Join(words[w:], " "), term: i})
+		}
+	}
+	sort.Slice(ix.entries, func(i, j int) bool {
+		return ix.entries[i].key < ix.entries[j].key
+	})
+}
+
+// search returns the terms with a key (or a word of their key) starting with
+// the normalised prefix provided
+func (ix *index) search(prefix string) (matches []match) {
+	seen := map[int]int{}
+	start := sort.Search(len(ix.entries), func(i int) bool {
+		return ix.entries[i].key >= prefix
+	})
+	for i := start; i < len(ix.entries) && strings.HasPrefix(ix.entries[i].key, prefix); i++ {
+		entry := ix.entries[i]
+		if m, ok := seen[entry.term]; ok {
+			matches[m].full = matches[m].full || entry.full
+			continue
+		}
+		seen[entry.term] = len(matches)
+		matches = append(matches, match{term: ix.terms[entry.term], full: entry.full})
+	}
+	return matches
+}
+
+// historyRow is how many times a user has added an item name to a store
+type historyRow struct {
+	Name        string
+	UserID      uuid.UUID
+	Count       int
+	LastAddedAt time.Time
+}
+
+// buildStoreIndex builds the index of a store's item history, staple items
+// and the item names saved in its category settings
+func buildStoreIndex(storeID uuid.UUID) (ix *storeIndex, err error) {
+	ix = &storeIndex{
+		index:         index{byKey: map[string]*term{}},
+		categories:    map[uuid.UUID]string{},
+		categoryNames: map[string]bool{},
+		builtAt:       time.Now(),
+	}
+
+	var tripIDs []string
+	if err := db.Manager.Model(&models.GroceryTrip{}).Where("store_id = ?", storeID).Pluck("id", &tripIDs).Error; err != nil {
+		return ix, err
+	}
+	for _, id := range tripIDs {
+		ix.tripIDs = append(ix.tripIDs, uuid.FromStringOrNil(id))
+	}
+
+	// The most recently added spelling of each name wins
+	var history []historyRow
+	query := db.Manager.
+		Model(&models.Item{}).
+		Select("items.name, items.user_id, COUNT(*) AS count, MAX(items.created_at) AS last_added_at").
+		Joins("INNER JOIN grocery_trips ON grocery_trips.id = items.grocery_trip_id").
+		Where("grocery_trips.store_id = ? AND grocery_trips.deleted_at IS NULL", storeID).
+		Group("items.name, items.user_id").
+		Order("last_added_at ASC").
+		Scan(&history).
+		Error
+	if err := query; err != nil {
+		return ix, err
+	}
+	for _, row := range history {
+		if normalise(row.Name) == "" {
+			continue
+		}
+		t := ix.add(row.Name, SourceHistory)
+		if t.spellings == nil {
+			t.spellings = map[uuid.UUID]string{}
+			t.counts = map[uuid.UUID]int{}
+		}
+		t.name = strings.TrimSpace(row.Name)
+		t.spellings[row.UserID] = t.name
+		t.counts[row.UserID] += row.Count
+		t.count += row.Count
+	}
+
+	var staples []string
+	if err := db.Manager.Model(&models.StoreStapleItem{}).Where("store_id = ?", storeID).Pluck("name", &staples).Error; err != nil {
+		return ix, err
+	}
+	for _, name := range staples {
+		if normalise(name) == "" {
+			continue
+		}
+		t := ix.add(name, SourceStaple)
+		t.staple = true
+		t.source = SourceStaple
+	}
+
+	var categories []models.StoreCategory
+	if err := db.Manager.Select("id, name").Where("store_id = ?", storeID).Find(&categories).Error; err != nil {
+		return ix, err
+	}
+	for i := range categories {
+		ix.categories[categories[i].ID] = categories[i].Name
+		ix.categoryNames[categories[i].Name] = true
+	}
+
+	var settings models.StoreItemCategorySettings
+	query = db.Manager.Where("store_id = ?", storeID).First(&settings).Error
+	if err := query; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return ix, err
+	}
+	if len(settings.Items) > 0 {
+		var items map[string]interface{}
+		if err := json.Unmarshal(settings.Items, &items); err != nil {
+			return ix, err
+		}
+		for name, value := range items {
+			id, _ := value.(string)
+			storeCategoryID, err := uuid.FromString(id)
+			if err != nil || normalise(name) == "" {
+				continue
+			}
+			t := ix.add(name, SourceCategorySetting)
+			t.storeCategoryID = &storeCategoryID
+		}
+	}
+
+	ix.sort()
+	return ix, nil
+}
+
+var (
+	vocabularyIndex *index
+	vocabularyOnce  sync.Once
+)
+
+// vocabulary returns the index of the food names in the embedded
+// FoodClassification.json file, which is built once
+func vocabulary() *index {
+	vocabularyOnce.Do(func() {
+		vocabularyIndex = &index{byKey: map[string]*term{}}
+		for _, food := range models.Foods() {
+			vocabularyIndex.add(food.Text, SourceVocabulary)
+		}
+		vocabularyIndex.sort()
+	})
+	return vocabularyIndex
+}
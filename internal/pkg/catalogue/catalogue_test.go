@@ -0,0 +1,61 @@
+package catalogue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormaliseCode_UPCA(t *testing.T) {
+	code, err := NormaliseCode("036000291452")
+	require.NoError(t, err)
+	assert.Equal(t, "0036000291452", code)
+}
+
+func TestNormaliseCode_EAN13(t *testing.T) {
+	code, err := NormaliseCode(" 4006381333931 ")
+	require.NoError(t, err)
+	assert.Equal(t, "4006381333931", code)
+}
+
+func TestNormaliseCode_EAN8(t *testing.T) {
+	code, err := NormaliseCode("96385074")
+	require.NoError(t, err)
+	assert.Equal(t, "96385074", code)
+}
+
+func TestNormaliseCode_InvalidCheckDigit(t *testing.T) {
+	_, err := NormaliseCode("4006381333932")
+	require.Error(t, err)
+	assert.Equal(t, "barcode isn't valid", err.Error())
+}
+
+func TestNormaliseCode_InvalidLength(t *testing.T) {
+	_, err := NormaliseCode("12345")
+	require.Error(t, err)
+	assert.Equal(t, "barcode must be 8, 12, 13 or 14 digits long", err.Error())
+}
+
+func TestNormaliseCode_NotDigits(t *testing.T) {
+	_, err := NormaliseCode("40063813339AB")
+	require.Error(t, err)
+	assert.Equal(t, "barcode must only contain digits", err.Error())
+}
+
+func TestParseProduct_OpenFoodFactsColumns(t *testing.T) {
+	indexes := columnIndexes([]string{"code", "url", "product_name", "quantity", "brands"})
+	product, ok := parseProduct([]string{"4006381333931", "https://example.com", "Highlighters", "4 pcs", "Stabilo, Schwan"}, indexes)
+	require.True(t, ok)
+	assert.Equal(t, "4006381333931", product.Code)
+	assert.Equal(t, "Highlighters", product.Name)
+	assert.Equal(t, "Stabilo", *product.Brand)
+	assert.Equal(t, "4 pcs", *product.Size)
+	assert.Nil(t, product.CategoryName)
+}
+
+func TestParseProduct_SkipsRowsWithoutName(t *testing.T) {
+	indexes := columnIndexes([]string{"barcode", "name", "category"})
+	_, ok := parseProduct([]string{"4006381333931", "", "Produce"}, indexes)
+	assert.False(t, ok)
+}
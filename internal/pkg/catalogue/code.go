@@ -0,0 +1,45 @@
+package catalogue
+
+import (
+	"errors"
+	"strings"
+)
+
+// NormaliseCode validates a UPC or EAN barcode and returns it in its
+// canonical form: digits only, with 12 digit UPC-A codes converted to EAN-13
+// (by adding a leading zero) so that a product is found however it's scanned.
+// Codes must be 8, 12, 13 or 14 digits long with a valid check digit
+func NormaliseCode(code string) (string, error) {
+	code = strings.TrimSpace(strings.ReplaceAll(code, " ", ""))
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return "", errors.New("barcode must only contain digits")
+		}
+	}
+	switch len(code) {
+	case 8, 13, 14:
+	case 12:
+		code = "0" + code
+	default:
+		return "", errors.New("barcode must be 8, 12, 13 or 14 digits long")
+	}
+	if !validCheckDigit(code) {
+		return "", errors.New("barcode isn't valid")
+	}
+	return code, nil
+}
+
+// validCheckDigit verifies the last digit of a GTIN code. From the right
+// (excluding the check digit), digits are weighted 3, 1, 3, 1...
+func validCheckDigit(code string) bool {
+	sum := 0
+	for i := len(code) - 2; i >= 0; i-- {
+		digit := int(code[i] - '0')
+		if (len(code)-2-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	check := (10 - sum%10) % 10
+	return check == int(code[len(code)-1]-'0')
+}
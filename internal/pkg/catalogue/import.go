@@ -0,0 +1,166 @@
+package catalogue
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/utils"
+	"gorm.io/gorm/clause"
+)
+
+// importBatchSize is how many products are upserted at a time
+const importBatchSize = 1000
+
+// columns maps the attributes of a product to the CSV headers they can be
+// read from. The Open Food Facts headers are included, so their CSV dumps
+// can be imported as is
+var columns = map[string][]string{
+	"code":     {"code", "barcode", "upc", "ean"},
+	"name":     {"product_name", "name"},
+	"brand":    {"brands", "brand"},
+	"size":     {"quantity", "size"},
+	"category": {"category", "category_name"},
+}
+
+// ImportResult counts the rows of an import
+type ImportResult struct {
+	Imported int
+	Skipped  int
+}
+
+// ImportCSV imports products from a CSV with a header row into the catalogue,
+// updating products that are already in it. Rows without a valid barcode or
+// a name are skipped. Open Food Facts dumps are tab separated, so the
+// delimiter is configurable
+func ImportCSV(r io.Reader, delimiter rune) (result ImportResult, err error) {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return result, err
+	}
+	indexes := columnIndexes(header)
+	if _, ok := indexes["code"]; !ok {
+		return result, errors.New("CSV doesn't have a code column")
+	}
+	if _, ok := indexes["name"]; !ok {
+		return result, errors.New("CSV doesn't have a product name column")
+	}
+
+	var batch []models.Product
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+		product, ok := parseProduct(record, indexes)
+		if !ok {
+			result.Skipped++
+			continue
+		}
+		batch = append(batch, product)
+		if len(batch) == importBatchSize {
+			if err := saveProducts(batch); err != nil {
+				return result, err
+			}
+			result.Imported += len(batch)
+			batch = nil
+		}
+	}
+	if len(batch) > 0 {
+		if err := saveProducts(batch); err != nil {
+			return result, err
+		}
+		result.Imported += len(batch)
+	}
+	return result, nil
+}
+
+// columnIndexes finds the index of each product attribute in the header row
+func columnIndexes(header []string) map[string]int {
+	indexes := make(map[string]int)
+	for attribute, names := range columns {
+		for i, column := range header {
+			if contains(names, strings.ToLower(strings.TrimSpace(column))) {
+				indexes[attribute] = i
+				break
+			}
+		}
+	}
+	return indexes
+}
+
+// parseProduct builds a product from a CSV record
+func parseProduct(record []string, indexes map[string]int) (product models.Product, ok bool) {
+	value := func(attribute string) string {
+		i, ok := indexes[attribute]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	code, err := NormaliseCode(value("code"))
+	if err != nil || value("name") == "" {
+		return product, false
+	}
+	product = models.Product{
+		Code: code,
+		Name: utils.TruncateString(value("name"), 255),
+	}
+	// Open Food Facts lists brands comma separated, the first being the main one
+	if brand := strings.TrimSpace(strings.Split(value("brand"), ",")[0]); brand != "" {
+		brand = utils.TruncateString(brand, 255)
+		product.Brand = &brand
+	}
+	if size := value("size"); size != "" {
+		size = utils.TruncateString(size, 100)
+		product.Size = &size
+	}
+	if category := value("category"); category != "" {
+		category = utils.TruncateString(category, 100)
+		product.CategoryName = &category
+	}
+	return product, true
+}
+
+// saveProducts upserts a batch of products by code
+func saveProducts(products []models.Product) error {
+	// Dumps can list a code more than once, which postgres won't upsert in
+	// the same statement, so the last row for each code wins
+	seen := make(map[string]int)
+	var unique []models.Product
+	for _, product := range products {
+		if i, ok := seen[product.Code]; ok {
+			unique[i] = product
+			continue
+		}
+		seen[product.Code] = len(unique)
+		unique = append(unique, product)
+	}
+	return db.Manager.
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "code"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "brand", "size", "category_name", "updated_at"}),
+		}).
+		Create(&unique).
+		Error
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
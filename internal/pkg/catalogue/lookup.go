@@ -0,0 +1,105 @@
+package catalogue
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// Lookup is what's known about a barcode in a store
+type Lookup struct {
+	Name         string
+	Notes        string
+	CategoryName string
+	// Known is false when the barcode isn't in the catalogue and hasn't been
+	// named for the store, in which case Name is a placeholder
+	Known bool
+}
+
+// PlaceholderName is the name of an item scanned with an unknown barcode
+func PlaceholderName(code string) string {
+	return "Unknown product " + code
+}
+
+// LookupCode looks up a normalised barcode for a store. A name the store's
+// household (or the store) gave the barcode takes precedence over the catalogue
+func LookupCode(code string, store models.Store) (lookup Lookup, err error) {
+	var product models.Product
+	query := db.Manager.Where("code = ?", code).First(&product).Error
+	if err := query; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return lookup, err
+	}
+	if query == nil {
+		lookup = Lookup{Name: product.Name, Known: true}
+		var notes []string
+		if product.Brand != nil {
+			notes = append(notes, *product.Brand)
+		}
+		if product.Size != nil {
+			notes = append(notes, *product.Size)
+		}
+		lookup.Notes = strings.Join(notes, ", ")
+		if product.CategoryName != nil {
+			lookup.CategoryName = *product.CategoryName
+		}
+	}
+
+	var productName models.ProductName
+	query = productNameScope(code, store).Order("updated_at DESC").First(&productName).Error
+	if err := query; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return lookup, err
+	}
+	if query == nil {
+		lookup.Name = productName.Name
+		lookup.Known = true
+	}
+
+	if !lookup.Known {
+		lookup.Name = PlaceholderName(code)
+	}
+	return lookup, nil
+}
+
+// RememberProductName saves the name a user gave a barcode for the household
+// of the store (or the store itself, when it isn't shared with a household)
+func RememberProductName(code string, storeID uuid.UUID, userID uuid.UUID, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || name == PlaceholderName(code) {
+		return nil
+	}
+	var store models.Store
+	if err := db.Manager.Select("id, household_id").Where("id = ?", storeID).First(&store).Error; err != nil {
+		return err
+	}
+
+	var productName models.ProductName
+	query := productNameScope(code, store).First(&productName).Error
+	if err := query; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if errors.Is(query, gorm.ErrRecordNotFound) {
+		productName = models.ProductName{Code: code}
+		if store.HouseholdID != nil {
+			productName.HouseholdID = store.HouseholdID
+		} else {
+			productName.StoreID = &store.ID
+		}
+	}
+	productName.UserID = userID
+	productName.Name = name
+	return db.Manager.Save(&productName).Error
+}
+
+// productNameScope scopes a product_names query to the names given to a
+// barcode for a store
+func productNameScope(code string, store models.Store) *gorm.DB {
+	query := db.Manager.Where("code = ?", code)
+	if store.HouseholdID != nil {
+		return query.Where("household_id = ?", store.HouseholdID)
+	}
+	return query.Where("store_id = ?", store.ID)
+}
@@ -0,0 +1,244 @@
+// Package classify classifies item names (i.e. "frozen peas", "2% milk",
+// "bag of apples") into the categories of a vocabulary of food names. Names
+// are split into words, which are made singular and have synonyms replaced,
+// so "Apples" matches "apple" and "courgettes" matches "zucchini". Each result
+// has a confidence, which is lower the less of the name the vocabulary covers
+package classify
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Entry is a food name in the vocabulary, and the category it belongs in
+type Entry struct {
+	Text  string
+	Label string
+}
+
+// Result is the category a name was classified into
+type Result struct {
+	Label string
+	// Confidence is from 0 to 1: 1 when the name is in the vocabulary as it
+	// was written, and lower when only some of its words are
+	Confidence float64
+	// Text is the vocabulary entry (or modifier) that decided the category
+	Text string
+}
+
+const (
+	// exactConfidence is the confidence of a name that matches a vocabulary
+	// entry once plurals and synonyms are taken into account
+	exactConfidence = 0.9
+	// modifierConfidence is the confidence of a name classified by a modifier
+	// (i.e. "frozen") rather than the food it describes
+	modifierConfidence = 0.75
+	// conflictPenalty scales the confidence of a name whose other words
+	// belong in a different category
+	conflictPenalty = 0.75
+)
+
+// fillerWords don't count towards how much of a name is matched, and nor do
+// numbers (i.e. the 2 in "2% milk")
+var fillerWords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "of": true, "on": true,
+	"in": true, "with": true, "for": true, "some": true, "fresh": true, "organic": true,
+}
+
+// punctuation is replaced before names are split into words
+var punctuation = strings.NewReplacer("&", " and ", "'", "", "’", "")
+
+// accents are folded so that names match whether they're typed with them or not
+var accents = strings.NewReplacer("á", "a", "à", "a", "â", "a", "ä", "a", "é", "e", "è", "e", "ê", "e",
+	"í", "i", "î", "i", "ï", "i", "ñ", "n", "ó", "o", "ô", "o", "ö", "o", "ú", "u", "ü", "u", "ç", "c")
+
+type phrase struct {
+	text  string
+	label string
+}
+
+// Classifier classifies names with a vocabulary. It's safe to use from
+// multiple goroutines once it's been built
+type Classifier struct {
+	phrases   map[string]phrase
+	longest   int
+	synonyms  map[string][]string
+	modifiers map[string]string
+}
+
+// New builds a classifier from a vocabulary. Synonyms map names to the names
+// they mean (i.e. "aubergine" to "eggplant"), and modifiers map words to the
+// category of anything they describe (i.e. "frozen" to "Frozen Foods"). When a
+// name is in the vocabulary more than once, its first entry is used
+func New(entries []Entry, synonyms map[string]string, modifiers map[string]string) *Classifier {
+	c := &Classifier{
+		phrases:   map[string]phrase{},
+		synonyms:  map[string][]string{},
+		modifiers: map[string]string{},
+	}
+	for from, to := range synonyms {
+		if key := strings.Join(Tokens(from), " "); key != "" {
+			c.synonyms[key] = Tokens(to)
+		}
+	}
+	for word, label := range modifiers {
+		if tokens := Tokens(word); len(tokens) == 1 {
+			c.modifiers[tokens[0]] = label
+		}
+	}
+	for _, entry := range entries {
+		tokens := Tokens(entry.Text)
+		key := strings.Join(tokens, " ")
+		if key == "" {
+			continue
+		}
+		if _, ok := c.phrases[key]; ok {
+			continue
+		}
+		c.phrases[key] = phrase{text: entry.Text, label: entry.Label}
+		if len(tokens) > c.longest {
+			c.longest = len(tokens)
+		}
+	}
+	return c
+}
+
+// Classify classifies a name. When more than one part of the name is in the
+// vocabulary, the last one wins, as that's usually what the name is of (i.e.
+// "apple pie" is a pie), unless the name has a modifier. It returns false when
+// no part of the name is in the vocabulary
+func (c *Classifier) Classify(name string) (result Result, ok bool) {
+	tokens := c.replaceSynonyms(Tokens(name))
+	words := countWords(tokens)
+	if words == 0 {
+		return result, false
+	}
+
+	type match struct {
+		phrase
+		start, end int
+	}
+	var matches []match
+	for start := range tokens {
+		for end := start + 1; end <= len(tokens) && end-start <= c.longest; end++ {
+			if p, ok := c.phrases[strings.Join(tokens[start:end], " ")]; ok {
+				matches = append(matches, match{p, start, end})
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return result, false
+	}
+	best := matches[0]
+	for _, m := range matches[1:] {
+		if m.end > best.end || (m.end == best.end && m.start < best.start) {
+			best = m
+		}
+	}
+
+	covered := countWords(tokens[best.start:best.end])
+	if covered == words {
+		result = Result{Label: best.label, Confidence: exactConfidence, Text: best.text}
+		if strings.Join(strings.Fields(strings.ToLower(name)), " ") == strings.ToLower(best.text) {
+			result.Confidence = 1
+		}
+		return result, true
+	}
+
+	outside := func(start, end int) bool { return end <= best.start || start >= best.end }
+	for i, token := range tokens {
+		if label, ok := c.modifiers[token]; ok && label != best.label && outside(i, i+1) {
+			return Result{Label: label, Confidence: modifierConfidence, Text: token}, true
+		}
+	}
+
+	confidence := 0.4 + 0.4*float64(covered)/float64(words)
+	for _, m := range matches {
+		if m.label != best.label && outside(m.start, m.end) {
+			confidence *= conflictPenalty
+			break
+		}
+	}
+	return Result{Label: best.label, Confidence: round(confidence), Text: best.text}, true
+}
+
+// replaceSynonyms replaces the synonyms in tokens with what they mean,
+// preferring the longest synonym at each word
+func (c *Classifier) replaceSynonyms(tokens []string) []string {
+	if len(c.synonyms) == 0 {
+		return tokens
+	}
+	var result []string
+	for i := 0; i < len(tokens); {
+		replaced := false
+		for end := len(tokens); end > i; end-- {
+			if to, ok := c.synonyms[strings.Join(tokens[i:end], " ")]; ok {
+				result = append(result, to...)
+				i, replaced = end, true
+				break
+			}
+		}
+		if !replaced {
+			result = append(result, tokens[i])
+			i++
+		}
+	}
+	return result
+}
+
+// Tokens splits text into lowercase, singular words. "&" is read as "and",
+// apostrophes are dropped and accents are folded
+func Tokens(text string) []string {
+	text = accents.Replace(strings.ToLower(text))
+	text = punctuation.Replace(text)
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, field := range fields {
+		fields[i] = Singular(field)
+	}
+	return fields
+}
+
+// Singular returns the singular form of a lowercase English word. It doesn't
+// have to be the correct word, only the same for its singular and plural (so
+// "cookie" and "cookies" are both "cooky", and "quiche" is "quich")
+func Singular(word string) string {
+	if len(word) < 3 {
+		return word
+	}
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "ie"):
+		return word[:len(word)-2] + "y"
+	case strings.HasSuffix(word, "oes") && len(word) > 5:
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"),
+		strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "che"), strings.HasSuffix(word, "she"):
+		return word[:len(word)-1]
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+		return word
+	case strings.HasSuffix(word, "s"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// countWords counts the tokens that aren't filler words or numbers
+func countWords(tokens []string) (count int) {
+	for _, token := range tokens {
+		if !fillerWords[token] && strings.TrimFunc(token, unicode.IsDigit) != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// round rounds a confidence to two decimal places
+func round(confidence float64) float64 {
+	return math.Round(confidence*100) / 100
+}
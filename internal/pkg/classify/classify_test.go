@@ -0,0 +1,111 @@
+package classify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testClassifier() *Classifier {
+	entries := []Entry{
+		{Text: "apples", Label: "Produce"},
+		{Text: "peas", Label: "Produce"},
+		{Text: "strawberries", Label: "Produce"},
+		{Text: "green onions", Label: "Produce"},
+		{Text: "frozen", Label: "Frozen Foods"},
+		{Text: "frozen pizza", Label: "Frozen Foods"},
+		{Text: "peanut butter", Label: "Condiments"},
+		{Text: "cookies", Label: "Candy & Snacks"},
+		{Text: "cookies", Label: "Bakery"},
+		{Text: "yogurt", Label: "Dairy"},
+		{Text: "milk", Label: "Dairy"},
+		{Text: "mac & cheese", Label: "Dry Goods"},
+		{Text: "jalapeños", Label: "Produce"},
+		{Text: "corn on the cob", Label: "Produce"},
+	}
+	synonyms := map[string]string{"scallions": "green onions", "pb": "peanut butter"}
+	modifiers := map[string]string{"frozen": "Frozen Foods", "canned": "Canned Goods"}
+	return New(entries, synonyms, modifiers)
+}
+
+func TestClassify(t *testing.T) {
+	c := testClassifier()
+	tests := []struct {
+		name       string
+		label      string
+		confidence float64
+		text       string
+	}{
+		// In the vocabulary as written
+		{"Apples", "Produce", 1, "apples"},
+		{"corn on the cob", "Produce", 1, "corn on the cob"},
+		{"frozen pizza", "Frozen Foods", 1, "frozen pizza"},
+		// In the vocabulary once plurals, synonyms and punctuation are handled
+		{"apple", "Produce", 0.9, "apples"},
+		{"Scallion", "Produce", 0.9, "green onions"},
+		{"PB", "Condiments", 0.9, "peanut butter"},
+		{"mac and cheese", "Dry Goods", 0.9, "mac & cheese"},
+		{"jalapeno", "Produce", 0.9, "jalapeños"},
+		{"fresh apples", "Produce", 0.9, "apples"},
+		{"2% milk", "Dairy", 0.9, "milk"},
+		// Modifiers win over the food they describe
+		{"frozen peas", "Frozen Foods", 0.75, "frozen"},
+		{"canned peas", "Canned Goods", 0.75, "canned"},
+		// The last food in the name wins, with less confidence the less of the
+		// name it covers and when other words disagree
+		{"bag of apples", "Produce", 0.6, "apples"},
+		{"peanut butter cookies", "Candy & Snacks", 0.4, "cookies"},
+		{"strawberry yogurt", "Dairy", 0.45, "yogurt"},
+	}
+	for _, test := range tests {
+		result, ok := c.Classify(test.name)
+		if assert.True(t, ok, test.name) {
+			assert.Equal(t, test.label, result.Label, test.name)
+			assert.Equal(t, test.confidence, result.Confidence, test.name)
+			assert.Equal(t, test.text, result.Text, test.name)
+		}
+	}
+}
+
+func TestClassify_NoMatch(t *testing.T) {
+	c := testClassifier()
+	for _, name := range []string{"", "  ", "xyzzy", "of the", "2"} {
+		_, ok := c.Classify(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestSingular(t *testing.T) {
+	tests := map[string]string{
+		"apples":    "apple",
+		"apple":     "apple",
+		"berries":   "berry",
+		"berry":     "berry",
+		"cookies":   "cooky",
+		"cookie":    "cooky",
+		"pies":      "py",
+		"pie":       "py",
+		"tomatoes":  "tomato",
+		"tomato":    "tomato",
+		"shoes":     "shoe",
+		"peaches":   "peach",
+		"radishes":  "radish",
+		"quiches":   "quich",
+		"quiche":    "quich",
+		"boxes":     "box",
+		"glasses":   "glass",
+		"glass":     "glass",
+		"hummus":    "hummus",
+		"asparagus": "asparagus",
+		"peas":      "pea",
+		"oj":        "oj",
+	}
+	for word, singular := range tests {
+		assert.Equal(t, singular, Singular(word), word)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	assert.Equal(t, []string{"ben", "and", "jerry", "ice", "cream"}, Tokens("Ben & Jerry's Ice-Cream"))
+	assert.Equal(t, []string{"jalapeno", "creme", "fraich"}, Tokens("Jalapeños, crème fraîche"))
+}
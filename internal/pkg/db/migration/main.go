@@ -163,6 +163,733 @@ func AutoMigrateService(db *gorm.DB) error {
 				return tx.Migrator().DropColumn(&Recipe{}, "instructions")
 			},
 		},
+		{
+			// Add column archived_at to stores
+			ID: "202610190900_add_archived_at_to_stores",
+			Migrate: func(tx *gorm.DB) error {
+				type Store struct {
+					ArchivedAt *time.Time `gorm:"index"`
+				}
+				return tx.AutoMigrate(&Store{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				type Store struct {
+					ArchivedAt *time.Time
+				}
+				return tx.Migrator().DropColumn(&Store{}, "archived_at")
+			},
+		},
+		{
+			// Add column position to store_categories and backfill it from the
+			// order the default categories were created in
+			ID: "202610191000_add_position_to_store_categories",
+			Migrate: func(tx *gorm.DB) error {
+				type StoreCategory struct {
+					Position int `gorm:"default:0;not null"`
+				}
+				if err := tx.AutoMigrate(&StoreCategory{}); err != nil {
+					return err
+				}
+				return tx.Exec(`
+					UPDATE store_categories SET position = ordered.position
+					FROM (
+						SELECT id, ROW_NUMBER() OVER (PARTITION BY store_id ORDER BY created_at, id) AS position
+						FROM store_categories
+					) AS ordered
+					WHERE store_categories.id = ordered.id
+				`).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				type StoreCategory struct {
+					Position int
+				}
+				return tx.Migrator().DropColumn(&StoreCategory{}, "position")
+			},
+		},
+		{
+			// Create store_templates table
+			ID: "202610191100_create_store_templates",
+			Migrate: func(tx *gorm.DB) error {
+				type StoreTemplate struct {
+					ID                   uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+					UserID               uuid.UUID `gorm:"type:uuid;not null;index:idx_store_templates_user_id"`
+					Name                 string    `gorm:"type:varchar(100);not null"`
+					Categories           datatypes.JSON
+					StapleItems          datatypes.JSON
+					ItemCategorySettings datatypes.JSON
+
+					CreatedAt time.Time
+					UpdatedAt time.Time
+					DeletedAt gorm.DeletedAt
+				}
+				return tx.AutoMigrate(&StoreTemplate{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("store_templates")
+			},
+		},
+		{
+			// Add location columns to stores
+			ID: "202610191200_add_location_to_stores",
+			Migrate: func(tx *gorm.DB) error {
+				type Store struct {
+					Address        *string  `gorm:"type:varchar(255)"`
+					Latitude       *float64 `gorm:"type:double precision"`
+					Longitude      *float64 `gorm:"type:double precision"`
+					GeofenceRadius *int
+				}
+				return tx.AutoMigrate(&Store{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				type Store struct{}
+				for _, column := range []string{"address", "latitude", "longitude", "geofence_radius"} {
+					if err := tx.Migrator().DropColumn(&Store{}, column); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			// Create households and household_users tables, and add household_id to stores
+			ID: "202610191300_create_households",
+			Migrate: func(tx *gorm.DB) error {
+				type Household struct {
+					ID     uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+					UserID uuid.UUID `gorm:"type:uuid;not null;index:idx_households_user_id"`
+					Name   string    `gorm:"type:varchar(100);not null"`
+
+					CreatedAt time.Time
+					UpdatedAt time.Time
+					DeletedAt gorm.DeletedAt
+				}
+				type HouseholdUser struct {
+					ID          uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+					HouseholdID uuid.UUID `gorm:"type:uuid;not null;index:idx_household_users_household_id"`
+					UserID      uuid.UUID `gorm:"type:uuid;index:idx_household_users_user_id"`
+					Email       string    `gorm:"type:varchar(100)"`
+					Creator     *bool     `gorm:"default:false;not null"`
+					Active      *bool     `gorm:"default:true;not null"`
+
+					CreatedAt time.Time
+					UpdatedAt time.Time
+					DeletedAt gorm.DeletedAt
+				}
+				type Store struct {
+					HouseholdID *uuid.UUID `gorm:"type:uuid;index"`
+				}
+				return tx.AutoMigrate(&Household{}, &HouseholdUser{}, &Store{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				type Store struct{}
+				if err := tx.Migrator().DropColumn(&Store{}, "household_id"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropTable("household_users", "households")
+			},
+		},
+		{
+			// Create store_activities table
+			ID: "202610191400_create_store_activities",
+			Migrate: func(tx *gorm.DB) error {
+				type StoreActivity struct {
+					ID         uuid.UUID  `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+					StoreID    uuid.UUID  `gorm:"type:uuid;not null;index:idx_store_activities_store_id_created_at,priority:1"`
+					UserID     uuid.UUID  `gorm:"type:uuid"`
+					Action     string     `gorm:"type:varchar(50);not null"`
+					EntityType string     `gorm:"type:varchar(50);not null"`
+					EntityID   *uuid.UUID `gorm:"type:uuid"`
+					EntityName string     `gorm:"type:varchar(255)"`
+					Changes    datatypes.JSON
+
+					CreatedAt time.Time `gorm:"index:idx_store_activities_store_id_created_at,priority:2"`
+				}
+				return tx.AutoMigrate(&StoreActivity{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("store_activities")
+			},
+		},
+		{
+			// Replace the notifications flag on store_user_preferences with
+			// preferences for each kind of notification and channel
+			ID: "202610191500_notification_preferences_by_kind",
+			Migrate: func(tx *gorm.DB) error {
+				type StoreUserPreference struct {
+					PushItemsAdded    bool `gorm:"default:true;not null"`
+					PushMeals         bool `gorm:"default:true;not null"`
+					PushMemberJoined  bool `gorm:"default:true;not null"`
+					PushStoreChanges  bool `gorm:"default:true;not null"`
+					EmailItemsAdded   bool `gorm:"default:false;not null"`
+					EmailMeals        bool `gorm:"default:false;not null"`
+					EmailMemberJoined bool `gorm:"default:false;not null"`
+					EmailStoreChanges bool `gorm:"default:false;not null"`
+				}
+				if err := tx.AutoMigrate(&StoreUserPreference{}); err != nil {
+					return err
+				}
+				query := tx.Exec(`
+					UPDATE store_user_preferences SET
+						push_items_added = notifications,
+						push_meals = notifications,
+						push_member_joined = notifications,
+						push_store_changes = notifications
+				`).Error
+				if err := query; err != nil {
+					return err
+				}
+				return tx.Migrator().DropColumn(&StoreUserPreference{}, "notifications")
+			},
+			Rollback: func(tx *gorm.DB) error {
+				type StoreUserPreference struct {
+					Notifications bool `gorm:"default:true;not null"`
+				}
+				if err := tx.AutoMigrate(&StoreUserPreference{}); err != nil {
+					return err
+				}
+				if err := tx.Exec("UPDATE store_user_preferences SET notifications = push_items_added").Error; err != nil {
+					return err
+				}
+				columns := []string{
+					"push_items_added", "push_meals", "push_member_joined", "push_store_changes",
+					"email_items_added", "email_meals", "email_member_joined", "email_store_changes",
+				}
+				for i := range columns {
+					if err := tx.Migrator().DropColumn(&StoreUserPreference{}, columns[i]); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			// Create quiet_hours and pending_notifications tables
+			ID: "202610191510_create_quiet_hours",
+			Migrate: func(tx *gorm.DB) error {
+				type QuietHours struct {
+					ID                uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+					UserID            uuid.UUID `gorm:"type:uuid;uniqueIndex;not null"`
+					Start             string    `gorm:"type:varchar(5);not null"`
+					End               string    `gorm:"type:varchar(5);not null"`
+					Timezone          string    `gorm:"type:varchar(64);not null"`
+					HoldNotifications *bool     `gorm:"default:true;not null"`
+
+					CreatedAt time.Time
+					UpdatedAt time.Time
+				}
+				type PendingNotification struct {
+					ID         uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+					UserID     uuid.UUID `gorm:"type:uuid;not null"`
+					Channel    string    `gorm:"type:varchar(10);not null"`
+					Recipient  string    `gorm:"type:varchar(255);not null"`
+					Title      string    `gorm:"type:varchar(255);not null"`
+					Body       string    `gorm:"type:text;not null"`
+					EntityName string    `gorm:"type:varchar(50)"`
+					EntityID   string    `gorm:"type:varchar(50)"`
+					AppScheme  string    `gorm:"type:varchar(50)"`
+					DeliverAt  time.Time `gorm:"not null;index:idx_pending_notifications_deliver_at"`
+
+					CreatedAt time.Time
+				}
+				if err := tx.Table("quiet_hours").AutoMigrate(&QuietHours{}); err != nil {
+					return err
+				}
+				return tx.AutoMigrate(&PendingNotification{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropTable("pending_notifications"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropTable("quiet_hours")
+			},
+		},
+		{
+			// Add planned shopping dates to grocery_trips and create store_schedules table
+			ID: "202610191600_add_shopping_dates_and_store_schedules",
+			Migrate: func(tx *gorm.DB) error {
+				type GroceryTrip struct {
+					ShoppingDate   *string `gorm:"type:varchar(10);index"`
+					ReminderSentAt *time.Time
+				}
+				type StoreSchedule struct {
+					ID                 uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+					StoreID            uuid.UUID `gorm:"type:uuid;uniqueIndex;not null"`
+					Weekday            int       `gorm:"not null"`
+					Timezone           string    `gorm:"type:varchar(64);not null"`
+					CopyRemainingItems *bool     `gorm:"default:true;not null"`
+
+					CreatedAt time.Time
+					UpdatedAt time.Time
+				}
+				if err := tx.AutoMigrate(&GroceryTrip{}); err != nil {
+					return err
+				}
+				return tx.AutoMigrate(&StoreSchedule{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropTable("store_schedules"); err != nil {
+					return err
+				}
+				type GroceryTrip struct{}
+				if err := tx.Migrator().DropColumn(&GroceryTrip{}, "reminder_sent_at"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropColumn(&GroceryTrip{}, "shopping_date")
+			},
+		},
+		{
+			// Add trip reminder notification preferences to store_user_preferences
+			ID: "202610191610_add_trip_reminder_preferences",
+			Migrate: func(tx *gorm.DB) error {
+				type StoreUserPreference struct {
+					PushTripReminders  bool `gorm:"default:true;not null"`
+					EmailTripReminders bool `gorm:"default:false;not null"`
+				}
+				return tx.AutoMigrate(&StoreUserPreference{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				type StoreUserPreference struct{}
+				if err := tx.Migrator().DropColumn(&StoreUserPreference{}, "email_trip_reminders"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropColumn(&StoreUserPreference{}, "push_trip_reminders")
+			},
+		},
+		{
+			// Store the app scheme devices were registered with, for notifications
+			// sent by background jobs
+			ID: "202610191620_add_app_scheme_to_devices",
+			Migrate: func(tx *gorm.DB) error {
+				type Device struct {
+					AppScheme *string `gorm:"type:varchar(50)"`
+				}
+				return tx.AutoMigrate(&Device{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				type Device struct{}
+				return tx.Migrator().DropColumn(&Device{}, "app_scheme")
+			},
+		},
+		{
+			// Add prices to items, monthly store budgets and budget alert
+			// notification preferences
+			ID: "202610191700_add_item_prices_and_store_budgets",
+			Migrate: func(tx *gorm.DB) error {
+				type Item struct {
+					UnitPrice *int64  `gorm:"type:bigint"`
+					Currency  *string `gorm:"type:varchar(3)"`
+				}
+				if err := tx.AutoMigrate(&Item{}); err != nil {
+					return err
+				}
+				type StoreBudget struct {
+					ID            uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+					StoreID       uuid.UUID `gorm:"type:uuid;uniqueIndex;not null"`
+					MonthlyAmount int64     `gorm:"type:bigint;not null"`
+					Currency      string    `gorm:"type:varchar(3);not null"`
+					NotifiedMonth *string   `gorm:"type:varchar(7)"`
+
+					CreatedAt time.Time
+					UpdatedAt time.Time
+				}
+				if err := tx.AutoMigrate(&StoreBudget{}); err != nil {
+					return err
+				}
+				type StoreUserPreference struct {
+					PushBudgetAlerts  bool `gorm:"default:true;not null"`
+					EmailBudgetAlerts bool `gorm:"default:false;not null"`
+				}
+				return tx.AutoMigrate(&StoreUserPreference{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				type StoreUserPreference struct{}
+				if err := tx.Migrator().DropColumn(&StoreUserPreference{}, "email_budget_alerts"); err != nil {
+					return err
+				}
+				if err := tx.Migrator().DropColumn(&StoreUserPreference{}, "push_budget_alerts"); err != nil {
+					return err
+				}
+				if err := tx.Migrator().DropTable("store_budgets"); err != nil {
+					return err
+				}
+				type Item struct{}
+				if err := tx.Migrator().DropColumn(&Item{}, "currency"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropColumn(&Item{}, "unit_price")
+			},
+		},
+		{
+			// Add decimal quantities and units to items
+			ID: "202610191710_add_decimal_quantity_and_unit_to_items",
+			Migrate: func(tx *gorm.DB) error {
+				type Item struct {
+					DecimalQuantity *float64 `gorm:"type:numeric(10,3)"`
+					Unit            *string  `gorm:"type:varchar(20)"`
+				}
+				return tx.AutoMigrate(&Item{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				type Item struct{}
+				if err := tx.Migrator().DropColumn(&Item{}, "unit"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropColumn(&Item{}, "decimal_quantity")
+			},
+		},
+		{
+			// Add the setting for merging duplicate items to stores, and record
+			// each addition of items that have been merged
+			ID: "202610191720_add_merge_duplicate_items",
+			Migrate: func(tx *gorm.DB) error {
+				type Store struct {
+					MergeDuplicateItems *bool `gorm:"default:true;not null"`
+				}
+				if err := tx.AutoMigrate(&Store{}); err != nil {
+					return err
+				}
+				type ItemAddition struct {
+					ID              uuid.UUID  `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+					ItemID          uuid.UUID  `gorm:"type:uuid;not null;index"`
+					UserID          uuid.UUID  `gorm:"type:uuid;not null"`
+					Quantity        int        `gorm:"default:1;not null"`
+					DecimalQuantity *float64   `gorm:"type:numeric(10,3)"`
+					Unit            *string    `gorm:"type:varchar(20)"`
+					MealID          *uuid.UUID `gorm:"type:uuid"`
+					MealName        *string    `gorm:"type:varchar(255)"`
+
+					CreatedAt time.Time
+				}
+				return tx.AutoMigrate(&ItemAddition{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropTable("item_additions"); err != nil {
+					return err
+				}
+				type Store struct{}
+				return tx.Migrator().DropColumn(&Store{}, "merge_duplicate_items")
+			},
+		},
+		{
+			// Add the product catalogue for barcode lookups, the names users give
+			// unknown barcodes, and the barcode items were scanned with
+			ID: "202610191730_add_product_catalogue",
+			Migrate: func(tx *gorm.DB) error {
+				type Product struct {
+					ID           uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+					Code         string    `gorm:"type:varchar(14);uniqueIndex;not null"`
+					Name         string    `gorm:"type:varchar(255);not null"`
+					Brand        *string   `gorm:"type:varchar(255)"`
+					Size         *string   `gorm:"type:varchar(100)"`
+					CategoryName *string   `gorm:"type:varchar(100)"`
+
+					CreatedAt time.Time
+					UpdatedAt time.Time
+				}
+				if err := tx.AutoMigrate(&Product{}); err != nil {
+					return err
+				}
+				type ProductName struct {
+					ID          uuid.UUID  `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+					Code        string     `gorm:"type:varchar(14);not null;index"`
+					HouseholdID *uuid.UUID `gorm:"type:uuid;index"`
+					StoreID     *uuid.UUID `gorm:"type:uuid;index"`
+					UserID      uuid.UUID  `gorm:"type:uuid;not null"`
+					Name        string     `gorm:"type:varchar(100);not null"`
+
+					CreatedAt time.Time
+					UpdatedAt time.Time
+				}
+				if err := tx.AutoMigrate(&ProductName{}); err != nil {
+					return err
+				}
+				type Item struct {
+					Barcode *string `gorm:"type:varchar(14);index"`
+				}
+				return tx.AutoMigrate(&Item{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				type Item struct{}
+				if err := tx.Migrator().DropColumn(&Item{}, "barcode"); err != nil {
+					return err
+				}
+				if err := tx.Migrator().DropTable("product_names"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropTable("products")
+			},
+		},
+		{
+			// Create the photos table for photos attached to items and recipes, with
+			// the blob storage keys of each photo and its thumbnail
+			ID: "202610191740_add_photos",
+			Migrate: func(tx *gorm.DB) error {
+				type Photo struct {
+					ID           uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+					UserID       uuid.UUID `gorm:"type:uuid;not null"`
+					OwnerType    string    `gorm:"type:varchar(20);not null;index:idx_photos_owner"`
+					OwnerID      uuid.UUID `gorm:"type:uuid;not null;index:idx_photos_owner"`
+					Key          string    `gorm:"type:varchar(255);not null"`
+					ThumbnailKey string    `gorm:"type:varchar(255);not null"`
+					ContentType  string    `gorm:"type:varchar(50);not null"`
+					Size         int64     `gorm:"not null"`
+					Width        int       `gorm:"not null"`
+					Height       int       `gorm:"not null"`
+
+					CreatedAt time.Time
+				}
+				return tx.AutoMigrate(&Photo{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("photos")
+			},
+		},
+		{
+			// Add assignees to items, item assignment notification preferences, and
+			// assignee_completions to record how many assigned items each member completed
+			ID: "202610191750_add_item_assignees",
+			Migrate: func(tx *gorm.DB) error {
+				type Item struct {
+					AssigneeID *uuid.UUID `gorm:"type:uuid;index"`
+				}
+				if err := tx.AutoMigrate(&Item{}); err != nil {
+					return err
+				}
+				type StoreUserPreference struct {
+					PushItemAssigned  bool `gorm:"default:true;not null"`
+					EmailItemAssigned bool `gorm:"default:false;not null"`
+				}
+				if err := tx.AutoMigrate(&StoreUserPreference{}); err != nil {
+					return err
+				}
+				type AssigneeCompletion struct {
+					ID            uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+					GroceryTripID uuid.UUID `gorm:"type:uuid;not null;index"`
+					UserID        uuid.UUID `gorm:"type:uuid;not null"`
+					Assigned      int       `gorm:"not null"`
+					Completed     int       `gorm:"not null"`
+
+					CreatedAt time.Time
+				}
+				return tx.AutoMigrate(&AssigneeCompletion{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropTable("assignee_completions"); err != nil {
+					return err
+				}
+				type StoreUserPreference struct{}
+				if err := tx.Migrator().DropColumn(&StoreUserPreference{}, "email_item_assigned"); err != nil {
+					return err
+				}
+				if err := tx.Migrator().DropColumn(&StoreUserPreference{}, "push_item_assigned"); err != nil {
+					return err
+				}
+				type Item struct{}
+				return tx.Migrator().DropColumn(&Item{}, "assignee_id")
+			},
+		},
+		{
+			// Add cadences to staple items, and record when each was last added to a
+			// trip and last purchased
+			ID: "202610191800_add_staple_item_cadences",
+			Migrate: func(tx *gorm.DB) error {
+				type StoreStapleItem struct {
+					Cadence         string `gorm:"type:varchar(10);default:'every_trip';not null"`
+					Interval        int    `gorm:"default:1;not null"`
+					Weekdays        int    `gorm:"default:0;not null"`
+					LastAddedAt     *time.Time
+					LastPurchasedAt *time.Time
+				}
+				return tx.AutoMigrate(&StoreStapleItem{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				type StoreStapleItem struct{}
+				for _, column := range []string{"cadence", "interval", "weekdays", "last_added_at", "last_purchased_at"} {
+					if err := tx.Migrator().DropColumn(&StoreStapleItem{}, column); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			// Add quantities, units, notes and pinned categories to staple items
+			ID: "202610191810_add_staple_item_details",
+			Migrate: func(tx *gorm.DB) error {
+				type StoreStapleItem struct {
+					Quantity        int        `gorm:"default:1;not null"`
+					DecimalQuantity *float64   `gorm:"type:numeric(10,3)"`
+					Unit            *string    `gorm:"type:varchar(20)"`
+					Notes           *string    `gorm:"type:varchar(255)"`
+					StoreCategoryID *uuid.UUID `gorm:"type:uuid"`
+				}
+				return tx.AutoMigrate(&StoreStapleItem{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				type StoreStapleItem struct{}
+				for _, column := range []string{"quantity", "decimal_quantity", "unit", "notes", "store_category_id"} {
+					if err := tx.Migrator().DropColumn(&StoreStapleItem{}, column); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			// Add the suggestions preference, and suggestion_dismissals to hide a
+			// dismissed suggestion from a member
+			ID: "202610191820_add_suggestions",
+			Migrate: func(tx *gorm.DB) error {
+				type StoreUserPreference struct {
+					Suggestions bool `gorm:"default:true;not null"`
+				}
+				if err := tx.AutoMigrate(&StoreUserPreference{}); err != nil {
+					return err
+				}
+				type SuggestionDismissal struct {
+					ID          uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+					StoreID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_suggestion_dismissals_store_user_name"`
+					UserID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_suggestion_dismissals_store_user_name"`
+					Name        string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_suggestion_dismissals_store_user_name"`
+					DismissedAt time.Time `gorm:"not null"`
+					CreatedAt   time.Time
+				}
+				return tx.AutoMigrate(&SuggestionDismissal{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropTable("suggestion_dismissals"); err != nil {
+					return err
+				}
+				type StoreUserPreference struct{}
+				return tx.Migrator().DropColumn(&StoreUserPreference{}, "suggestions")
+			},
+		},
+		{
+			// Index item names by trigram (pg_trgm) for ranked item search
+			ID: "202610191830_add_items_name_trigram_index",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+					return err
+				}
+				return tx.Exec("CREATE INDEX IF NOT EXISTS idx_items_name_trgm ON items USING gin (name gin_trgm_ops)").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Exec("DROP INDEX idx_items_name_trgm").Error
+			},
+		},
+		{
+			// Create trip_rollovers to record what completing a trip did, so that
+			// reopening it can undo it
+			ID: "202610191840_create_trip_rollovers",
+			Migrate: func(tx *gorm.DB) error {
+				type TripRollover struct {
+					ID                uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+					GroceryTripID     uuid.UUID `gorm:"type:uuid;not null;index"`
+					NextTripID        uuid.UUID `gorm:"type:uuid;not null"`
+					CompletedItemIDs  datatypes.JSON
+					CopiedItemIDs     datatypes.JSON
+					StapleLastAddedAt datatypes.JSON
+					CreatedAt         time.Time
+				}
+				return tx.AutoMigrate(&TripRollover{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("trip_rollovers")
+			},
+		},
+		{
+			// Replace item positions, which were renumbered across the whole
+			// trip on every change, with rank keys ordering items per category
+			ID: "202610191850_replace_item_positions_with_ranks",
+			Migrate: func(tx *gorm.DB) error {
+				query := tx.Exec(`ALTER TABLE items ADD COLUMN IF NOT EXISTS rank varchar(32) COLLATE "C" NOT NULL DEFAULT ''`)
+				if err := query.Error; err != nil {
+					return err
+				}
+				type Item struct{}
+				if tx.Migrator().HasColumn(&Item{}, "position") {
+					// Rank the items in each category in the order of their
+					// positions. Zero-padded numbers ending in 1 are valid rank
+					// keys, and sort the same way as the numbers do
+					query = tx.Exec(`
+						UPDATE items SET rank = ranked.rank
+						FROM (
+							SELECT id, LPAD((ROW_NUMBER() OVER (
+								PARTITION BY category_id ORDER BY position, created_at
+							))::text, 8, '0') || '1' AS rank
+							FROM items
+						) AS ranked
+						WHERE items.id = ranked.id
+					`)
+					if err := query.Error; err != nil {
+						return err
+					}
+					if err := tx.Migrator().DropColumn(&Item{}, "position"); err != nil {
+						return err
+					}
+				}
+				return tx.Exec("CREATE INDEX IF NOT EXISTS idx_items_category_id_rank ON items (category_id, rank)").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				query := tx.Exec("ALTER TABLE items ADD COLUMN IF NOT EXISTS position bigint NOT NULL DEFAULT 1")
+				if err := query.Error; err != nil {
+					return err
+				}
+				query = tx.Exec(`
+					UPDATE items SET position = ranked.position
+					FROM (
+						SELECT id, ROW_NUMBER() OVER (
+							PARTITION BY grocery_trip_id ORDER BY rank, id
+						) AS position
+						FROM items
+					) AS ranked
+					WHERE items.id = ranked.id
+				`)
+				if err := query.Error; err != nil {
+					return err
+				}
+				if err := tx.Exec("DROP INDEX IF EXISTS idx_items_category_id_rank").Error; err != nil {
+					return err
+				}
+				type Item struct{}
+				return tx.Migrator().DropColumn(&Item{}, "rank")
+			},
+		},
+		{
+			// Record how each item's category was decided, and how confident
+			// the decision is
+			ID: "202610191900_add_category_source_and_confidence_to_items",
+			Migrate: func(tx *gorm.DB) error {
+				type Item struct {
+					CategorySource     *string  `gorm:"type:varchar(20)"`
+					CategoryConfidence *float64 `gorm:"type:numeric(3,2)"`
+				}
+				return tx.AutoMigrate(&Item{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				type Item struct{}
+				if err := tx.Migrator().DropColumn(&Item{}, "category_confidence"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropColumn(&Item{}, "category_source")
+			},
+		},
+		{
+			// Record which household added each store user, so that leaving
+			// the household only removes the stores it added. Existing store
+			// users are left without one and keep their access
+			ID: "202610191910_add_household_id_to_store_users",
+			Migrate: func(tx *gorm.DB) error {
+				type StoreUser struct {
+					HouseholdID *uuid.UUID `gorm:"type:uuid;index:idx_store_users_household_id"`
+				}
+				return tx.AutoMigrate(&StoreUser{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				type StoreUser struct{}
+				return tx.Migrator().DropColumn(&StoreUser{}, "household_id")
+			},
+		},
 	})
 	return m.Migrate()
 }
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// AssigneeCompletion defines the model for assignee_completions, which record
+// how many of the items assigned to each store member were completed when a
+// trip was completed (before its remaining items were marked as completed)
+type AssigneeCompletion struct {
+	ID            uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	GroceryTripID uuid.UUID `gorm:"type:uuid;not null;index"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null"`
+	Assigned      int       `gorm:"not null"`
+	Completed     int       `gorm:"not null"`
+
+	CreatedAt time.Time
+}
@@ -11,6 +11,9 @@ type Device struct {
 	ID     uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	UserID uuid.UUID `gorm:"type:uuid;not null"`
 	Token  string    `gorm:"type:varchar(255);not null"`
+	// AppScheme is the scheme of the app that registered the device, used to
+	// deliver notifications that aren't sent in response to a request
+	AppScheme *string `gorm:"type:varchar(50)"`
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -19,3 +22,12 @@ type Device struct {
 	// Associations
 	User User
 }
+
+// Scheme returns the app scheme the device was registered with, falling back
+// to the release scheme for devices registered before it was stored
+func (d *Device) Scheme() string {
+	if d.AppScheme == nil {
+		return "Release"
+	}
+	return *d.AppScheme
+}
@@ -8,12 +8,21 @@ import (
 	"gorm.io/gorm"
 )
 
+// ShoppingDateLayout is the layout of planned shopping dates
+const ShoppingDateLayout = "2006-01-02"
+
+// TripNameLayout is the layout of trip names derived from a date
+const TripNameLayout = "Jan 2, 2006"
+
 type GroceryTrip struct {
 	ID                 uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	StoreID            uuid.UUID `gorm:"type:uuid;not null;index"`
 	Name               string    `gorm:"type:varchar(100);not null"`
 	Completed          bool      `gorm:"default:false;not null"`
 	CopyRemainingItems bool      `gorm:"default:false;not null"`
+	// ShoppingDate is the date the trip is planned for, in ShoppingDateLayout
+	ShoppingDate   *string `gorm:"type:varchar(10);index"`
+	ReminderSentAt *time.Time
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
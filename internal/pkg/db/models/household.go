@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// Household is a group of users who share a set of stores. Every member of a
+// household is a member of every store in it
+type Household struct {
+	ID     uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index:idx_households_user_id"`
+	Name   string    `gorm:"type:varchar(100);not null"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+
+	// Associations
+	HouseholdUsers []HouseholdUser
+	Stores         []Store
+}
+
+// AfterCreate hook to automatically add the creator to the household
+func (h *Household) AfterCreate(tx *gorm.DB) (err error) {
+	creator := true
+	active := true
+	householdUser := HouseholdUser{
+		HouseholdID: h.ID,
+		UserID:      h.UserID,
+		Creator:     &creator,
+		Active:      &active,
+	}
+	if err := tx.Create(&householdUser).Error; err != nil {
+		return err
+	}
+	return nil
+}
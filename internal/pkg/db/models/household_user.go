@@ -0,0 +1,80 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// HouseholdUser is a member of a household. Like StoreUser, a household user
+// that was invited by email is pending (inactive) until they accept
+type HouseholdUser struct {
+	ID          uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	HouseholdID uuid.UUID `gorm:"type:uuid;not null;index:idx_household_users_household_id"`
+	UserID      uuid.UUID `gorm:"type:uuid;index:idx_household_users_user_id"`
+	Email       string    `gorm:"type:varchar(100)"`
+	Creator     *bool     `gorm:"default:false;not null"`
+	Active      *bool     `gorm:"default:true;not null"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+
+	// JoinedStoreUsers are the store memberships added when the user joined,
+	// so that they can be recorded in each store's activity log
+	JoinedStoreUsers []StoreUser `gorm:"-"`
+
+	// Associations
+	Household Household
+	User      User
+}
+
+// AfterSave hook adds an active household user to every store in the household
+func (hu *HouseholdUser) AfterSave(tx *gorm.DB) (err error) {
+	if hu.Active == nil || !*hu.Active || hu.UserID == uuid.Nil {
+		return nil
+	}
+	var stores []Store
+	if err := tx.Select("id").Where("household_id = ?", hu.HouseholdID).Find(&stores).Error; err != nil {
+		return err
+	}
+	hu.JoinedStoreUsers = nil
+	for i := range stores {
+		storeUser, added, err := addStoreUser(stores[i].ID, hu.UserID, &hu.HouseholdID, tx)
+		if err != nil {
+			return err
+		}
+		if added {
+			hu.JoinedStoreUsers = append(hu.JoinedStoreUsers, storeUser)
+		}
+	}
+	return nil
+}
+
+// addStoreUser adds the user to the store as an active member through the
+// household provided, unless they already belong to it, and returns whether
+// they were added. The StoreUser hooks take care of creating preferences
+func addStoreUser(storeID uuid.UUID, userID uuid.UUID, householdID *uuid.UUID, tx *gorm.DB) (storeUser StoreUser, added bool, err error) {
+	query := tx.Where("store_id = ? AND user_id = ?", storeID, userID).First(&storeUser).Error
+	if !errors.Is(query, gorm.ErrRecordNotFound) {
+		if err := query; err != nil {
+			return storeUser, false, err
+		}
+		if storeUser.Active != nil && !*storeUser.Active {
+			if err := tx.Model(&storeUser).Update("active", true).Error; err != nil {
+				return storeUser, false, err
+			}
+			return storeUser, true, nil
+		}
+		return storeUser, false, nil
+	}
+
+	active := true
+	storeUser = StoreUser{StoreID: storeID, UserID: userID, HouseholdID: householdID, Active: &active}
+	if err := tx.Create(&storeUser).Error; err != nil {
+		return storeUser, false, err
+	}
+	return storeUser, true, nil
+}
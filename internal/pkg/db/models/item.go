@@ -1,52 +1,102 @@
 package models
 
 import (
-	_ "embed"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/parser"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/utils"
 	uuid "github.com/satori/go.uuid"
-	"github.com/tidwall/gjson"
-	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
-//go:embed FoodClassification.json
-var foods string
+// ItemPriceSQL is the SQL for the total price of an item (its unit price times
+// its quantity, preferring the decimal quantity), rounded to the minor unit
+const ItemPriceSQL = "ROUND(items.unit_price * COALESCE(items.decimal_quantity, items.quantity))::bigint"
+
+// ItemRestorePeriod is how long a deleted item can be restored for. It matches
+// PhotoRetentionPeriod so that a restored item still has its photo
+const ItemRestorePeriod = PhotoRetentionPeriod
 
 // Item defines the model for items
 type Item struct {
 	ID            uuid.UUID  `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	GroceryTripID uuid.UUID  `gorm:"type:uuid;not null;index:idx_items_grocery_trip_id_name"`
-	CategoryID    *uuid.UUID `gorm:"type:uuid;not null"`
-	UserID        uuid.UUID  `gorm:"type:uuid;not null"`
-	StapleItemID  *uuid.UUID `gorm:"type:uuid;index"`
-	Name          string     `gorm:"type:varchar(100);not null;index:idx_items_grocery_trip_id_name"`
-	Quantity      int        `gorm:"default:1;not null"`
-	Completed     *bool      `gorm:"default:false;not null"`
-	Position      int        `gorm:"default:1;not null"`
-	Notes         *string    `gorm:"type:varchar(255)"`
-	MealID        *uuid.UUID `gorm:"type:uuid"`
-	MealName      *string    `gorm:"type:varchar(255)"`
+	CategoryID    *uuid.UUID `gorm:"type:uuid;not null;index:idx_items_category_id_rank,priority:1"`
+	// CategorySource is how the item's category was decided (see the
+	// CategorySource constants), and CategoryConfidence how sure that decision
+	// is, from 0 to 1, so the app can point out categories that were guessed
+	CategorySource     *string   `gorm:"type:varchar(20)"`
+	CategoryConfidence *float64  `gorm:"type:numeric(3,2)"`
+	UserID             uuid.UUID `gorm:"type:uuid;not null"`
+	// AssigneeID is the store member who has been asked to pick up the item
+	AssigneeID   *uuid.UUID `gorm:"type:uuid;index"`
+	StapleItemID *uuid.UUID `gorm:"type:uuid;index"`
+	Name         string     `gorm:"type:varchar(100);not null;index:idx_items_grocery_trip_id_name"`
+	Quantity     int        `gorm:"default:1;not null"`
+	// DecimalQuantity and Unit hold a measured amount (e.g. 1.5 kg). Quantity
+	// is kept as a whole number approximation for older app versions
+	DecimalQuantity *float64 `gorm:"type:numeric(10,3)"`
+	Unit            *string  `gorm:"type:varchar(20)"`
+	Completed       *bool    `gorm:"default:false;not null"`
+	// Rank orders the item within its category (see the rank package)
+	Rank     string     `gorm:"type:varchar(32) COLLATE \"C\";not null;index:idx_items_category_id_rank,priority:2"`
+	Notes    *string    `gorm:"type:varchar(255)"`
+	MealID   *uuid.UUID `gorm:"type:uuid"`
+	MealName *string    `gorm:"type:varchar(255)"`
+	// UnitPrice is the price of a single unit of the item, in the minor unit
+	// of its currency (i.e. cents)
+	UnitPrice *int64  `gorm:"type:bigint"`
+	Currency  *string `gorm:"type:varchar(3)"`
+	// Barcode is the normalised UPC/EAN code the item was scanned with
+	Barcode *string `gorm:"type:varchar(14);index"`
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt
 
+	// Locale is the locale to parse the item's name in (see parser.NormaliseLocale)
+	Locale string `gorm:"-"`
+	// DefaultCategoryName is the category to use for the item when the store
+	// hasn't saved one for it (i.e. from the product catalogue). It's only used
+	// when the item is added; after that its CategorySource of "catalogue"
+	// keeps the category when the item is saved
+	DefaultCategoryName string `gorm:"-"`
+	// PinnedStoreCategoryID is the category to use for the item regardless of
+	// the store settings (i.e. from a staple item with a pinned category). It's
+	// only used when the item is added; after that its CategorySource of
+	// "pinned" keeps the category when the item is saved
+	PinnedStoreCategoryID *uuid.UUID `gorm:"-"`
+	// NeedsName is set when the item was scanned with a barcode that isn't in
+	// the catalogue, so the user should be asked to name it
+	NeedsName bool `gorm:"-"`
+	// Merged is set when an item being added was merged into this item
+	// instead of being added as a new row
+	Merged bool `gorm:"-"`
+	// Position is the position (from 1) of the item in its category, when it
+	// was retrieved along with the rest of the category
+	Position int `gorm:"-"`
+	// storeID is the store of the item's trip, found when it's saved
+	storeID uuid.UUID
+
 	// Associations
 	GroceryTrip GroceryTrip
 	Meal        Meal
 	StapleItem  StoreStapleItem
 }
 
-// BeforeCreate hook updates the item position
+// BeforeCreate hook ranks the item at the top of its category, unless it
+// already has a rank (i.e. it was copied from another trip)
 func (i *Item) BeforeCreate(tx *gorm.DB) (err error) {
-	tx.Exec("UPDATE items SET position = position + 1 WHERE grocery_trip_id = ? AND position >= 0", i.GroceryTripID)
+	if i.Rank != "" || i.CategoryID == nil {
+		return nil
+	}
+	ranks, err := RankItems(tx, *i.CategoryID, nil, 1, 1)
+	if err != nil {
+		return err
+	}
+	i.Rank = ranks[0]
 	return nil
 }
 
@@ -57,9 +107,10 @@ func (i *Item) AfterCreate(tx *gorm.DB) (err error) {
 	return nil
 }
 
-// BeforeSave hook
+// BeforeSave hook verifies that the item can be added/saved to the trip, and
+// decides the category of a new item, unless it already has one that wasn't
+// decided automatically (i.e. it was copied from another trip)
 func (i *Item) BeforeSave(tx *gorm.DB) (err error) {
-	// Verify that the item can be added/saved to the trip
 	var trip GroceryTrip
 	if err := tx.Select("store_id").Where("id = ?", i.GroceryTripID).Last(&trip).Error; err != nil {
 		return errors.New("trip does not exist")
@@ -68,40 +119,71 @@ func (i *Item) BeforeSave(tx *gorm.DB) (err error) {
 	if err := tx.Where("store_id = ? AND user_id = ?", trip.StoreID, i.UserID).First(&storeUser).Error; err != nil {
 		return errors.New("user does not belong to this store")
 	}
-
-	// Parse the item name and quantity
-	i.Name, i.Quantity = i.parseItemName()
-
-	// Determine the proper category for the item
-	categoryName, err := i.DetermineCategoryName(trip.StoreID, tx)
-	if err != nil {
-		return err
+	i.storeID = trip.StoreID
+	if i.ID == uuid.Nil && (i.CategoryID == nil || autoCategorised(i.CategorySource)) {
+		return i.categorise(tx)
 	}
-	category, err := i.FetchGroceryTripCategory(categoryName, tx)
-	if err != nil {
-		return errors.New("could not find or create grocery trip category")
-	}
-	i.CategoryID = &category.ID
-
 	return nil
 }
 
-// BeforeUpdate hook handles reordering items
+// BeforeUpdate hook decides the item's category again when it has been renamed
+// and its category was decided automatically, so that a category the user
+// chose (or a pinned or catalogue category) is kept. It then ranks the item at
+// the top of its category when it has been moved to another category, unless
+// it was given a rank in it already
 func (i *Item) BeforeUpdate(tx *gorm.DB) (err error) {
+	if i.CategoryID == nil || i.storeID == uuid.Nil {
+		// BeforeSave couldn't verify the item, and has returned why
+		return nil
+	}
 	item := &Item{}
-	if err := tx.Where("id = ?", i.ID).Find(&item).Error; err != nil {
+	if err := tx.Select("name, category_id, rank").Where("id = ?", i.ID).Find(&item).Error; err != nil {
 		return err
 	}
-	currPosition := item.Position
-	newPosition := i.Position
-	if currPosition == newPosition {
+	if item.Name != i.Name && autoCategorised(i.CategorySource) {
+		if err := i.categorise(tx); err != nil {
+			return err
+		}
+	}
+	if item.CategoryID == nil || *item.CategoryID == *i.CategoryID || item.Rank != i.Rank {
 		return nil
 	}
-	if currPosition > newPosition {
-		tx.Exec("UPDATE items SET position = position + 1 WHERE grocery_trip_id = ? AND position >= ? AND position < ?", i.GroceryTripID, newPosition, currPosition)
+	ranks, err := RankItems(tx, *i.CategoryID, []uuid.UUID{i.ID}, 1, 1)
+	if err != nil {
+		return err
+	}
+	i.Rank = ranks[0]
+	return nil
+}
+
+// categorise decides the item's category in its store, and puts the item in
+// that category of its trip
+func (i *Item) categorise(tx *gorm.DB) (err error) {
+	var decision CategoryDecision
+	if i.PinnedStoreCategoryID != nil {
+		decision = CategoryDecision{
+			Name:       FindStoreCategoryName(*i.PinnedStoreCategoryID, tx),
+			Confidence: 1,
+			Source:     CategorySourcePinned,
+		}
 	} else {
-		tx.Exec("UPDATE items SET position = position - 1 WHERE grocery_trip_id = ? AND position > ? AND position <= ?", i.GroceryTripID, currPosition, newPosition)
+		decision, err = i.DetermineCategory(i.storeID, tx)
+		if err != nil {
+			return err
+		}
 	}
+	category, err := i.FetchGroceryTripCategory(decision.Name, tx)
+	if err != nil && decision.Name != MiscCategoryName {
+		// The store may have renamed or deleted the category we came up with
+		category, err = i.FetchGroceryTripCategory(MiscCategoryName, tx)
+		decision = miscCategoryDecision()
+	}
+	if err != nil {
+		return errors.New("could not find or create grocery trip category")
+	}
+	i.CategoryID = &category.ID
+	i.CategorySource = &decision.Source
+	i.CategoryConfidence = &decision.Confidence
 	return nil
 }
 
@@ -110,68 +192,32 @@ func (i *Item) AfterUpdate(tx *gorm.DB) (err error) {
 	return nil
 }
 
-// parseItemName handles inline quantity in the item name (e.g. Orange x 5) and
-// returns a parsed version of both the name and quantity
-func (i *Item) parseItemName() (parsedName string, parsedQuantity int) {
-	re := regexp.MustCompile("^(.*)(\\s)x(\\s?)(\\d+)(\\s+)?")
-	match := re.FindStringSubmatch(i.Name)
-	if match != nil {
-		var err error
-		parsedQuantity, err = strconv.Atoi(match[4])
-		if err != nil {
-			return i.Name, i.Quantity
-		}
-		// Strip the quantity out of the name
-		parsedName = re.ReplaceAllString(i.Name, "$1")
-		return parsedName, parsedQuantity
-	}
-	return i.Name, i.Quantity
+// ParseName parses the quantity, measured amount and notes out of the item's
+// name (e.g. "Orange x 5", "2 lbs apples", "bread (whole wheat)") and sets
+// them on the item instead
+func (i *Item) ParseName() {
+	i.parseItemName()
 }
 
-// DetermineCategoryName first checks to see if this item's preferred category
-// has been saved in the store settings and uses that if so.
-// As a fallback, it opens the FoodClassification.json file and scans it
-func (i *Item) DetermineCategoryName(storeID uuid.UUID, tx *gorm.DB) (result string, err error) {
-	result = "Misc."
-	name := strings.ToLower(i.Name) // for case-insensitivity
-
-	// Look for the category in store_item_category_settings
-	var settings StoreItemCategorySettings
-	query := tx.
-		Where("store_id = ?", storeID).
-		Where(datatypes.JSONQuery("items").HasKey(name)).
-		First(&settings).
-		Error
-	if !errors.Is(query, gorm.ErrRecordNotFound) {
-		if err := query; err != nil {
-			return result, err
-		}
-		itemSettings := settings.Items
-		var settingsMap map[string]interface{}
-		if err := json.Unmarshal(itemSettings, &settingsMap); err != nil {
-			return result, err
-		}
-		if settingsMap[name] != nil {
-			// There is an assigned storeCategoryID in settings for this item.
-			// From this we need to find the name of the category and return it
-			storeCategoryID, err := uuid.FromString(settingsMap[name].(string))
-			if err != nil {
-				return result, err
-			}
-			return FindStoreCategoryName(storeCategoryID, tx), nil
-		}
+// parseItemName parses the item's name with the parser package, in the
+// item's Locale. The quantity and amount are left alone when the name
+// doesn't include one, and parsed notes are added to any existing notes
+func (i *Item) parseItemName() {
+	result := parser.Parse(i.Name, i.Locale)
+	i.Name = result.Name
+	if result.Amount != nil {
+		i.Quantity = result.Quantity()
+		i.DecimalQuantity = result.DecimalQuantity()
+		i.Unit = result.Unit
 	}
-
-	// Use gjson to quickly fetch it from the embedded FoodClassification.json file
-	properName := strings.TrimSpace(name)
-	search := fmt.Sprintf("foods.#(text%%\"%s*\").label", properName)
-	value := gjson.Get(foods, search)
-	foundCategory := value.String()
-	if len(foundCategory) > 0 {
-		return foundCategory, nil
+	if len(result.Notes) > 0 {
+		notes := strings.Join(result.Notes, ", ")
+		if i.Notes != nil && *i.Notes != "" {
+			notes = *i.Notes + ", " + notes
+		}
+		notes = utils.TruncateString(notes, 255)
+		i.Notes = &notes
 	}
-
-	return result, nil
 }
 
 func FindStoreCategoryName(id uuid.UUID, tx *gorm.DB) (name string) {
@@ -182,7 +228,7 @@ func FindStoreCategoryName(id uuid.UUID, tx *gorm.DB) (name string) {
 		First(&storeCategory).
 		Error
 	if err := query; err != nil {
-		return "Misc."
+		return MiscCategoryName
 	}
 	return storeCategory.Name
 }
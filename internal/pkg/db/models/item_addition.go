@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// ItemAddition defines the model for item_additions, which record each time
+// an item was added to a trip once additions have been merged into it, so
+// that it's clear who asked for what (and for which meal)
+type ItemAddition struct {
+	ID              uuid.UUID  `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ItemID          uuid.UUID  `gorm:"type:uuid;not null;index"`
+	UserID          uuid.UUID  `gorm:"type:uuid;not null"`
+	Quantity        int        `gorm:"default:1;not null"`
+	DecimalQuantity *float64   `gorm:"type:numeric(10,3)"`
+	Unit            *string    `gorm:"type:varchar(20)"`
+	MealID          *uuid.UUID `gorm:"type:uuid"`
+	MealName        *string    `gorm:"type:varchar(255)"`
+
+	CreatedAt time.Time
+}
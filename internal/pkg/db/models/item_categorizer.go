@@ -0,0 +1,226 @@
+package models
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/classify"
+	uuid "github.com/satori/go.uuid"
+	"github.com/tidwall/gjson"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+//go:embed FoodClassification.json
+var foods string
+
+// Sources of an item's category, from the user's own choices to the fallback
+const (
+	// CategorySourceUser is a category the user chose for the item itself
+	CategorySourceUser = "user"
+	// CategorySourceStoreSetting is a category the store saved for the item's name
+	CategorySourceStoreSetting = "storeSetting"
+	// CategorySourcePinned is a category pinned by the staple item the item was added from
+	CategorySourcePinned = "pinned"
+	// CategorySourceCatalogue is the category of the product the item was scanned as
+	CategorySourceCatalogue = "catalogue"
+	// CategorySourceVocabulary is a category from the first food in the
+	// vocabulary that starts with the item's name (see RuleCategorizer)
+	CategorySourceVocabulary = "vocabulary"
+	// CategorySourceClassifier is a category from classifying the item's name
+	// word by word (see TokenCategorizer)
+	CategorySourceClassifier = "classifier"
+	// CategorySourceDefault is the Misc. category, when nothing else was found
+	CategorySourceDefault = "default"
+)
+
+// CategoryDecision is the category decided on for an item, along with how
+// sure the decision is (from 0 to 1) and how it was made
+type CategoryDecision struct {
+	Name       string
+	Confidence float64
+	Source     string
+}
+
+// Categorizer decides the category an item is added to in a store
+type Categorizer interface {
+	Categorize(tx *gorm.DB, storeID uuid.UUID, item *Item) (CategoryDecision, error)
+}
+
+// DefaultCategorizer is the Categorizer used when items are added or renamed
+var DefaultCategorizer Categorizer = TokenCategorizer{}
+
+// RuleCategorizer uses the category the store has saved for the item's name,
+// then the item's catalogue category, then the category of the first food in
+// the vocabulary that starts with the item's name, and finally Misc.
+type RuleCategorizer struct{}
+
+// Categorize satisfies the Categorizer interface
+func (RuleCategorizer) Categorize(tx *gorm.DB, storeID uuid.UUID, item *Item) (decision CategoryDecision, err error) {
+	if decision, ok, err := savedCategory(tx, storeID, item); ok || err != nil {
+		return decision, err
+	}
+	if food, ok := findFood(item.Name); ok {
+		decision = CategoryDecision{Name: food.Label, Confidence: 0.5, Source: CategorySourceVocabulary}
+		if strings.EqualFold(food.Text, strings.TrimSpace(item.Name)) {
+			decision.Confidence = 1
+		}
+		return decision, nil
+	}
+	return miscCategoryDecision(), nil
+}
+
+// TokenCategorizer uses the category the store has saved for the item's name,
+// then the item's catalogue category, then classifies the item's name with
+// FoodClassifier, and finally Misc. Unlike RuleCategorizer, it understands
+// plurals, synonyms and names that are more than the name of a food (i.e.
+// "frozen green peas" are Frozen Foods, and "bag of apples" are Produce)
+type TokenCategorizer struct{}
+
+// Categorize satisfies the Categorizer interface
+func (TokenCategorizer) Categorize(tx *gorm.DB, storeID uuid.UUID, item *Item) (decision CategoryDecision, err error) {
+	if decision, ok, err := savedCategory(tx, storeID, item); ok || err != nil {
+		return decision, err
+	}
+	if result, ok := FoodClassifier().Classify(item.Name); ok {
+		return CategoryDecision{Name: result.Label, Confidence: result.Confidence, Source: CategorySourceClassifier}, nil
+	}
+	return miscCategoryDecision(), nil
+}
+
+// DetermineCategory decides the category of the item in a store with the
+// DefaultCategorizer
+func (i *Item) DetermineCategory(storeID uuid.UUID, tx *gorm.DB) (CategoryDecision, error) {
+	return DefaultCategorizer.Categorize(tx, storeID, i)
+}
+
+// savedCategory returns the category saved for the item's name in the store
+// settings or, failing that, the item's catalogue category. It returns false
+// when there's neither
+func savedCategory(tx *gorm.DB, storeID uuid.UUID, item *Item) (decision CategoryDecision, ok bool, err error) {
+	name := strings.ToLower(item.Name) // for case-insensitivity
+
+	// Look for the category in store_item_category_settings
+	var settings StoreItemCategorySettings
+	query := tx.
+		Where("store_id = ?", storeID).
+		Where(datatypes.JSONQuery("items").HasKey(name)).
+		First(&settings).
+		Error
+	if !errors.Is(query, gorm.ErrRecordNotFound) {
+		if err := query; err != nil {
+			return decision, false, err
+		}
+		var settingsMap map[string]interface{}
+		if err := json.Unmarshal(settings.Items, &settingsMap); err != nil {
+			return decision, false, err
+		}
+		if settingsMap[name] != nil {
+			// There is an assigned storeCategoryID in settings for this item.
+			// From this we need to find the name of the category and return it
+			storeCategoryID, err := uuid.FromString(settingsMap[name].(string))
+			if err != nil {
+				return decision, false, err
+			}
+			decision = CategoryDecision{
+				Name:       FindStoreCategoryName(storeCategoryID, tx),
+				Confidence: 1,
+				Source:     CategorySourceStoreSetting,
+			}
+			return decision, true, nil
+		}
+	}
+
+	if item.DefaultCategoryName != "" {
+		decision = CategoryDecision{Name: item.DefaultCategoryName, Confidence: 0.9, Source: CategorySourceCatalogue}
+		return decision, true, nil
+	}
+	return decision, false, nil
+}
+
+// autoCategorised returns whether a category source is one of the
+// categorizers' own decisions, which are made again when an item is renamed.
+// Items categorised before sources were recorded count as decided this way
+func autoCategorised(source *string) bool {
+	if source == nil {
+		return true
+	}
+	switch *source {
+	case CategorySourceVocabulary, CategorySourceClassifier, CategorySourceDefault:
+		return true
+	}
+	return false
+}
+
+// miscCategoryDecision is the decision for an item that couldn't be categorised
+func miscCategoryDecision() CategoryDecision {
+	return CategoryDecision{Name: MiscCategoryName, Confidence: 0, Source: CategorySourceDefault}
+}
+
+// Food is an entry in the embedded FoodClassification.json file
+type Food struct {
+	Text  string
+	Label string
+}
+
+// Foods returns the entries in the embedded FoodClassification.json file
+func Foods() (result []Food) {
+	gjson.Get(foods, "foods").ForEach(func(_, value gjson.Result) bool {
+		result = append(result, Food{Text: value.Get("text").String(), Label: value.Get("label").String()})
+		return true
+	})
+	return result
+}
+
+// FoodCategoryName returns the category of the first food in the embedded
+// FoodClassification.json file that starts with the name provided, or an
+// empty string if there isn't one
+func FoodCategoryName(name string) string {
+	food, _ := findFood(name)
+	return food.Label
+}
+
+// findFood returns the first food in the embedded FoodClassification.json
+// file that starts with the name provided
+func findFood(name string) (food Food, ok bool) {
+	// Use gjson to quickly fetch it from the embedded FoodClassification.json file
+	properName := strings.TrimSpace(strings.ToLower(name))
+	search := fmt.Sprintf("foods.#(text%%\"%s*\")", properName)
+	result := gjson.Get(foods, search)
+	if !result.Exists() {
+		return food, false
+	}
+	return Food{Text: result.Get("text").String(), Label: result.Get("label").String()}, true
+}
+
+var (
+	foodClassifier     *classify.Classifier
+	foodClassifierOnce sync.Once
+)
+
+// FoodClassifier returns a classifier for the foods, synonyms and modifiers in
+// the embedded FoodClassification.json file, which is built once
+func FoodClassifier() *classify.Classifier {
+	foodClassifierOnce.Do(func() {
+		var entries []classify.Entry
+		for _, food := range Foods() {
+			entries = append(entries, classify.Entry{Text: food.Text, Label: food.Label})
+		}
+		foodClassifier = classify.New(entries, foodsMap("synonyms"), foodsMap("modifiers"))
+	})
+	return foodClassifier
+}
+
+// foodsMap returns an object in the embedded FoodClassification.json file as a map
+func foodsMap(key string) map[string]string {
+	result := map[string]string{}
+	gjson.Get(foods, key).ForEach(func(key, value gjson.Result) bool {
+		result[key.String()] = value.String()
+		return true
+	})
+	return result
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/rank"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ItemRankOrder is the order of the items in a category. Items given the same
+// rank by concurrent changes are ordered by ID until the category is rebalanced
+const ItemRankOrder = "items.rank, items.id"
+
+// RankItems returns ranks for n items placed at a position (from 1) among the
+// other items in a category, or at the bottom of it when position is 0. The
+// items being placed (itemIDs) are left out when finding their neighbours. If
+// there's no room left between the neighbours, the category is rebalanced first
+func RankItems(tx *gorm.DB, categoryID uuid.UUID, itemIDs []uuid.UUID, position int, n int) (ranks []string, err error) {
+	before, after, err := rankNeighbours(tx, categoryID, itemIDs, position)
+	if err != nil {
+		return ranks, err
+	}
+	ranks, err = rank.BetweenN(before, after, n)
+	if err == nil && !rank.TooLong(ranks...) {
+		return ranks, nil
+	}
+
+	if err := RebalanceCategory(tx, categoryID); err != nil {
+		return ranks, err
+	}
+	before, after, err = rankNeighbours(tx, categoryID, itemIDs, position)
+	if err != nil {
+		return ranks, err
+	}
+	return rank.BetweenN(before, after, n)
+}
+
+// rankNeighbours returns the ranks of the items either side of a position in a
+// category, leaving out the items in itemIDs. A blank rank means there is no
+// item on that side
+func rankNeighbours(tx *gorm.DB, categoryID uuid.UUID, itemIDs []uuid.UUID, position int) (before string, after string, err error) {
+	query := tx.Model(&Item{}).Where("category_id = ?", categoryID)
+	if len(itemIDs) > 0 {
+		query = query.Where("id NOT IN ?", itemIDs)
+	}
+
+	var ranks []string
+	if position <= 0 {
+		if err := query.Order("items.rank DESC, items.id DESC").Limit(1).Pluck("rank", &ranks).Error; err != nil {
+			return before, after, err
+		}
+		if len(ranks) > 0 {
+			before = ranks[0]
+		}
+		return before, after, nil
+	}
+	if position == 1 {
+		if err := query.Order(ItemRankOrder).Limit(1).Pluck("rank", &ranks).Error; err != nil {
+			return before, after, err
+		}
+		if len(ranks) > 0 {
+			after = ranks[0]
+		}
+		return before, after, nil
+	}
+	if err := query.Order(ItemRankOrder).Offset(position-2).Limit(2).Pluck("rank", &ranks).Error; err != nil {
+		return before, after, err
+	}
+	if len(ranks) == 0 {
+		// The position is past the bottom of the category
+		return rankNeighbours(tx, categoryID, itemIDs, 0)
+	}
+	before = ranks[0]
+	if len(ranks) > 1 {
+		after = ranks[1]
+	}
+	return before, after, nil
+}
+
+// RebalanceCategory gives the items in a category new, evenly spread ranks in
+// the order they're in. The items are locked while they're rebalanced so that
+// concurrent rebalances of the category queue up behind each other, which
+// means it must be called in a transaction for the lock to last until the new
+// ranks are written
+func RebalanceCategory(tx *gorm.DB, categoryID uuid.UUID) error {
+	itemIDs, err := lockCategoryItems(tx, categoryID)
+	if err != nil {
+		return err
+	}
+	return SetItemRanks(tx, itemIDs, rank.Spread(len(itemIDs)))
+}
+
+// LockCategory locks the items in a category until the end of the transaction,
+// so that an item can be ranked among them without another change to the
+// category's ranks slipping in between finding its neighbours and saving it
+func LockCategory(tx *gorm.DB, categoryID uuid.UUID) error {
+	_, err := lockCategoryItems(tx, categoryID)
+	return err
+}
+
+// lockCategoryItems locks the items in a category, and returns their IDs in order
+func lockCategoryItems(tx *gorm.DB, categoryID uuid.UUID) (itemIDs []uuid.UUID, err error) {
+	query := tx.
+		Model(&Item{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("category_id = ?", categoryID).
+		Order(ItemRankOrder).
+		Pluck("id", &itemIDs).
+		Error
+	if err := query; err != nil {
+		return itemIDs, err
+	}
+	return itemIDs, nil
+}
+
+// SetItemRanks sets the ranks of items in a single statement. This only
+// changes the order of the items, so it leaves their updated_at alone
+func SetItemRanks(tx *gorm.DB, itemIDs []uuid.UUID, ranks []string) error {
+	if len(itemIDs) != len(ranks) {
+		return errors.New("an item rank is needed for every item")
+	}
+	if len(itemIDs) == 0 {
+		return nil
+	}
+	values := make([]string, len(itemIDs))
+	args := make([]interface{}, 0, len(itemIDs)*2)
+	for i := range itemIDs {
+		values[i] = "(?::uuid, ?)"
+		args = append(args, itemIDs[i], ranks[i])
+	}
+	query := "UPDATE items SET rank = ranked.rank FROM (VALUES " + strings.Join(values, ", ") + ") AS ranked(id, rank) WHERE items.id = ranked.id"
+	return tx.Exec(query, args...).Error
+}
+
+// PositionInCategory returns the position (from 1) of the item among the items
+// in its category
+func (i *Item) PositionInCategory(tx *gorm.DB) (position int, err error) {
+	var count int64
+	query := tx.
+		Model(&Item{}).
+		Where("category_id = ?", i.CategoryID).
+		Where("(items.rank, items.id) < (?, ?)", i.Rank, i.ID).
+		Count(&count).
+		Error
+	if err := query; err != nil {
+		return position, err
+	}
+	return int(count) + 1, nil
+}
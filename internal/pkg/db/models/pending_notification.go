@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// PendingNotification defines the model for pending_notifications, which are
+// notifications held during a user's quiet hours to be delivered afterwards
+type PendingNotification struct {
+	ID      uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID  uuid.UUID `gorm:"type:uuid;not null"`
+	Channel string    `gorm:"type:varchar(10);not null"`
+	// Recipient is the device token for push notifications, or the email
+	// address for email notifications
+	Recipient  string    `gorm:"type:varchar(255);not null"`
+	Title      string    `gorm:"type:varchar(255);not null"`
+	Body       string    `gorm:"type:text;not null"`
+	EntityName string    `gorm:"type:varchar(50)"`
+	EntityID   string    `gorm:"type:varchar(50)"`
+	AppScheme  string    `gorm:"type:varchar(50)"`
+	DeliverAt  time.Time `gorm:"not null;index:idx_pending_notifications_deliver_at"`
+
+	CreatedAt time.Time
+}
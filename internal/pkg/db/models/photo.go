@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Photo owner types
+const (
+	PhotoOwnerItem   = "item"
+	PhotoOwnerRecipe = "recipe"
+)
+
+// PhotoRetentionPeriod is how long the photo of a deleted item or recipe is
+// kept (in case the deletion is undone) before it is purged
+const PhotoRetentionPeriod = 24 * time.Hour
+
+// Photo defines the model for photos, which are images uploaded for an item
+// or recipe. The image and its thumbnail are stored in the storage backend
+// under Key and ThumbnailKey
+type Photo struct {
+	ID           uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null"`
+	OwnerType    string    `gorm:"type:varchar(20);not null;index:idx_photos_owner"`
+	OwnerID      uuid.UUID `gorm:"type:uuid;not null;index:idx_photos_owner"`
+	Key          string    `gorm:"type:varchar(255);not null"`
+	ThumbnailKey string    `gorm:"type:varchar(255);not null"`
+	ContentType  string    `gorm:"type:varchar(50);not null"`
+	Size         int64     `gorm:"not null"`
+	Width        int       `gorm:"not null"`
+	Height       int       `gorm:"not null"`
+
+	CreatedAt time.Time
+}
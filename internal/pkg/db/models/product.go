@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Product defines the model for products, the local product catalogue that
+// barcodes are looked up in. It's imported from open data dumps (see
+// cmd/import-catalogue)
+type Product struct {
+	ID    uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Code  string    `gorm:"type:varchar(14);uniqueIndex;not null"`
+	Name  string    `gorm:"type:varchar(255);not null"`
+	Brand *string   `gorm:"type:varchar(255)"`
+	Size  *string   `gorm:"type:varchar(100)"`
+	// CategoryName is the name of the store category the product is added to
+	// by default (when the store hasn't saved a category for it)
+	CategoryName *string `gorm:"type:varchar(100)"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ProductName defines the model for product_names, the names that users have
+// given to barcodes. Names are remembered for a household, or for a store
+// when the store isn't shared with a household
+type ProductName struct {
+	ID          uuid.UUID  `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Code        string     `gorm:"type:varchar(14);not null;index"`
+	HouseholdID *uuid.UUID `gorm:"type:uuid;index"`
+	StoreID     *uuid.UUID `gorm:"type:uuid;index"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null"`
+	Name        string     `gorm:"type:varchar(100);not null"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
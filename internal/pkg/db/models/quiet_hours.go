@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// QuietHoursLayout is the layout of the start and end times of quiet hours
+const QuietHoursLayout = "15:04"
+
+// QuietHours defines the model for quiet_hours, a daily window during which
+// a user doesn't want to be disturbed by notifications
+type QuietHours struct {
+	ID       uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID   uuid.UUID `gorm:"type:uuid;uniqueIndex;not null"`
+	Start    string    `gorm:"type:varchar(5);not null"`
+	End      string    `gorm:"type:varchar(5);not null"`
+	Timezone string    `gorm:"type:varchar(64);not null"`
+	// HoldNotifications determines whether notifications sent during quiet
+	// hours are delivered once they end, or dropped
+	HoldNotifications *bool `gorm:"default:true;not null"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName overrides the default table name, which would otherwise be pluralized
+func (QuietHours) TableName() string {
+	return "quiet_hours"
+}
+
+// Until returns the time that quiet hours end if t falls within them. ok is
+// false if t is outside of quiet hours
+func (q *QuietHours) Until(t time.Time) (until time.Time, ok bool, err error) {
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return until, false, err
+	}
+	start, err := time.Parse(QuietHoursLayout, q.Start)
+	if err != nil {
+		return until, false, fmt.Errorf("invalid quiet hours start: %v", q.Start)
+	}
+	end, err := time.Parse(QuietHoursLayout, q.End)
+	if err != nil {
+		return until, false, fmt.Errorf("invalid quiet hours end: %v", q.End)
+	}
+
+	local := t.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	startAt := midnight.Add(time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute)
+	endAt := midnight.Add(time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute)
+
+	switch {
+	case !startAt.After(endAt):
+		// Quiet hours within a single day i.e. 13:00 - 15:00
+		if !local.Before(startAt) && local.Before(endAt) {
+			return endAt, true, nil
+		}
+	case !local.Before(startAt):
+		// Quiet hours overnight i.e. 22:00 - 07:00, after they start
+		return endAt.AddDate(0, 0, 1), true, nil
+	case local.Before(endAt):
+		// Quiet hours overnight, before they end
+		return endAt, true, nil
+	}
+	return until, false, nil
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"strings"
 	"time"
 
@@ -10,12 +11,48 @@ import (
 	"gorm.io/gorm"
 )
 
+// ArchiveRetentionPeriod is how long an archived store can be restored before
+// it is permanently deleted
+const ArchiveRetentionPeriod = 30 * 24 * time.Hour
+
+// DefaultGeofenceRadius is the radius in metres around a store's location
+// that a user is considered to be at the store, if the store doesn't set one
+const DefaultGeofenceRadius = 150
+
 type Store struct {
 	ID        uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	UserID    uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();not null"`
 	Name      string    `gorm:"type:varchar(100);not null"`
 	ShareCode string    `gorm:"type:varchar(255);uniqueIndex"`
 
+	// Location of the store, used to find the stores that a user is near.
+	// GeofenceRadius is in metres; DefaultGeofenceRadius is used when it's not set
+	Address        *string  `gorm:"type:varchar(255)"`
+	Latitude       *float64 `gorm:"type:double precision"`
+	Longitude      *float64 `gorm:"type:double precision"`
+	GeofenceRadius *int
+
+	// HouseholdID is set when the store is shared with a household, in which
+	// case all of the household's members are added to the store
+	HouseholdID *uuid.UUID `gorm:"type:uuid;index"`
+
+	// MergeDuplicateItems controls whether adding an item that's already on
+	// the current trip increases its quantity instead of adding another row
+	MergeDuplicateItems *bool `gorm:"default:true;not null"`
+
+	// ArchivedAt is set when a store is deleted by its creator. Archived stores
+	// are hidden but can be restored until ArchiveRetentionPeriod has passed,
+	// at which point they are purged for real
+	ArchivedAt *time.Time `gorm:"index"`
+
+	// Template, when set before the store is created, is used to seed the
+	// store's categories, staple items and item category settings instead
+	// of the default categories
+	Template *StoreTemplate `gorm:"-"`
+	// householdChanged is set when the store is created in a household, or
+	// moved to another one, so that its members are only added to the store then
+	householdChanged bool
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt
@@ -28,9 +65,23 @@ type Store struct {
 // BeforeCreate handles some prep work before a store is created
 func (s *Store) BeforeCreate(tx *gorm.DB) (err error) {
 	s.ShareCode = strings.ToUpper(utils.RandString(6))
+	s.householdChanged = s.HouseholdID != nil
 	return
 }
 
+// BeforeUpdate hook notes whether the store is being moved to another household
+func (s *Store) BeforeUpdate(tx *gorm.DB) (err error) {
+	if s.HouseholdID == nil {
+		return nil
+	}
+	var stored Store
+	if err := tx.Select("household_id").Where("id = ?", s.ID).Find(&stored).Error; err != nil {
+		return err
+	}
+	s.householdChanged = stored.HouseholdID == nil || *stored.HouseholdID != *s.HouseholdID
+	return nil
+}
+
 // AfterCreate hook to automatically create some associated records
 func (s *Store) AfterCreate(tx *gorm.DB) (err error) {
 	// Create default store user (creator)
@@ -46,12 +97,18 @@ func (s *Store) AfterCreate(tx *gorm.DB) (err error) {
 		return err
 	}
 
-	categories := fetchCategories()
-	for i := range categories {
-		storeCategory := &StoreCategory{StoreID: s.ID, Name: categories[i]}
-		if err := tx.Create(&storeCategory).Error; err != nil {
+	if s.Template != nil {
+		if err := s.applyTemplate(tx); err != nil {
 			return err
 		}
+	} else {
+		categories := fetchCategories()
+		for i := range categories {
+			storeCategory := &StoreCategory{StoreID: s.ID, Name: categories[i], Position: i + 1}
+			if err := tx.Create(&storeCategory).Error; err != nil {
+				return err
+			}
+		}
 	}
 
 	// Create default grocery trip
@@ -70,6 +127,32 @@ func (s *Store) AfterCreate(tx *gorm.DB) (err error) {
 	return nil
 }
 
+// AfterSave hook adds the active members of the store's household to the
+// store, when it has been created in or moved to a household. Saving the store
+// otherwise leaves its members alone, so that members who were removed from
+// the store aren't added back
+func (s *Store) AfterSave(tx *gorm.DB) (err error) {
+	if s.HouseholdID == nil || !s.householdChanged {
+		return nil
+	}
+	s.householdChanged = false
+	var householdUsers []HouseholdUser
+	query := tx.
+		Select("user_id").
+		Where("household_id = ? AND active = ?", s.HouseholdID, true).
+		Find(&householdUsers).
+		Error
+	if err := query; err != nil {
+		return err
+	}
+	for i := range householdUsers {
+		if _, _, err := addStoreUser(s.ID, householdUsers[i].UserID, s.HouseholdID, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // AfterDelete hook handles deleting associated records after store is deleted
 func (s *Store) AfterDelete(tx *gorm.DB) (err error) {
 	// Delete items associated with this store
@@ -121,6 +204,57 @@ func (s *Store) AfterDelete(tx *gorm.DB) (err error) {
 	return
 }
 
+// applyTemplate seeds the store's categories, staple items and item category
+// settings from s.Template
+func (s *Store) applyTemplate(tx *gorm.DB) (err error) {
+	categories, err := s.Template.CategoryNames()
+	if err != nil {
+		return err
+	}
+	categoryIDs := make(map[string]uuid.UUID)
+	for i := range categories {
+		storeCategory := &StoreCategory{StoreID: s.ID, Name: categories[i], Position: i + 1}
+		if err := tx.Create(&storeCategory).Error; err != nil {
+			return err
+		}
+		categoryIDs[categories[i]] = storeCategory.ID
+	}
+
+	staples, err := s.Template.StapleItemNames()
+	if err != nil {
+		return err
+	}
+	for i := range staples {
+		stapleItem := &StoreStapleItem{StoreID: s.ID, Name: staples[i]}
+		if err := tx.Create(&stapleItem).Error; err != nil {
+			return err
+		}
+	}
+
+	templateSettings, err := s.Template.ItemCategorySettingsMap()
+	if err != nil {
+		return err
+	}
+	settings := make(map[string]uuid.UUID)
+	for itemName, categoryName := range templateSettings {
+		if categoryID, ok := categoryIDs[categoryName]; ok {
+			settings[itemName] = categoryID
+		}
+	}
+	if len(settings) > 0 {
+		items, err := json.Marshal(settings)
+		if err != nil {
+			return err
+		}
+		itemCategorySettings := &StoreItemCategorySettings{StoreID: s.ID, Items: items}
+		if err := tx.Create(&itemCategorySettings).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func fetchCategories() [20]string {
 	categories := [20]string{
 		"Produce",
@@ -142,7 +276,7 @@ func fetchCategories() [20]string {
 		"Baby",
 		"Alcohol",
 		"Pharmacy",
-		"Misc.",
+		MiscCategoryName,
 	}
 	return categories
 }
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Actions recorded in the store activity log
+const (
+	ActivityItemAdded     = "item_added"
+	ActivityItemUpdated   = "item_updated"
+	ActivityItemMerged    = "item_merged"
+	ActivityItemCompleted = "item_completed"
+	ActivityItemDeleted   = "item_deleted"
+	ActivityItemRestored  = "item_restored"
+	ActivityItemReordered = "item_reordered"
+	ActivityItemAssigned  = "item_assigned"
+	ActivityItemMoved     = "item_moved"
+	ActivityTripCompleted = "trip_completed"
+	ActivityTripReopened  = "trip_reopened"
+	ActivityMemberJoined  = "member_joined"
+	ActivityMemberLeft    = "member_left"
+	ActivityStapleAdded   = "staple_added"
+	ActivityStapleRemoved = "staple_removed"
+	ActivityStapleChanged = "staple_changed"
+	ActivityStoreRenamed  = "store_renamed"
+)
+
+// Bulk item actions, which are recorded once for each trip changed, with the
+// names of its items that were changed
+const (
+	ActivityItemsCompleted     = "items_completed"
+	ActivityItemsUncompleted   = "items_uncompleted"
+	ActivityItemsDeleted       = "items_deleted"
+	ActivityItemsMoved         = "items_moved"
+	ActivityItemsRecategorised = "items_recategorised"
+)
+
+// StoreActivity is an entry in a store's activity log. Entries are append-only
+type StoreActivity struct {
+	ID      uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	StoreID uuid.UUID `gorm:"type:uuid;not null;index:idx_store_activities_store_id_created_at,priority:1"`
+	// UserID is the user who performed the action. It is empty for actions
+	// performed by the system (e.g. scheduled jobs)
+	UserID uuid.UUID `gorm:"type:uuid"`
+	Action string    `gorm:"type:varchar(50);not null"`
+
+	// The record the action was performed on, and its name at the time
+	EntityType string     `gorm:"type:varchar(50);not null"`
+	EntityID   *uuid.UUID `gorm:"type:uuid"`
+	EntityName string     `gorm:"type:varchar(255)"`
+
+	// Changes maps each changed attribute to its before and after values
+	Changes datatypes.JSON
+
+	CreatedAt time.Time `gorm:"index:idx_store_activities_store_id_created_at,priority:2"`
+
+	// Associations
+	Store Store
+	User  User
+}
+
+// BeforeUpdate hook prevents activity entries from being changed
+func (sa *StoreActivity) BeforeUpdate(tx *gorm.DB) (err error) {
+	return errors.New("store activity can't be changed")
+}
+
+// BeforeDelete hook prevents activity entries from being deleted
+func (sa *StoreActivity) BeforeDelete(tx *gorm.DB) (err error) {
+	return errors.New("store activity can't be deleted")
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"regexp"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// DefaultCurrency is the currency assumed for item prices that don't specify
+// one, in stores without a budget
+const DefaultCurrency = "USD"
+
+// BudgetMonthLayout is the layout of the month a budget notification was sent for
+const BudgetMonthLayout = "2006-01"
+
+var currencyCode = regexp.MustCompile("^[A-Z]{3}$")
+
+// ValidCurrency returns whether currency is an ISO 4217 style currency code
+func ValidCurrency(currency string) bool {
+	return currencyCode.MatchString(currency)
+}
+
+// StoreBudget defines the model for store_budgets, the amount a store's
+// members plan to spend there each calendar month (in UTC)
+type StoreBudget struct {
+	ID      uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	StoreID uuid.UUID `gorm:"type:uuid;uniqueIndex;not null"`
+	// MonthlyAmount is in the minor unit of the currency (i.e. cents)
+	MonthlyAmount int64  `gorm:"not null"`
+	Currency      string `gorm:"type:varchar(3);not null"`
+	// NotifiedMonth is the last month that members were notified about going
+	// over budget, so that they're only notified once a month
+	NotifiedMonth *string `gorm:"type:varchar(7)"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
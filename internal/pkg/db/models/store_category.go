@@ -7,10 +7,16 @@ import (
 	"gorm.io/gorm"
 )
 
+// MiscCategoryName is the name of the category that items fall back to when no
+// other category applies. It can't be renamed or deleted.
+const MiscCategoryName = "Misc."
+
 type StoreCategory struct {
 	ID      uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	StoreID uuid.UUID `gorm:"type:uuid;not null;index:idx_store_categories_store_id"`
 	Name    string    `gorm:"type:varchar(100);not null"`
+	// Position determines the order that categories are listed in for a trip
+	Position int `gorm:"default:0;not null"`
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
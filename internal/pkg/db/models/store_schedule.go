@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// ShoppingReminderHour is the hour of the evening before a planned shopping
+// date that store members are reminded about the trip
+const ShoppingReminderHour = 18
+
+// StoreSchedule defines the model for store_schedules, a recurring weekly
+// shopping day for a store. When the shopping day passes, the store's current
+// trip is completed and a new trip is planned for the next shopping day
+type StoreSchedule struct {
+	ID      uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	StoreID uuid.UUID `gorm:"type:uuid;uniqueIndex;not null"`
+	// Weekday is the day of the week to shop on, where 0 is Sunday
+	Weekday  int    `gorm:"not null"`
+	Timezone string `gorm:"type:varchar(64);not null"`
+	// CopyRemainingItems determines whether items that weren't picked up are
+	// moved to the next trip when the current trip is completed
+	CopyRemainingItems *bool `gorm:"default:true;not null"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NextShoppingDate returns the first scheduled shopping date on or after the
+// date of t in the schedule's timezone
+func (s *StoreSchedule) NextShoppingDate(t time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return t, err
+	}
+	local := t.In(loc)
+	date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	days := (s.Weekday - int(date.Weekday()) + 7) % 7
+	return date.AddDate(0, 0, days), nil
+}
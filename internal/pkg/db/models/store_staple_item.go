@@ -8,10 +8,41 @@ import (
 	uuid "github.com/satori/go.uuid"
 )
 
+// Staple cadences, which determine the new trips a staple is added to
+const (
+	// StapleCadenceEveryTrip adds the staple to every new trip
+	StapleCadenceEveryTrip = "every_trip"
+	// StapleCadenceTrips adds the staple to every Interval-th new trip
+	StapleCadenceTrips = "trips"
+	// StapleCadenceDays adds the staple once Interval days have passed since
+	// it was last purchased (or added, if it hasn't been purchased)
+	StapleCadenceDays = "days"
+	// StapleCadenceWeekdays adds the staple once one of its Weekdays has come
+	// around since it was last added
+	StapleCadenceWeekdays = "weekdays"
+)
+
 type StoreStapleItem struct {
 	ID      uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	StoreID uuid.UUID `gorm:"type:uuid;not null;index:idx_store_staple_items_store_id"`
 	Name    string    `gorm:"type:varchar(100);not null"`
+	// Quantity, DecimalQuantity, Unit and Notes are copied to the item each
+	// time the staple is added to a trip (see Item for how they relate)
+	Quantity        int      `gorm:"default:1;not null"`
+	DecimalQuantity *float64 `gorm:"type:numeric(10,3)"`
+	Unit            *string  `gorm:"type:varchar(20)"`
+	Notes           *string  `gorm:"type:varchar(255)"`
+	// StoreCategoryID pins the staple to a category, instead of the category
+	// being determined by the store settings each time it's added to a trip
+	StoreCategoryID *uuid.UUID `gorm:"type:uuid"`
+	// Cadence is one of the StapleCadence constants. Interval is the N of
+	// every N trips or days, and Weekdays is a bitmask of days of the week
+	// (1 << time.Weekday) for the weekdays cadence
+	Cadence         string `gorm:"type:varchar(10);default:'every_trip';not null"`
+	Interval        int    `gorm:"default:1;not null"`
+	Weekdays        int    `gorm:"default:0;not null"`
+	LastAddedAt     *time.Time
+	LastPurchasedAt *time.Time
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -20,3 +51,71 @@ type StoreStapleItem struct {
 	// Associations
 	Store Store
 }
+
+// OnWeekday returns whether day is one of the staple's weekdays
+func (s *StoreStapleItem) OnWeekday(day time.Weekday) bool {
+	return s.Weekdays&(1<<uint(day)) != 0
+}
+
+// TripsSinceAdded counts the trips created in the staple's store since it
+// was last added to a trip
+func (s *StoreStapleItem) TripsSinceAdded(tx *gorm.DB) (count int64, err error) {
+	query := tx.Model(&GroceryTrip{}).Where("store_id = ?", s.StoreID)
+	if s.LastAddedAt != nil {
+		query = query.Where("created_at > ?", s.LastAddedAt)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return count, err
+	}
+	return count, nil
+}
+
+// NextDueDate returns the date the staple is next due for the days and
+// weekdays cadences, or nil when it's due right away (or has another cadence)
+func (s *StoreStapleItem) NextDueDate() *time.Time {
+	switch s.Cadence {
+	case StapleCadenceDays:
+		since := s.LastPurchasedAt
+		if since == nil {
+			since = s.LastAddedAt
+		}
+		if since == nil {
+			return nil
+		}
+		next := dateOf(*since).AddDate(0, 0, s.Interval)
+		return &next
+	case StapleCadenceWeekdays:
+		if s.LastAddedAt == nil || s.Weekdays == 0 {
+			return nil
+		}
+		next := dateOf(*s.LastAddedAt)
+		for i := 0; i < 7; i++ {
+			next = next.AddDate(0, 0, 1)
+			if s.OnWeekday(next.Weekday()) {
+				break
+			}
+		}
+		return &next
+	}
+	return nil
+}
+
+// DueOn returns whether the staple should be added to a new trip planned for
+// date, when tripsSinceAdded trips have been created since it was last added
+// (including the new trip)
+func (s *StoreStapleItem) DueOn(date time.Time, tripsSinceAdded int64) bool {
+	switch s.Cadence {
+	case StapleCadenceTrips:
+		return s.LastAddedAt == nil || tripsSinceAdded >= int64(s.Interval)
+	case StapleCadenceDays, StapleCadenceWeekdays:
+		next := s.NextDueDate()
+		return next == nil || !next.After(dateOf(date))
+	}
+	return true
+}
+
+// dateOf truncates a time to its date in UTC
+func dateOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
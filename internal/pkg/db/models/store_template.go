@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// StoreTemplate is a reusable snapshot of a store's setup that a new store
+// can be created from
+type StoreTemplate struct {
+	ID     uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index:idx_store_templates_user_id"`
+	Name   string    `gorm:"type:varchar(100);not null"`
+
+	// Categories is the ordered list of category names
+	Categories datatypes.JSON
+	// StapleItems is the list of staple item names
+	StapleItems datatypes.JSON
+	// ItemCategorySettings maps item names to category names (rather than to
+	// store category IDs like StoreItemCategorySettings does)
+	ItemCategorySettings datatypes.JSON
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+
+	// Associations
+	User User
+}
+
+// CategoryNames returns the template's category names, always ending with
+// the Misc. category since items fall back to it
+func (t *StoreTemplate) CategoryNames() (names []string, err error) {
+	if len(t.Categories) > 0 {
+		if err := json.Unmarshal(t.Categories, &names); err != nil {
+			return names, err
+		}
+	}
+	for i := range names {
+		if names[i] == MiscCategoryName {
+			return names, nil
+		}
+	}
+	return append(names, MiscCategoryName), nil
+}
+
+// StapleItemNames returns the template's staple item names
+func (t *StoreTemplate) StapleItemNames() (names []string, err error) {
+	if len(t.StapleItems) > 0 {
+		if err := json.Unmarshal(t.StapleItems, &names); err != nil {
+			return names, err
+		}
+	}
+	return names, nil
+}
+
+// ItemCategorySettingsMap returns the template's item name to category name mapping
+func (t *StoreTemplate) ItemCategorySettingsMap() (settings map[string]string, err error) {
+	settings = make(map[string]string)
+	if len(t.ItemCategorySettings) > 0 {
+		if err := json.Unmarshal(t.ItemCategorySettings, &settings); err != nil {
+			return settings, err
+		}
+	}
+	return settings, nil
+}
@@ -17,6 +17,10 @@ type StoreUser struct {
 	Creator *bool     `gorm:"default:false;not null"`
 	Active  *bool     `gorm:"default:true;not null"`
 
+	// HouseholdID is set when the user was added to the store by a household,
+	// so that they're removed from it again when they leave the household
+	HouseholdID *uuid.UUID `gorm:"type:uuid;index:idx_store_users_household_id"`
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt
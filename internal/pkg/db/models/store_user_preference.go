@@ -8,17 +8,98 @@ import (
 	uuid "github.com/satori/go.uuid"
 )
 
+// Kinds of notifications that store users can opt in or out of
+const (
+	NotificationItemsAdded    = "itemsAdded"
+	NotificationMeals         = "meals"
+	NotificationMemberJoined  = "memberJoined"
+	NotificationStoreChanges  = "storeChanges"
+	NotificationTripReminders = "tripReminders"
+	NotificationBudgetAlerts  = "budgetAlerts"
+	NotificationItemAssigned  = "itemAssigned"
+)
+
+// Channels that notifications can be delivered through
+const (
+	NotificationChannelPush  = "push"
+	NotificationChannelEmail = "email"
+)
+
 type StoreUserPreference struct {
-	ID            uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	StoreUserID   uuid.UUID `gorm:"type:uuid;uniqueIndex;not null"`
-	DefaultStore  bool      `gorm:"default:false;not null"`
-	Notifications bool      `gorm:"default:true;not null"`
+	ID           uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	StoreUserID  uuid.UUID `gorm:"type:uuid;uniqueIndex;not null"`
+	DefaultStore bool      `gorm:"default:false;not null"`
+	// Suggestions is whether the store user sees "you usually buy"
+	// suggestions from their purchase history
+	Suggestions bool `gorm:"default:true;not null"`
+
+	// Notification preferences for each kind of notification and channel
+	PushItemsAdded     bool `gorm:"default:true;not null"`
+	PushMeals          bool `gorm:"default:true;not null"`
+	PushMemberJoined   bool `gorm:"default:true;not null"`
+	PushStoreChanges   bool `gorm:"default:true;not null"`
+	PushTripReminders  bool `gorm:"default:true;not null"`
+	PushBudgetAlerts   bool `gorm:"default:true;not null"`
+	PushItemAssigned   bool `gorm:"default:true;not null"`
+	EmailItemsAdded    bool `gorm:"default:false;not null"`
+	EmailMeals         bool `gorm:"default:false;not null"`
+	EmailMemberJoined  bool `gorm:"default:false;not null"`
+	EmailStoreChanges  bool `gorm:"default:false;not null"`
+	EmailTripReminders bool `gorm:"default:false;not null"`
+	EmailBudgetAlerts  bool `gorm:"default:false;not null"`
+	EmailItemAssigned  bool `gorm:"default:false;not null"`
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt
 }
 
+// NotificationEnabled returns whether the store user wants to receive the
+// kind of notification provided through the channel provided
+func (sup *StoreUserPreference) NotificationEnabled(channel string, kind string) bool {
+	if channel == NotificationChannelEmail {
+		switch kind {
+		case NotificationItemsAdded:
+			return sup.EmailItemsAdded
+		case NotificationMeals:
+			return sup.EmailMeals
+		case NotificationMemberJoined:
+			return sup.EmailMemberJoined
+		case NotificationStoreChanges:
+			return sup.EmailStoreChanges
+		case NotificationTripReminders:
+			return sup.EmailTripReminders
+		case NotificationBudgetAlerts:
+			return sup.EmailBudgetAlerts
+		case NotificationItemAssigned:
+			return sup.EmailItemAssigned
+		}
+		return false
+	}
+	switch kind {
+	case NotificationItemsAdded:
+		return sup.PushItemsAdded
+	case NotificationMeals:
+		return sup.PushMeals
+	case NotificationMemberJoined:
+		return sup.PushMemberJoined
+	case NotificationStoreChanges:
+		return sup.PushStoreChanges
+	case NotificationTripReminders:
+		return sup.PushTripReminders
+	case NotificationBudgetAlerts:
+		return sup.PushBudgetAlerts
+	case NotificationItemAssigned:
+		return sup.PushItemAssigned
+	}
+	return false
+}
+
+// PushEnabled returns whether any kind of push notification is enabled
+func (sup *StoreUserPreference) PushEnabled() bool {
+	return sup.PushItemsAdded || sup.PushMeals || sup.PushMemberJoined || sup.PushStoreChanges || sup.PushTripReminders || sup.PushBudgetAlerts || sup.PushItemAssigned
+}
+
 // AfterUpdate hook handles some cleanup operations after updating store user prefs
 func (sup *StoreUserPreference) AfterUpdate(tx *gorm.DB) (err error) {
 	if sup.DefaultStore {
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// SuggestionDismissal defines the model for suggestion_dismissals, which hide
+// a suggested item from a user until the item is bought again
+type SuggestionDismissal struct {
+	ID      uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	StoreID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_suggestion_dismissals_store_user_name"`
+	UserID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_suggestion_dismissals_store_user_name"`
+	// Name is the normalised (lowercased and trimmed) name of the item
+	Name        string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_suggestion_dismissals_store_user_name"`
+	DismissedAt time.Time `gorm:"not null"`
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/datatypes"
+)
+
+// TripRollover defines the model for trip_rollovers, which record what
+// completing a trip did (creating the next trip and carrying items over to
+// it) so that it can be undone by reopening the trip
+type TripRollover struct {
+	ID            uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	GroceryTripID uuid.UUID `gorm:"type:uuid;not null;index"`
+	NextTripID    uuid.UUID `gorm:"type:uuid;not null"`
+	// CompletedItemIDs lists the items that were marked as completed when
+	// the trip was completed
+	CompletedItemIDs datatypes.JSON
+	// CopiedItemIDs maps the ID of each item copied to the next trip to the
+	// ID of the item it was copied from
+	CopiedItemIDs datatypes.JSON
+	// StapleLastAddedAt maps the ID of each staple item added to the next
+	// trip to when it had last been added before then
+	StapleLastAddedAt datatypes.JSON
+
+	CreatedAt time.Time
+}
+
+// CompletedItems decodes CompletedItemIDs
+func (r *TripRollover) CompletedItems() (ids []uuid.UUID, err error) {
+	if len(r.CompletedItemIDs) == 0 {
+		return ids, nil
+	}
+	err = json.Unmarshal(r.CompletedItemIDs, &ids)
+	return ids, err
+}
+
+// CopiedItems decodes CopiedItemIDs
+func (r *TripRollover) CopiedItems() (ids map[uuid.UUID]uuid.UUID, err error) {
+	ids = map[uuid.UUID]uuid.UUID{}
+	if len(r.CopiedItemIDs) == 0 {
+		return ids, nil
+	}
+	err = json.Unmarshal(r.CopiedItemIDs, &ids)
+	return ids, err
+}
+
+// StaplesLastAddedAt decodes StapleLastAddedAt
+func (r *TripRollover) StaplesLastAddedAt() (lastAddedAt map[uuid.UUID]*time.Time, err error) {
+	lastAddedAt = map[uuid.UUID]*time.Time{}
+	if len(r.StapleLastAddedAt) == 0 {
+		return lastAddedAt, nil
+	}
+	err = json.Unmarshal(r.StapleLastAddedAt, &lastAddedAt)
+	return lastAddedAt, err
+}
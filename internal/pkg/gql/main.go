@@ -36,6 +36,24 @@ func init() {
 					Description: "Retrieve stores for the current user",
 					Resolve:     resolvers.StoresResolver,
 				},
+				"archivedStores": &graphql.Field{
+					Type:        graphql.NewList(gql.StoreType),
+					Description: "Retrieve deleted stores that the current user can still restore",
+					Resolve:     resolvers.ArchivedStoresResolver,
+				},
+				"nearbyStores": &graphql.Field{
+					Type:        graphql.NewList(gql.NearbyStoreType),
+					Description: "Retrieve the current user's stores that have a location, nearest first",
+					Args: graphql.FieldConfigArgument{
+						"lat": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.Float),
+						},
+						"lng": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.Float),
+						},
+					},
+					Resolve: resolvers.NearbyStoresResolver,
+				},
 				"invitedStores": &graphql.Field{
 					Type:        graphql.NewList(gql.StoreInviteType),
 					Description: "Retrieve stores the current user has been invited to",
@@ -61,6 +79,11 @@ func init() {
 					},
 					Resolve: resolvers.StoreUserPrefsResolver,
 				},
+				"quietHours": &graphql.Field{
+					Type:        gql.QuietHoursType,
+					Description: "Retrieves the current user's quiet hours for notifications",
+					Resolve:     resolvers.QuietHoursResolver,
+				},
 				"storeCategories": &graphql.Field{
 					Type:        graphql.NewList(gql.StoreCategoryType),
 					Description: "Retrieves store categories for a store",
@@ -71,6 +94,83 @@ func init() {
 					},
 					Resolve: resolvers.StoreCategoriesResolver,
 				},
+				"households": &graphql.Field{
+					Type:        graphql.NewList(gql.HouseholdType),
+					Description: "Retrieve the households the current user belongs to",
+					Resolve:     resolvers.HouseholdsResolver,
+				},
+				"invitedHouseholds": &graphql.Field{
+					Type:        graphql.NewList(gql.HouseholdType),
+					Description: "Retrieve households the current user has been invited to",
+					Resolve:     resolvers.InvitedHouseholdsResolver,
+				},
+				"storeActivity": &graphql.Field{
+					Type:        graphql.NewList(gql.StoreActivityType),
+					Description: "Retrieve a store's activity log, newest first",
+					Args: graphql.FieldConfigArgument{
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"limit": &graphql.ArgumentConfig{
+							Type: graphql.Int,
+						},
+						"offset": &graphql.ArgumentConfig{
+							Type: graphql.Int,
+						},
+					},
+					Resolve: resolvers.StoreActivityResolver,
+				},
+				"spending": &graphql.Field{
+					Type:        graphql.NewList(gql.CategorySpendingType),
+					Description: "Retrieve the spending at a store between two dates (YYYY-MM-DD, inclusive) by category",
+					Args: graphql.FieldConfigArgument{
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"from": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+						"to": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+					},
+					Resolve: resolvers.SpendingResolver,
+				},
+				"stapleItems": &graphql.Field{
+					Type:        graphql.NewList(gql.StoreStapleItemType),
+					Description: "Retrieve a store's staple items",
+					Args: graphql.FieldConfigArgument{
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.StapleItemsResolver,
+				},
+				"suggestedItems": &graphql.Field{
+					Type:        graphql.NewList(gql.SuggestedItemType),
+					Description: "Retrieve the items the current user probably needs to buy from a store, based on their purchase history",
+					Args: graphql.FieldConfigArgument{
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.SuggestedItemsResolver,
+				},
+				"upcomingStapleItems": &graphql.Field{
+					Type:        graphql.NewList(gql.UpcomingStapleItemType),
+					Description: "Retrieve a store's staple items and when they're next due to be added to a trip, soonest first",
+					Args: graphql.FieldConfigArgument{
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.UpcomingStapleItemsResolver,
+				},
+				"storeTemplates": &graphql.Field{
+					Type:        graphql.NewList(gql.StoreTemplateType),
+					Description: "Retrieve the current user's store templates",
+					Resolve:     resolvers.StoreTemplatesResolver,
+				},
 				"trips": &graphql.Field{
 					Type:        graphql.NewList(gql.GroceryTripType),
 					Description: "Retrieve trip history for a store",
@@ -94,9 +194,20 @@ func init() {
 					},
 					Resolve: resolvers.GroceryTripResolver,
 				},
+				"recentlyDeletedItems": &graphql.Field{
+					Type:        graphql.NewList(gql.ItemType),
+					Description: "Retrieve the items deleted from a trip that can still be restored",
+					Args: graphql.FieldConfigArgument{
+						"tripId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.RecentlyDeletedItemsResolver,
+				},
 				"itemSearch": &graphql.Field{
-					Type:        gql.ItemType,
-					Description: "Search for an item in the user's stores by name",
+					Type:              gql.ItemType,
+					Description:       "Search for an item in the user's stores by name",
+					DeprecationReason: "Use searchItems",
 					Args: graphql.FieldConfigArgument{
 						"name": &graphql.ArgumentConfig{
 							Type: graphql.NewNonNull(graphql.String),
@@ -104,6 +215,44 @@ func init() {
 					},
 					Resolve: resolvers.ItemSearchResolver,
 				},
+				"searchItems": &graphql.Field{
+					Type:        gql.ItemSearchResultsType,
+					Description: "Search the items (including completed items and past trips) in the user's stores, tolerating typos. Results are ranked by similarity and grouped by store and trip",
+					Args: graphql.FieldConfigArgument{
+						"query": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+						"storeId": &graphql.ArgumentConfig{
+							Type:        graphql.ID,
+							Description: "Only search the items in this store",
+						},
+						"limit": &graphql.ArgumentConfig{
+							Type:        graphql.Int,
+							Description: "How many items to return (20 by default, at most 100)",
+						},
+						"offset": &graphql.ArgumentConfig{
+							Type: graphql.Int,
+						},
+					},
+					Resolve: resolvers.SearchItemsResolver,
+				},
+				"itemAutocomplete": &graphql.Field{
+					Type:        graphql.NewList(gql.ItemCompletionType),
+					Description: "Complete an item name from a store's history, staple items and category settings, and common foods",
+					Args: graphql.FieldConfigArgument{
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"prefix": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+						"limit": &graphql.ArgumentConfig{
+							Type:        graphql.Int,
+							Description: "How many completions to return (10 by default, at most 50)",
+						},
+					},
+					Resolve: resolvers.ItemAutocompleteResolver,
+				},
 				"recipes": &graphql.Field{
 					Type:        graphql.NewList(gql.RecipeType),
 					Description: "Retrieve recipes added by the current user",
@@ -252,12 +401,55 @@ func init() {
 						"name": &graphql.ArgumentConfig{
 							Type: graphql.NewNonNull(graphql.String),
 						},
+						"templateId": &graphql.ArgumentConfig{
+							Type:        graphql.ID,
+							Description: "Create the store from one of the user's store templates",
+						},
 					},
 					Resolve: resolvers.CreateStoreResolver,
 				},
+				"duplicateStore": &graphql.Field{
+					Type:        gql.StoreType,
+					Description: "Create a copy of a store",
+					Args: graphql.FieldConfigArgument{
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"name": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+						"include": &graphql.ArgumentConfig{
+							Type: graphql.NewList(graphql.NewNonNull(gql.StoreDuplicateIncludeEnum)),
+						},
+					},
+					Resolve: resolvers.DuplicateStoreResolver,
+				},
+				"saveStoreTemplate": &graphql.Field{
+					Type:        gql.StoreTemplateType,
+					Description: "Save a store's setup as a template for new stores",
+					Args: graphql.FieldConfigArgument{
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"name": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+					},
+					Resolve: resolvers.SaveStoreTemplateResolver,
+				},
+				"deleteStoreTemplate": &graphql.Field{
+					Type:        gql.StoreTemplateType,
+					Description: "Delete a store template",
+					Args: graphql.FieldConfigArgument{
+						"templateId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.DeleteStoreTemplateResolver,
+				},
 				"deleteStore": &graphql.Field{
 					Type:        gql.StoreType,
-					Description: "Delete a store",
+					Description: "Delete a store. Deleted stores can be restored for 30 days",
 					Args: graphql.FieldConfigArgument{
 						"storeId": &graphql.ArgumentConfig{
 							Type: graphql.NewNonNull(graphql.ID),
@@ -265,6 +457,16 @@ func init() {
 					},
 					Resolve: resolvers.DeleteStoreResolver,
 				},
+				"restoreStore": &graphql.Field{
+					Type:        gql.StoreType,
+					Description: "Restore a deleted store",
+					Args: graphql.FieldConfigArgument{
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.RestoreStoreResolver,
+				},
 				"updateStore": &graphql.Field{
 					Type:        gql.StoreType,
 					Description: "Update a store",
@@ -275,9 +477,134 @@ func init() {
 						"name": &graphql.ArgumentConfig{
 							Type: graphql.String,
 						},
+						"address": &graphql.ArgumentConfig{
+							Type: graphql.String,
+						},
+						"latitude": &graphql.ArgumentConfig{
+							Type: graphql.Float,
+						},
+						"longitude": &graphql.ArgumentConfig{
+							Type: graphql.Float,
+						},
+						"geofenceRadius": &graphql.ArgumentConfig{
+							Type:        graphql.Int,
+							Description: "Radius around the store's location in metres",
+						},
+						"mergeDuplicateItems": &graphql.ArgumentConfig{
+							Type:        graphql.Boolean,
+							Description: "Whether adding an item that's already on the trip increases its quantity instead of adding it again",
+						},
 					},
 					Resolve: resolvers.UpdateStoreResolver,
 				},
+				"createStoreCategory": &graphql.Field{
+					Type:        gql.StoreCategoryType,
+					Description: "Add a category to a store",
+					Args: graphql.FieldConfigArgument{
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"name": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+					},
+					Resolve: resolvers.CreateStoreCategoryResolver,
+				},
+				"renameStoreCategory": &graphql.Field{
+					Type:        gql.StoreCategoryType,
+					Description: "Rename a store category",
+					Args: graphql.FieldConfigArgument{
+						"categoryId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"name": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+					},
+					Resolve: resolvers.RenameStoreCategoryResolver,
+				},
+				"deleteStoreCategory": &graphql.Field{
+					Type:        gql.StoreCategoryType,
+					Description: "Delete a store category, moving its items to another category (Misc. by default)",
+					Args: graphql.FieldConfigArgument{
+						"categoryId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"moveItemsToCategoryId": &graphql.ArgumentConfig{
+							Type: graphql.ID,
+						},
+					},
+					Resolve: resolvers.DeleteStoreCategoryResolver,
+				},
+				"reorderStoreCategories": &graphql.Field{
+					Type:        graphql.NewList(gql.StoreCategoryType),
+					Description: "Set the order of a store's categories",
+					Args: graphql.FieldConfigArgument{
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"categoryIds": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID))),
+						},
+					},
+					Resolve: resolvers.ReorderStoreCategoriesResolver,
+				},
+				"createHousehold": &graphql.Field{
+					Type:        gql.HouseholdType,
+					Description: "Create a household to share a group of stores with",
+					Args: graphql.FieldConfigArgument{
+						"name": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+					},
+					Resolve: resolvers.CreateHouseholdResolver,
+				},
+				"inviteToHousehold": &graphql.Field{
+					Type:        gql.HouseholdUserType,
+					Description: "Invite to a household via email",
+					Args: graphql.FieldConfigArgument{
+						"householdId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"email": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+					},
+					Resolve: resolvers.InviteToHouseholdResolver,
+				},
+				"joinHousehold": &graphql.Field{
+					Type:        gql.HouseholdUserType,
+					Description: "Accept a household invitation, joining every store in the household",
+					Args: graphql.FieldConfigArgument{
+						"householdId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.JoinHouseholdResolver,
+				},
+				"leaveHousehold": &graphql.Field{
+					Type:        gql.HouseholdUserType,
+					Description: "Leave (or decline an invitation to) a household",
+					Args: graphql.FieldConfigArgument{
+						"householdId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.LeaveHouseholdResolver,
+				},
+				"setStoreHousehold": &graphql.Field{
+					Type:        gql.StoreType,
+					Description: "Add a store to a household, or remove it from its household if householdId is omitted",
+					Args: graphql.FieldConfigArgument{
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"householdId": &graphql.ArgumentConfig{
+							Type: graphql.ID,
+						},
+					},
+					Resolve: resolvers.SetStoreHouseholdResolver,
+				},
 				"inviteToStore": &graphql.Field{
 					Type:        gql.StoreUserType,
 					Description: "Invite to a store via email",
@@ -341,7 +668,54 @@ func init() {
 						"defaultStore": &graphql.ArgumentConfig{
 							Type: graphql.Boolean,
 						},
+						"suggestions": &graphql.ArgumentConfig{
+							Type:        graphql.Boolean,
+							Description: "Whether to show suggested items from purchase history",
+						},
 						"notifications": &graphql.ArgumentConfig{
+							Type:        graphql.Boolean,
+							Description: "Deprecated: toggles every kind of push notification at once",
+						},
+						"pushItemsAdded": &graphql.ArgumentConfig{
+							Type: graphql.Boolean,
+						},
+						"pushMeals": &graphql.ArgumentConfig{
+							Type: graphql.Boolean,
+						},
+						"pushMemberJoined": &graphql.ArgumentConfig{
+							Type: graphql.Boolean,
+						},
+						"pushStoreChanges": &graphql.ArgumentConfig{
+							Type: graphql.Boolean,
+						},
+						"pushTripReminders": &graphql.ArgumentConfig{
+							Type: graphql.Boolean,
+						},
+						"pushBudgetAlerts": &graphql.ArgumentConfig{
+							Type: graphql.Boolean,
+						},
+						"pushItemAssigned": &graphql.ArgumentConfig{
+							Type: graphql.Boolean,
+						},
+						"emailItemsAdded": &graphql.ArgumentConfig{
+							Type: graphql.Boolean,
+						},
+						"emailMeals": &graphql.ArgumentConfig{
+							Type: graphql.Boolean,
+						},
+						"emailMemberJoined": &graphql.ArgumentConfig{
+							Type: graphql.Boolean,
+						},
+						"emailStoreChanges": &graphql.ArgumentConfig{
+							Type: graphql.Boolean,
+						},
+						"emailTripReminders": &graphql.ArgumentConfig{
+							Type: graphql.Boolean,
+						},
+						"emailBudgetAlerts": &graphql.ArgumentConfig{
+							Type: graphql.Boolean,
+						},
+						"emailItemAssigned": &graphql.ArgumentConfig{
 							Type: graphql.Boolean,
 						},
 					},
@@ -366,9 +740,84 @@ func init() {
 						"newTripName": &graphql.ArgumentConfig{
 							Type: graphql.String,
 						},
+						"shoppingDate": &graphql.ArgumentConfig{
+							Type:        graphql.String,
+							Description: "The date the trip is planned for, in the format YYYY-MM-DD (blank to clear)",
+						},
+						"newTripShoppingDate": &graphql.ArgumentConfig{
+							Type:        graphql.String,
+							Description: "The date the next trip is planned for when completing a trip, in the format YYYY-MM-DD",
+						},
 					},
 					Resolve: resolvers.UpdateTripResolver,
 				},
+				"reopenTrip": &graphql.Field{
+					Type:        gql.GroceryTripType,
+					Description: "Reopen a completed trip, undoing the items carried over to the next trip (which is removed if it hasn't been touched)",
+					Args: graphql.FieldConfigArgument{
+						"tripId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.ReopenTripResolver,
+				},
+				"setStoreSchedule": &graphql.Field{
+					Type:        gql.StoreScheduleType,
+					Description: "Sets a weekly shopping day for a store, which plans and rolls over its trips automatically",
+					Args: graphql.FieldConfigArgument{
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"weekday": &graphql.ArgumentConfig{
+							Type:        graphql.NewNonNull(graphql.Int),
+							Description: "The day of the week to shop on, where 0 is Sunday",
+						},
+						"timezone": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+						"copyRemainingItems": &graphql.ArgumentConfig{
+							Type: graphql.Boolean,
+						},
+					},
+					Resolve: resolvers.SetStoreScheduleResolver,
+				},
+				"deleteStoreSchedule": &graphql.Field{
+					Type:        gql.StoreScheduleType,
+					Description: "Removes the weekly shopping day for a store",
+					Args: graphql.FieldConfigArgument{
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.DeleteStoreScheduleResolver,
+				},
+				"setStoreBudget": &graphql.Field{
+					Type:        gql.StoreBudgetType,
+					Description: "Sets the amount the members of a store plan to spend there each month",
+					Args: graphql.FieldConfigArgument{
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"monthlyAmount": &graphql.ArgumentConfig{
+							Type:        graphql.NewNonNull(graphql.Int),
+							Description: "The monthly budget in the minor unit of the currency (i.e. cents)",
+						},
+						"currency": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+					},
+					Resolve: resolvers.SetStoreBudgetResolver,
+				},
+				"deleteStoreBudget": &graphql.Field{
+					Type:        gql.StoreBudgetType,
+					Description: "Removes the monthly budget for a store",
+					Args: graphql.FieldConfigArgument{
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.DeleteStoreBudgetResolver,
+				},
 				// Items
 				"deleteItem": &graphql.Field{
 					Type:        gql.ItemType,
@@ -380,6 +829,92 @@ func init() {
 					},
 					Resolve: resolvers.DeleteItemResolver,
 				},
+				"restoreItem": &graphql.Field{
+					Type:        gql.ItemType,
+					Description: "Restore a recently deleted item to its trip",
+					Args: graphql.FieldConfigArgument{
+						"itemId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.RestoreItemResolver,
+				},
+				"completeItems": &graphql.Field{
+					Type:        graphql.NewList(gql.ItemType),
+					Description: "Mark items as completed (or not completed) at once",
+					Args: graphql.FieldConfigArgument{
+						"itemIds": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID))),
+						},
+						"completed": &graphql.ArgumentConfig{
+							Type:        graphql.Boolean,
+							Description: "Whether to mark the items as completed (the default) or not completed",
+						},
+					},
+					Resolve: resolvers.CompleteItemsResolver,
+				},
+				"deleteItems": &graphql.Field{
+					Type:        graphql.NewList(gql.ItemType),
+					Description: "Remove items from their trips at once",
+					Args: graphql.FieldConfigArgument{
+						"itemIds": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID))),
+						},
+					},
+					Resolve: resolvers.DeleteItemsResolver,
+				},
+				"moveItems": &graphql.Field{
+					Type:        graphql.NewList(gql.ItemType),
+					Description: "Move items to another trip in the same store at once. The trip can't be completed",
+					Args: graphql.FieldConfigArgument{
+						"itemIds": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID))),
+						},
+						"toTripId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.MoveItemsResolver,
+				},
+				"setItemsCategory": &graphql.Field{
+					Type:        graphql.NewList(gql.ItemType),
+					Description: "Move items to a category of their store at once",
+					Args: graphql.FieldConfigArgument{
+						"itemIds": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID))),
+						},
+						"storeCategoryId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.SetItemsCategoryResolver,
+				},
+				"moveItemToStore": &graphql.Field{
+					Type:        gql.ItemType,
+					Description: "Move an item to the current trip in another store",
+					Args: graphql.FieldConfigArgument{
+						"itemId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.MoveItemToStoreResolver,
+				},
+				"copyItemToStore": &graphql.Field{
+					Type:        gql.ItemType,
+					Description: "Copy an item to the current trip in another store",
+					Args: graphql.FieldConfigArgument{
+						"itemId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.CopyItemToStoreResolver,
+				},
 				"updateItem": &graphql.Field{
 					Type:        gql.ItemType,
 					Description: "Updates the properties of an item",
@@ -408,6 +943,20 @@ func init() {
 						"position": &graphql.ArgumentConfig{
 							Type: graphql.Int,
 						},
+						"decimalQuantity": &graphql.ArgumentConfig{
+							Type: graphql.Float,
+						},
+						"unit": &graphql.ArgumentConfig{
+							Type:        graphql.String,
+							Description: "The unit of decimalQuantity (i.e. kg, lbs, grams). Blank clears it",
+						},
+						"unitPrice": &graphql.ArgumentConfig{
+							Type:        graphql.Int,
+							Description: "The price of a single unit of the item in the minor unit of its currency (i.e. cents)",
+						},
+						"currency": &graphql.ArgumentConfig{
+							Type: graphql.String,
+						},
 					},
 					Resolve: resolvers.UpdateItemResolver,
 				},
@@ -429,11 +978,65 @@ func init() {
 							Type: graphql.NewNonNull(graphql.ID),
 						},
 						"position": &graphql.ArgumentConfig{
-							Type: graphql.NewNonNull(graphql.Int),
+							Type:        graphql.NewNonNull(graphql.Int),
+							Description: "The new position (from 1) of the item in its category",
 						},
 					},
 					Resolve: resolvers.ReorderItemResolver,
 				},
+				"addItemByBarcode": &graphql.Field{
+					Type:        gql.ItemType,
+					Description: "Add the product with a UPC/EAN barcode to a grocery trip",
+					Args: graphql.FieldConfigArgument{
+						"tripId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"code": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+					},
+					Resolve: resolvers.AddItemByBarcodeResolver,
+				},
+				"assignItem": &graphql.Field{
+					Type:        gql.ItemType,
+					Description: "Assign an item to a store member (or unassign it when userId is omitted)",
+					Args: graphql.FieldConfigArgument{
+						"itemId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"userId": &graphql.ArgumentConfig{
+							Type: graphql.ID,
+						},
+					},
+					Resolve: resolvers.AssignItemResolver,
+				},
+				"attachItemPhoto": &graphql.Field{
+					Type:        gql.ItemType,
+					Description: "Attach a photo to an item, replacing its existing photo",
+					Args: graphql.FieldConfigArgument{
+						"itemId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"data": &graphql.ArgumentConfig{
+							Type:        graphql.NewNonNull(graphql.String),
+							Description: "Base64 encoded JPEG, PNG or GIF image of up to 10 MB",
+						},
+						"contentType": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+					},
+					Resolve: resolvers.AttachItemPhotoResolver,
+				},
+				"removeItemPhoto": &graphql.Field{
+					Type:        gql.ItemType,
+					Description: "Remove the photo of an item",
+					Args: graphql.FieldConfigArgument{
+						"itemId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.RemoveItemPhotoResolver,
+				},
 				"addItemToTrip": &graphql.Field{
 					Type:        gql.ItemType,
 					Description: "Add an item to a grocery trip",
@@ -450,6 +1053,20 @@ func init() {
 						"categoryName": &graphql.ArgumentConfig{
 							Type: graphql.String,
 						},
+						"decimalQuantity": &graphql.ArgumentConfig{
+							Type: graphql.Float,
+						},
+						"unit": &graphql.ArgumentConfig{
+							Type:        graphql.String,
+							Description: "The unit of decimalQuantity (i.e. kg, lbs, grams). Blank clears it",
+						},
+						"unitPrice": &graphql.ArgumentConfig{
+							Type:        graphql.Int,
+							Description: "The price of a single unit of the item in the minor unit of its currency (i.e. cents)",
+						},
+						"currency": &graphql.ArgumentConfig{
+							Type: graphql.String,
+						},
 					},
 					Resolve: resolvers.AddItemToTrip,
 				},
@@ -481,6 +1098,71 @@ func init() {
 					},
 					Resolve: resolvers.SaveStapleItem,
 				},
+				"dismissSuggestedItem": &graphql.Field{
+					Type:        graphql.Boolean,
+					Description: "Hides a suggested item from the current user until it's bought again",
+					Args: graphql.FieldConfigArgument{
+						"storeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"name": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+					},
+					Resolve: resolvers.DismissSuggestedItemResolver,
+				},
+				"updateStapleItem": &graphql.Field{
+					Type:        gql.StoreStapleItemType,
+					Description: "Updates the name, amount, notes and pinned category of a staple item",
+					Args: graphql.FieldConfigArgument{
+						"stapleItemId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"name": &graphql.ArgumentConfig{
+							Type: graphql.String,
+						},
+						"quantity": &graphql.ArgumentConfig{
+							Type: graphql.Int,
+						},
+						"decimalQuantity": &graphql.ArgumentConfig{
+							Type: graphql.Float,
+						},
+						"unit": &graphql.ArgumentConfig{
+							Type:        graphql.String,
+							Description: "One of lb, oz, kg, g, l or ml, or an empty string to clear it",
+						},
+						"notes": &graphql.ArgumentConfig{
+							Type:        graphql.String,
+							Description: "An empty string clears the notes",
+						},
+						"storeCategoryId": &graphql.ArgumentConfig{
+							Type:        graphql.String,
+							Description: "The category to pin the staple to, or an empty string to unpin it",
+						},
+					},
+					Resolve: resolvers.UpdateStapleItemResolver,
+				},
+				"setStapleItemCadence": &graphql.Field{
+					Type:        gql.StoreStapleItemType,
+					Description: "Sets how often a staple item is added to new trips",
+					Args: graphql.FieldConfigArgument{
+						"stapleItemId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"cadence": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(gql.StapleCadenceEnum),
+						},
+						"interval": &graphql.ArgumentConfig{
+							Type:        graphql.Int,
+							Description: "The N of every N trips or days",
+						},
+						"weekdays": &graphql.ArgumentConfig{
+							Type:        graphql.NewList(graphql.NewNonNull(graphql.Int)),
+							Description: "The days of the week (0 is Sunday) for the weekdays cadence",
+						},
+					},
+					Resolve: resolvers.SetStapleItemCadenceResolver,
+				},
 				"removeStapleItem": &graphql.Field{
 					Type:        gql.StoreStapleItemType,
 					Description: "Unmarks an item as a staple in a store",
@@ -563,6 +1245,33 @@ func init() {
 					},
 					Resolve: resolvers.DeleteRecipeResolver,
 				},
+				"uploadRecipeImage": &graphql.Field{
+					Type:        gql.RecipeType,
+					Description: "Upload an image for a recipe, replacing its existing image",
+					Args: graphql.FieldConfigArgument{
+						"recipeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+						"data": &graphql.ArgumentConfig{
+							Type:        graphql.NewNonNull(graphql.String),
+							Description: "Base64 encoded JPEG, PNG or GIF image of up to 10 MB",
+						},
+						"contentType": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+					},
+					Resolve: resolvers.UploadRecipeImageResolver,
+				},
+				"removeRecipeImage": &graphql.Field{
+					Type:        gql.RecipeType,
+					Description: "Remove the uploaded image of a recipe",
+					Args: graphql.FieldConfigArgument{
+						"recipeId": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.ID),
+						},
+					},
+					Resolve: resolvers.RemoveRecipeImageResolver,
+				},
 				"planMeal": &graphql.Field{
 					Type:        gql.MealType,
 					Description: "Creates a planned meal of a recipe",
@@ -652,6 +1361,30 @@ func init() {
 					},
 					Resolve: resolvers.AddDeviceResolver,
 				},
+				"setQuietHours": &graphql.Field{
+					Type:        gql.QuietHoursType,
+					Description: "Sets the current user's quiet hours, during which notifications are held or dropped",
+					Args: graphql.FieldConfigArgument{
+						"start": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+						"end": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+						"timezone": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(graphql.String),
+						},
+						"holdNotifications": &graphql.ArgumentConfig{
+							Type: graphql.Boolean,
+						},
+					},
+					Resolve: resolvers.SetQuietHoursResolver,
+				},
+				"deleteQuietHours": &graphql.Field{
+					Type:        gql.QuietHoursType,
+					Description: "Removes the current user's quiet hours",
+					Resolve:     resolvers.DeleteQuietHoursResolver,
+				},
 				"notifyTripUpdatedItemsAdded": &graphql.Field{
 					Type:        graphql.Boolean,
 					Description: "Notifies store users about a trip being updated after items were added by the current user",
@@ -16,7 +16,11 @@ func AddDeviceResolver(p graphql.ResolveParams) (interface{}, error) {
 
 	token := p.Args["token"].(string)
 	userID := user.ID
-	device, err := notifications.StoreDeviceToken(token, userID)
+	appScheme := ""
+	if scheme := p.Info.RootValue.(map[string]interface{})["App-Scheme"]; scheme != nil {
+		appScheme = scheme.(string)
+	}
+	device, err := notifications.StoreDeviceToken(token, userID, appScheme)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,34 @@
+package resolvers
+
+import (
+	"errors"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/trips"
+	"github.com/graphql-go/graphql"
+	uuid "github.com/satori/go.uuid"
+)
+
+// AddItemByBarcodeResolver resolves the addItemByBarcode mutation
+func AddItemByBarcodeResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	tripID, err := uuid.FromString(p.Args["tripId"].(string))
+	if err != nil {
+		return nil, errors.New("tripId arg not a UUID")
+	}
+	args := map[string]interface{}{
+		"tripId": tripID,
+		"code":   p.Args["code"],
+		"locale": p.Info.RootValue.(map[string]interface{})["Accept-Language"],
+	}
+	item, err := trips.AddItemByBarcode(user.ID, args)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
@@ -15,6 +15,8 @@ func AddItemsToStore(p graphql.ResolveParams) (interface{}, error) {
 	}
 
 	userID := user.ID
+	// Item names are parsed in the language the app is using
+	p.Args["locale"] = p.Info.RootValue.(map[string]interface{})["Accept-Language"]
 	items, err := trips.AddItemsToStore(userID, p.Args)
 	if err != nil {
 		return nil, err
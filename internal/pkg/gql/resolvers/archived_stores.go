@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
+	"github.com/graphql-go/graphql"
+)
+
+// ArchivedStoresResolver returns the archived stores that the current user can still restore
+func ArchivedStoresResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	archivedStores, err := stores.RetrieveArchivedUserStores(user)
+	if err != nil {
+		return nil, err
+	}
+	return archivedStores, nil
+}
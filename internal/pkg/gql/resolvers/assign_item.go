@@ -0,0 +1,29 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/notifications"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/trips"
+	"github.com/graphql-go/graphql"
+)
+
+// AssignItemResolver resolves the assignItem mutation, notifying the
+// assignee about the item
+func AssignItemResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := trips.AssignItem(user.ID, p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	appScheme := p.Info.RootValue.(map[string]interface{})["App-Scheme"]
+	if appScheme != nil && item.AssigneeID != nil {
+		go notifications.ItemAssigned(user, item, appScheme.(string))
+	}
+	return item, nil
+}
@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/photos"
+	"github.com/graphql-go/graphql"
+)
+
+// AttachItemPhotoResolver resolves the attachItemPhoto mutation
+func AttachItemPhotoResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := photos.AttachItemPhoto(user.ID, p.Args["itemId"], p.Args["data"].(string), p.Args["contentType"].(string))
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/trips"
+	"github.com/graphql-go/graphql"
+)
+
+// CompleteItemsResolver marks the items by itemIds param as completed (or not completed)
+func CompleteItemsResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := trips.CompleteItems(user.ID, p.Args)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
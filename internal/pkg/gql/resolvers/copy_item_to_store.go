@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/trips"
+	"github.com/graphql-go/graphql"
+)
+
+// CopyItemToStoreResolver copys an item to the current trip in another store
+func CopyItemToStoreResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := trips.CopyItemToStore(user.ID, p.Args)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/households"
+	"github.com/graphql-go/graphql"
+)
+
+// CreateHouseholdResolver resolves the createHousehold mutation
+func CreateHouseholdResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	household, err := households.CreateHousehold(user.ID, p.Args["name"].(string))
+	if err != nil {
+		return nil, err
+	}
+	return household, nil
+}
@@ -2,6 +2,7 @@ package resolvers
 
 import (
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
 	"github.com/graphql-go/graphql"
 )
@@ -15,7 +16,13 @@ func CreateStoreResolver(p graphql.ResolveParams) (interface{}, error) {
 	}
 
 	userID := user.ID
-	store, err := stores.CreateStore(userID, p.Args["name"].(string))
+	name := p.Args["name"].(string)
+	var store models.Store
+	if templateID := p.Args["templateId"]; templateID != nil {
+		store, err = stores.CreateStoreFromTemplate(userID, name, templateID)
+	} else {
+		store, err = stores.CreateStore(userID, name)
+	}
 	if err != nil {
 		return nil, err
 	}
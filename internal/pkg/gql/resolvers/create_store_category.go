@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
+	"github.com/graphql-go/graphql"
+)
+
+// CreateStoreCategoryResolver resolves the createStoreCategory mutation by adding a category to a store
+func CreateStoreCategoryResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := stores.CreateStoreCategory(p.Args["storeId"], user.ID, p.Args["name"].(string))
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
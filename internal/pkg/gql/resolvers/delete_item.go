@@ -9,13 +9,13 @@ import (
 // DeleteItemResolver deletes an item by itemId param
 func DeleteItemResolver(p graphql.ResolveParams) (interface{}, error) {
 	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
-	_, err := auth.FetchAuthenticatedUser(header.(string))
+	user, err := auth.FetchAuthenticatedUser(header.(string))
 	if err != nil {
 		return nil, err
 	}
 
 	itemID := p.Args["itemId"]
-	item, err := trips.DeleteItem(itemID)
+	item, err := trips.DeleteItem(user.ID, itemID)
 	if err != nil {
 		return nil, err
 	}
@@ -2,12 +2,13 @@ package resolvers
 
 import (
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/notifications"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
 	"github.com/graphql-go/graphql"
 )
 
-// DeleteStoreResolver resolves the deleteStore mutation by deleting a store
-// and its associated store users and items
+// DeleteStoreResolver resolves the deleteStore mutation by archiving a store.
+// The store can be restored with the restoreStore mutation until it is purged
 func DeleteStoreResolver(p graphql.ResolveParams) (interface{}, error) {
 	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
 	user, err := auth.FetchAuthenticatedUser(header.(string))
@@ -16,9 +17,14 @@ func DeleteStoreResolver(p graphql.ResolveParams) (interface{}, error) {
 	}
 
 	storeID := p.Args["storeId"]
-	store, err := stores.DeleteStore(storeID, user.ID)
+	store, err := stores.ArchiveStore(storeID, user.ID)
 	if err != nil {
 		return nil, err
 	}
+
+	appScheme := p.Info.RootValue.(map[string]interface{})["App-Scheme"]
+	if appScheme != nil {
+		go notifications.StoreArchived(user, store, appScheme.(string))
+	}
 	return store, nil
 }
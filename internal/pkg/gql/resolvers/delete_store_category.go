@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
+	"github.com/graphql-go/graphql"
+)
+
+// DeleteStoreCategoryResolver resolves the deleteStoreCategory mutation, moving the category's items to another category
+func DeleteStoreCategoryResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := stores.DeleteStoreCategory(p.Args["categoryId"], user.ID, p.Args["moveItemsToCategoryId"])
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/trips"
+	"github.com/graphql-go/graphql"
+)
+
+// DeleteStoreScheduleResolver resolves the deleteStoreSchedule mutation
+func DeleteStoreScheduleResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := trips.DeleteStoreSchedule(p.Args["storeId"], user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
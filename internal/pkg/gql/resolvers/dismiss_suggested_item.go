@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/suggestions"
+	"github.com/graphql-go/graphql"
+)
+
+// DismissSuggestedItemResolver resolves the dismissSuggestedItem mutation
+func DismissSuggestedItemResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	name := p.Args["name"].(string)
+	if _, err := suggestions.DismissSuggestedItem(user.ID, p.Args["storeId"], name); err != nil {
+		return false, err
+	}
+	return true, nil
+}
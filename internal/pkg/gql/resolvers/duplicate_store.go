@@ -0,0 +1,29 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
+	"github.com/graphql-go/graphql"
+)
+
+// DuplicateStoreResolver resolves the duplicateStore mutation by creating a
+// copy of a store for the currently authenticated user
+func DuplicateStoreResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	var include []string
+	if p.Args["include"] != nil {
+		for _, value := range p.Args["include"].([]interface{}) {
+			include = append(include, value.(string))
+		}
+	}
+	store, err := stores.DuplicateStore(p.Args["storeId"], user.ID, p.Args["name"].(string), include)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/households"
+	"github.com/graphql-go/graphql"
+)
+
+// HouseholdsResolver resolves the households query
+func HouseholdsResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	userHouseholds, err := households.RetrieveUserHouseholds(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return userHouseholds, nil
+}
@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/households"
+	"github.com/graphql-go/graphql"
+)
+
+// InviteToHouseholdResolver resolves the inviteToHousehold mutation
+func InviteToHouseholdResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	householdUser, err := households.InviteToHousehold(p.Args["householdId"], user.ID, p.Args["email"].(string))
+	if err != nil {
+		return nil, err
+	}
+	return householdUser, nil
+}
@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/households"
+	"github.com/graphql-go/graphql"
+)
+
+// InvitedHouseholdsResolver resolves the invitedHouseholds query
+func InvitedHouseholdsResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	invitedHouseholds, err := households.RetrieveInvitedUserHouseholds(user)
+	if err != nil {
+		return nil, err
+	}
+	return invitedHouseholds, nil
+}
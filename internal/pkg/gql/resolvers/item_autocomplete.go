@@ -0,0 +1,27 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/autocomplete"
+	"github.com/graphql-go/graphql"
+)
+
+// ItemAutocompleteResolver resolves the itemAutocomplete query
+func ItemAutocompleteResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	limit := 0
+	if p.Args["limit"] != nil {
+		limit = p.Args["limit"].(int)
+	}
+	prefix := p.Args["prefix"].(string)
+	completions, err := autocomplete.Complete(user.ID, p.Args["storeId"], prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	return completions, nil
+}
@@ -6,7 +6,8 @@ import (
 	"github.com/graphql-go/graphql"
 )
 
-// ItemSearchResolver resolves the itemSearch query
+// ItemSearchResolver resolves the itemSearch query, which returns the best
+// match of an item search (for older app versions)
 func ItemSearchResolver(p graphql.ResolveParams) (interface{}, error) {
 	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
 	user, err := auth.FetchAuthenticatedUser(header.(string))
@@ -14,11 +15,13 @@ func ItemSearchResolver(p graphql.ResolveParams) (interface{}, error) {
 		return nil, err
 	}
 
-	name := p.Args["name"].(string)
-	userID := user.ID
-	item, err := trips.SearchForItemByName(name, userID)
+	args := map[string]interface{}{"query": p.Args["name"], "limit": 1}
+	results, err := trips.SearchItems(user.ID, args)
 	if err != nil {
 		return nil, err
 	}
-	return item, err
+	if len(results.Stores) == 0 {
+		return nil, nil
+	}
+	return results.Stores[0].Trips[0].Hits[0].Item, nil
 }
@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/households"
+	"github.com/graphql-go/graphql"
+)
+
+// LeaveHouseholdResolver resolves the leaveHousehold mutation
+func LeaveHouseholdResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	householdUser, err := households.LeaveHousehold(p.Args["householdId"], user)
+	if err != nil {
+		return nil, err
+	}
+	return householdUser, nil
+}
@@ -0,0 +1,25 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
+	"github.com/graphql-go/graphql"
+)
+
+// NearbyStoresResolver resolves the nearbyStores query by sorting the current
+// user's stores by their distance from the location provided
+func NearbyStoresResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	lat := p.Args["lat"].(float64)
+	lng := p.Args["lng"].(float64)
+	nearbyStores, err := stores.RetrieveNearbyUserStores(user, lat, lng)
+	if err != nil {
+		return nil, err
+	}
+	return nearbyStores, nil
+}
@@ -0,0 +1,25 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/notifications"
+	"github.com/graphql-go/graphql"
+)
+
+// QuietHoursResolver resolves the quietHours query
+func QuietHoursResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	quietHours, err := notifications.RetrieveQuietHours(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if quietHours == nil {
+		return nil, nil
+	}
+	return *quietHours, nil
+}
@@ -0,0 +1,23 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/trips"
+	"github.com/graphql-go/graphql"
+)
+
+// RecentlyDeletedItemsResolver retrieves the items deleted from a trip that
+// can still be restored
+func RecentlyDeletedItemsResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := trips.RetrieveRecentlyDeletedItems(user.ID, p.Args["tripId"])
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/photos"
+	"github.com/graphql-go/graphql"
+)
+
+// RemoveItemPhotoResolver resolves the removeItemPhoto mutation
+func RemoveItemPhotoResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := photos.RemoveItemPhoto(user.ID, p.Args["itemId"])
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/photos"
+	"github.com/graphql-go/graphql"
+)
+
+// RemoveRecipeImageResolver resolves the removeRecipeImage mutation
+func RemoveRecipeImageResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	recipe, err := photos.RemoveRecipeImage(user.ID, p.Args["recipeId"])
+	if err != nil {
+		return nil, err
+	}
+	return recipe, nil
+}
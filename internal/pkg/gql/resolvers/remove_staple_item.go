@@ -10,7 +10,7 @@ import (
 // RemoveStapleItem resolves the removeStapleItem mutation
 func RemoveStapleItem(p graphql.ResolveParams) (interface{}, error) {
 	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
-	_, err := auth.FetchAuthenticatedUser(header.(string))
+	user, err := auth.FetchAuthenticatedUser(header.(string))
 	if err != nil {
 		return nil, err
 	}
@@ -19,7 +19,7 @@ func RemoveStapleItem(p graphql.ResolveParams) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	item, err := stores.RemoveStapleItem(itemID)
+	item, err := stores.RemoveStapleItem(user.ID, itemID)
 	if err != nil {
 		return nil, err
 	}
@@ -9,14 +9,14 @@ import (
 // ReorderItemResolver updates the position of an item with the provided params
 func ReorderItemResolver(p graphql.ResolveParams) (interface{}, error) {
 	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
-	_, err := auth.FetchAuthenticatedUser(header.(string))
+	user, err := auth.FetchAuthenticatedUser(header.(string))
 	if err != nil {
 		return nil, err
 	}
 
 	itemID := p.Args["itemId"]
 	position := p.Args["position"].(int)
-	trip, err := trips.ReorderItem(itemID, position)
+	trip, err := trips.ReorderItem(user.ID, itemID, position)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
+	"github.com/graphql-go/graphql"
+)
+
+// ReorderStoreCategoriesResolver resolves the reorderStoreCategories mutation
+func ReorderStoreCategoriesResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := stores.ReorderStoreCategories(p.Args["storeId"], user.ID, p.Args["categoryIds"].([]interface{}))
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
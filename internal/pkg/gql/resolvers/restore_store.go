@@ -0,0 +1,29 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/notifications"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
+	"github.com/graphql-go/graphql"
+)
+
+// RestoreStoreResolver resolves the restoreStore mutation by restoring an archived store
+func RestoreStoreResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	storeID := p.Args["storeId"]
+	store, err := stores.RestoreStore(storeID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	appScheme := p.Info.RootValue.(map[string]interface{})["App-Scheme"]
+	if appScheme != nil {
+		go notifications.StoreRestored(user, store, appScheme.(string))
+	}
+	return store, nil
+}
@@ -10,7 +10,7 @@ import (
 // SaveStapleItem resolves the saveStapleItem mutation
 func SaveStapleItem(p graphql.ResolveParams) (interface{}, error) {
 	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
-	_, err := auth.FetchAuthenticatedUser(header.(string))
+	user, err := auth.FetchAuthenticatedUser(header.(string))
 	if err != nil {
 		return nil, err
 	}
@@ -23,7 +23,7 @@ func SaveStapleItem(p graphql.ResolveParams) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	item, err := stores.SaveStapleItem(storeID, itemID)
+	item, err := stores.SaveStapleItem(user.ID, storeID, itemID)
 	if err != nil {
 		return nil, err
 	}
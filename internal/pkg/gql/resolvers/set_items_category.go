@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/trips"
+	"github.com/graphql-go/graphql"
+)
+
+// SetItemsCategoryResolver moves the items by itemIds param to the store category by storeCategoryId param
+func SetItemsCategoryResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := trips.SetItemsCategory(user.ID, p.Args)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/notifications"
+	"github.com/graphql-go/graphql"
+)
+
+// SetQuietHoursResolver resolves the setQuietHours mutation
+func SetQuietHoursResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	quietHours, err := notifications.SetQuietHours(user.ID, p.Args)
+	if err != nil {
+		return nil, err
+	}
+	return quietHours, nil
+}
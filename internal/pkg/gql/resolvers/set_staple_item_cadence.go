@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
+	"github.com/graphql-go/graphql"
+)
+
+// SetStapleItemCadenceResolver resolves the setStapleItemCadence mutation
+func SetStapleItemCadenceResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	staple, err := stores.SetStapleItemCadence(user.ID, p.Args)
+	if err != nil {
+		return nil, err
+	}
+	return staple, nil
+}
@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
+	"github.com/graphql-go/graphql"
+)
+
+// SetStoreBudgetResolver resolves the setStoreBudget mutation
+func SetStoreBudgetResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	budget, err := stores.SetStoreBudget(p.Args["storeId"], user.ID, p.Args)
+	if err != nil {
+		return nil, err
+	}
+	return budget, nil
+}
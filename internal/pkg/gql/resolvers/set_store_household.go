@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/households"
+	"github.com/graphql-go/graphql"
+)
+
+// SetStoreHouseholdResolver resolves the setStoreHousehold mutation by adding a store to (or removing it from) a household
+func SetStoreHouseholdResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := households.SetStoreHousehold(p.Args["storeId"], user.ID, p.Args["householdId"])
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
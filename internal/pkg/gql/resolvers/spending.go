@@ -0,0 +1,23 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
+	"github.com/graphql-go/graphql"
+)
+
+// SpendingResolver resolves the spending query by retrieving what was spent
+// at a store in each category between two dates
+func SpendingResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	spending, err := stores.RetrieveSpending(p.Args["storeId"], user.ID, p.Args["from"].(string), p.Args["to"].(string))
+	if err != nil {
+		return nil, err
+	}
+	return spending, nil
+}
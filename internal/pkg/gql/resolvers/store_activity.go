@@ -0,0 +1,31 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/graphql-go/graphql"
+)
+
+// StoreActivityResolver resolves the storeActivity query by retrieving a page
+// of a store's activity log
+func StoreActivityResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	limit := activity.DefaultLimit
+	if p.Args["limit"] != nil {
+		limit = p.Args["limit"].(int)
+	}
+	offset := 0
+	if p.Args["offset"] != nil {
+		offset = p.Args["offset"].(int)
+	}
+	entries, err := activity.RetrieveStoreActivity(p.Args["storeId"], user.ID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
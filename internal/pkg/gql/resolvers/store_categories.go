@@ -21,6 +21,7 @@ func StoreCategoriesResolver(p graphql.ResolveParams) (interface{}, error) {
 		Joins("INNER JOIN store_users ON store_users.store_id = store_categories.store_id").
 		Where("store_categories.store_id = ?", storeID).
 		Where("store_users.user_id = ?", user.ID).
+		Order("store_categories.position ASC").
 		Find(&storeCategories).
 		Error
 	if err := query; err != nil {
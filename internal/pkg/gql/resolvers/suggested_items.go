@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/suggestions"
+	"github.com/graphql-go/graphql"
+)
+
+// SuggestedItemsResolver resolves the suggestedItems query
+func SuggestedItemsResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	suggested, err := suggestions.RetrieveSuggestedItems(user.ID, p.Args["storeId"])
+	if err != nil {
+		return nil, err
+	}
+	return suggested, nil
+}
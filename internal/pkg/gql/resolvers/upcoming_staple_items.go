@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
+	"github.com/graphql-go/graphql"
+)
+
+// UpcomingStapleItemsResolver resolves the upcomingStapleItems query
+func UpcomingStapleItemsResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	upcoming, err := stores.RetrieveUpcomingStapleItems(user.ID, p.Args["storeId"])
+	if err != nil {
+		return nil, err
+	}
+	return upcoming, nil
+}
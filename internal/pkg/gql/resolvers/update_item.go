@@ -9,12 +9,14 @@ import (
 // UpdateItemResolver updates the properties of an item with the provided params
 func UpdateItemResolver(p graphql.ResolveParams) (interface{}, error) {
 	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
-	_, err := auth.FetchAuthenticatedUser(header.(string))
+	user, err := auth.FetchAuthenticatedUser(header.(string))
 	if err != nil {
 		return nil, err
 	}
 
-	item, err := trips.UpdateItem(p.Args)
+	// Item names are parsed in the language the app is using
+	p.Args["locale"] = p.Info.RootValue.(map[string]interface{})["Accept-Language"]
+	item, err := trips.UpdateItem(user.ID, p.Args)
 	if err != nil {
 		return nil, err
 	}
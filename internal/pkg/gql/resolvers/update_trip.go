@@ -9,12 +9,12 @@ import (
 // UpdateTripResolver updates the properties of a trip with the provided params
 func UpdateTripResolver(p graphql.ResolveParams) (interface{}, error) {
 	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
-	_, err := auth.FetchAuthenticatedUser(header.(string))
+	user, err := auth.FetchAuthenticatedUser(header.(string))
 	if err != nil {
 		return nil, err
 	}
 
-	item, err := trips.UpdateTrip(p.Args)
+	item, err := trips.UpdateTrip(user.ID, p.Args)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,22 @@
+package resolvers
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/photos"
+	"github.com/graphql-go/graphql"
+)
+
+// UploadRecipeImageResolver resolves the uploadRecipeImage mutation
+func UploadRecipeImageResolver(p graphql.ResolveParams) (interface{}, error) {
+	header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+	user, err := auth.FetchAuthenticatedUser(header.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	recipe, err := photos.AttachRecipeImage(user.ID, p.Args["recipeId"], p.Args["data"].(string), p.Args["contentType"].(string))
+	if err != nil {
+		return nil, err
+	}
+	return recipe, nil
+}
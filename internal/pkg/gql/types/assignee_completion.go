@@ -0,0 +1,41 @@
+package gql
+
+import (
+	"errors"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/graphql-go/graphql"
+	"gorm.io/gorm"
+)
+
+// AssigneeCompletionType defines a graphql type for AssigneeCompletion
+var AssigneeCompletionType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "AssigneeCompletion",
+		Fields: graphql.Fields{
+			"userId": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"user": &graphql.Field{
+				Type: UserType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					userID := p.Source.(models.AssigneeCompletion).UserID
+					var user models.User
+					if err := db.Manager.Where("id = ?", userID).First(&user).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+						return nil, err
+					}
+					return user, nil
+				},
+			},
+			"assigned": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "The number of items in the trip assigned to the user",
+			},
+			"completed": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "The number of items assigned to the user that they completed",
+			},
+		},
+	},
+)
@@ -3,6 +3,7 @@ package gql
 import (
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/trips"
 	"github.com/graphql-go/graphql"
 )
 
@@ -33,6 +34,32 @@ var GroceryTripType = graphql.NewObject(
 			"completed": &graphql.Field{
 				Type: graphql.NewNonNull(graphql.Boolean),
 			},
+			"shoppingDate": &graphql.Field{
+				Type:        graphql.String,
+				Description: "The date the trip is planned for, in the format YYYY-MM-DD",
+			},
+			"totals": &graphql.Field{
+				Type:        graphql.NewList(TripTotalType),
+				Description: "The estimated and actual totals of the trip for each currency its items are priced in",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					totals, err := trips.RetrieveTripTotals(p.Source.(models.GroceryTrip))
+					if err != nil {
+						return nil, err
+					}
+					return totals, nil
+				},
+			},
+			"assigneeCompletion": &graphql.Field{
+				Type:        graphql.NewList(AssigneeCompletionType),
+				Description: "How many of the items assigned to each store member were completed (recorded when the trip was completed)",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					completion, err := trips.RetrieveAssigneeCompletion(p.Source.(models.GroceryTrip))
+					if err != nil {
+						return nil, err
+					}
+					return completion, nil
+				},
+			},
 			"createdAt": &graphql.Field{
 				Type: graphql.DateTime,
 			},
@@ -47,7 +74,7 @@ var GroceryTripType = graphql.NewObject(
 					query := db.Manager.
 						Joins("INNER JOIN store_categories ON store_categories.id = grocery_trip_categories.store_category_id").
 						Where("grocery_trip_categories.grocery_trip_id = ?", tripID).
-						Order("grocery_trip_categories.created_at DESC").
+						Order("store_categories.position ASC").
 						Find(&categories).
 						Error
 					if err := query; err != nil {
@@ -1,10 +1,14 @@
 package gql
 
 import (
+	"errors"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/auth"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/trips"
 	"github.com/graphql-go/graphql"
+	uuid "github.com/satori/go.uuid"
 )
 
 var GroceryTripCategoryType = graphql.NewObject(
@@ -31,11 +35,32 @@ var GroceryTripCategoryType = graphql.NewObject(
 					"filter": &graphql.ArgumentConfig{
 						Type: graphql.String,
 					},
+					"assignedTo": &graphql.ArgumentConfig{
+						Type:        graphql.String,
+						Description: "Only items assigned to this user ID, or to the current user with me",
+					},
 				},
 				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
 					tripID := p.Source.(models.GroceryTripCategory).GroceryTripID
 					categoryID := p.Source.(models.GroceryTripCategory).ID
-					items, err := trips.RetrieveItemsInCategory(tripID, categoryID)
+					var assigneeID *uuid.UUID
+					if p.Args["assignedTo"] != nil {
+						if p.Args["assignedTo"].(string) == "me" {
+							header := p.Info.RootValue.(map[string]interface{})["Authorization"]
+							user, err := auth.FetchAuthenticatedUser(header.(string))
+							if err != nil {
+								return nil, err
+							}
+							assigneeID = &user.ID
+						} else {
+							id, err := uuid.FromString(p.Args["assignedTo"].(string))
+							if err != nil {
+								return nil, errors.New("assignedTo arg must be me or a user ID")
+							}
+							assigneeID = &id
+						}
+					}
+					items, err := trips.RetrieveItemsInCategory(tripID, categoryID, assigneeID)
 					if err != nil {
 						return nil, err
 					}
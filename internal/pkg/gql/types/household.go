@@ -0,0 +1,101 @@
+package gql
+
+import (
+	"errors"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/households"
+	"github.com/graphql-go/graphql"
+	"gorm.io/gorm"
+)
+
+// HouseholdType defines a graphql type for Household
+var HouseholdType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "Household",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"userId": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"name": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"users": &graphql.Field{
+				Type: graphql.NewList(HouseholdUserType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					householdID := p.Source.(models.Household).ID
+					householdUsers, err := households.RetrieveHouseholdUsers(householdID)
+					if err != nil {
+						return nil, err
+					}
+					return householdUsers, nil
+				},
+			},
+			"stores": &graphql.Field{
+				Type: graphql.NewList(StoreType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					householdID := p.Source.(models.Household).ID
+					stores, err := households.RetrieveHouseholdStores(householdID)
+					if err != nil {
+						return nil, err
+					}
+					return stores, nil
+				},
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+			"updatedAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+		},
+	},
+)
+
+// HouseholdUserType defines a graphql type for HouseholdUser
+var HouseholdUserType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "HouseholdUser",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"householdId": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"userId": &graphql.Field{
+				Type: graphql.ID,
+			},
+			"email": &graphql.Field{
+				Type: graphql.String,
+			},
+			"creator": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+			},
+			"active": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+			"updatedAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+			"user": &graphql.Field{
+				Type: UserType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					userID := p.Source.(models.HouseholdUser).UserID
+					user := &models.User{}
+					if err := db.Manager.Where("id = ?", userID).First(&user).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+						return nil, err
+					}
+					return user, nil
+				},
+			},
+		},
+	},
+)
@@ -7,7 +7,9 @@ import (
 
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/photos"
 	"github.com/graphql-go/graphql"
+	uuid "github.com/satori/go.uuid"
 )
 
 // ItemType defines a graphql type for Item
@@ -29,7 +31,8 @@ var ItemType = graphql.NewObject(
 				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
 					groceryTripCategoryID := p.Source.(*models.Item).CategoryID
 					groceryTripCategory := &models.GroceryTripCategory{}
-					if err := db.Manager.Select("store_category_id").Where("id = ?", groceryTripCategoryID).First(&groceryTripCategory).Error; err != nil {
+					// Unscoped so that deleted items still have their category
+					if err := db.Manager.Unscoped().Select("store_category_id").Where("id = ?", groceryTripCategoryID).First(&groceryTripCategory).Error; err != nil {
 						return nil, err
 					}
 					storeCategory := &models.StoreCategory{}
@@ -40,6 +43,14 @@ var ItemType = graphql.NewObject(
 					return storeCategory.Name, nil
 				},
 			},
+			"categorySource": &graphql.Field{
+				Type:        graphql.String,
+				Description: "How the item's category was decided: user, storeSetting, pinned, catalogue, vocabulary, classifier or default",
+			},
+			"categoryConfidence": &graphql.Field{
+				Type:        graphql.Float,
+				Description: "How sure the item's category is, from 0 to 1. Categories the classifier wasn't sure of can be fixed with updateItem (with saveStoreCategoryId, so they stick)",
+			},
 			"userId": &graphql.Field{
 				Type: graphql.NewNonNull(graphql.ID),
 			},
@@ -49,11 +60,110 @@ var ItemType = graphql.NewObject(
 			"quantity": &graphql.Field{
 				Type: graphql.Int,
 			},
+			"decimalQuantity": &graphql.Field{
+				Type:        graphql.Float,
+				Description: "The measured amount of the item (e.g. 1.5 with a unit of kg). Unset when quantity is a plain count",
+			},
+			"unit": &graphql.Field{
+				Type:        graphql.String,
+				Description: "The normalised unit of decimalQuantity (lb, oz, kg, g, l or ml)",
+			},
 			"notes": &graphql.Field{
 				Type: graphql.String,
 			},
+			"unitPrice": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "The price of a single unit of the item in the minor unit of its currency (i.e. cents)",
+			},
+			"currency": &graphql.Field{
+				Type: graphql.String,
+			},
+			"barcode": &graphql.Field{
+				Type:        graphql.String,
+				Description: "The UPC/EAN code the item was scanned with",
+			},
+			"needsName": &graphql.Field{
+				Type:        graphql.Boolean,
+				Description: "Set when the item was scanned with a barcode that isn't known, so the user should be asked to name it",
+			},
+			"merged": &graphql.Field{
+				Type:        graphql.Boolean,
+				Description: "Set when the item being added was merged into an item already on the trip",
+			},
+			"additions": &graphql.Field{
+				Type:        graphql.NewList(ItemAdditionType),
+				Description: "Who added the item and for which meal, when additions have been merged into it",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var itemID uuid.UUID
+					switch item := p.Source.(type) {
+					case *models.Item:
+						itemID = item.ID
+					case models.Item:
+						itemID = item.ID
+					}
+					var additions []models.ItemAddition
+					if err := db.Manager.Where("item_id = ?", itemID).Order("created_at ASC").Find(&additions).Error; err != nil {
+						return nil, err
+					}
+					return additions, nil
+				},
+			},
+			"assigneeId": &graphql.Field{
+				Type: graphql.ID,
+			},
+			"assignee": &graphql.Field{
+				Type:        UserType,
+				Description: "The store member who has been asked to pick up the item",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var assigneeID *uuid.UUID
+					switch item := p.Source.(type) {
+					case *models.Item:
+						assigneeID = item.AssigneeID
+					case models.Item:
+						assigneeID = item.AssigneeID
+					}
+					if assigneeID == nil {
+						return nil, nil
+					}
+					var user models.User
+					if err := db.Manager.Where("id = ?", assigneeID).First(&user).Error; err != nil {
+						if errors.Is(err, gorm.ErrRecordNotFound) {
+							return nil, nil
+						}
+						return nil, err
+					}
+					return user, nil
+				},
+			},
+			"photo": &graphql.Field{
+				Type: PhotoType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var itemID uuid.UUID
+					switch item := p.Source.(type) {
+					case *models.Item:
+						itemID = item.ID
+					case models.Item:
+						itemID = item.ID
+					}
+					return photos.RetrievePhoto(models.PhotoOwnerItem, itemID)
+				},
+			},
 			"position": &graphql.Field{
-				Type: graphql.NewNonNull(graphql.Int),
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "The position (from 1) of the item in its category",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var item models.Item
+					switch source := p.Source.(type) {
+					case *models.Item:
+						item = *source
+					case models.Item:
+						item = source
+					}
+					if item.Position > 0 {
+						return item.Position, nil
+					}
+					return item.PositionInCategory(db.Manager)
+				},
 			},
 			"completed": &graphql.Field{
 				Type: graphql.NewNonNull(graphql.Boolean),
@@ -84,6 +194,23 @@ var ItemType = graphql.NewObject(
 			"updatedAt": &graphql.Field{
 				Type: graphql.DateTime,
 			},
+			"deletedAt": &graphql.Field{
+				Type:        graphql.DateTime,
+				Description: "When the item was deleted, for items that can still be restored",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var deletedAt gorm.DeletedAt
+					switch item := p.Source.(type) {
+					case *models.Item:
+						deletedAt = item.DeletedAt
+					case models.Item:
+						deletedAt = item.DeletedAt
+					}
+					if !deletedAt.Valid {
+						return nil, nil
+					}
+					return deletedAt.Time, nil
+				},
+			},
 		},
 	},
 )
@@ -0,0 +1,57 @@
+package gql
+
+import (
+	"errors"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/graphql-go/graphql"
+	"gorm.io/gorm"
+)
+
+// ItemAdditionType defines a graphql type for ItemAddition
+var ItemAdditionType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "ItemAddition",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"itemId": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"userId": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"user": &graphql.Field{
+				Type: UserType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					userID := p.Source.(models.ItemAddition).UserID
+					var user models.User
+					if err := db.Manager.Where("id = ?", userID).First(&user).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+						return nil, err
+					}
+					return user, nil
+				},
+			},
+			"quantity": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"decimalQuantity": &graphql.Field{
+				Type: graphql.Float,
+			},
+			"unit": &graphql.Field{
+				Type: graphql.String,
+			},
+			"mealId": &graphql.Field{
+				Type: graphql.ID,
+			},
+			"mealName": &graphql.Field{
+				Type: graphql.String,
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+		},
+	},
+)
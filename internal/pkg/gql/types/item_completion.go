@@ -0,0 +1,53 @@
+package gql
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/autocomplete"
+	"github.com/graphql-go/graphql"
+)
+
+// ItemCompletionSourceEnum defines where an item name completion came from
+var ItemCompletionSourceEnum = graphql.NewEnum(
+	graphql.EnumConfig{
+		Name: "ItemCompletionSource",
+		Values: graphql.EnumValueConfigMap{
+			"history": &graphql.EnumValueConfig{
+				Value:       autocomplete.SourceHistory,
+				Description: "An item previously added to the store",
+			},
+			"staple": &graphql.EnumValueConfig{
+				Value:       autocomplete.SourceStaple,
+				Description: "One of the store's staple items",
+			},
+			"categorySetting": &graphql.EnumValueConfig{
+				Value:       autocomplete.SourceCategorySetting,
+				Description: "An item with a category saved in the store settings",
+			},
+			"vocabulary": &graphql.EnumValueConfig{
+				Value:       autocomplete.SourceVocabulary,
+				Description: "A common food",
+			},
+		},
+	},
+)
+
+// ItemCompletionType defines a graphql type for Completion
+var ItemCompletionType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "ItemCompletion",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"category": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "The name of the category the item would be added to",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(autocomplete.Completion).CategoryName, nil
+				},
+			},
+			"source": &graphql.Field{
+				Type: graphql.NewNonNull(ItemCompletionSourceEnum),
+			},
+		},
+	},
+)
@@ -0,0 +1,67 @@
+package gql
+
+import (
+	"github.com/graphql-go/graphql"
+)
+
+// ItemSearchHitType defines a graphql type for ItemSearchHit
+var ItemSearchHitType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "ItemSearchHit",
+		Fields: graphql.Fields{
+			"item": &graphql.Field{
+				Type: graphql.NewNonNull(ItemType),
+			},
+			"score": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Float),
+				Description: "How similar the item's name is to the search term, from 0 to 1",
+			},
+		},
+	},
+)
+
+// ItemSearchTripType defines a graphql type for ItemSearchTrip
+var ItemSearchTripType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "ItemSearchTrip",
+		Fields: graphql.Fields{
+			"trip": &graphql.Field{
+				Type: graphql.NewNonNull(GroceryTripType),
+			},
+			"hits": &graphql.Field{
+				Type: graphql.NewList(ItemSearchHitType),
+			},
+		},
+	},
+)
+
+// ItemSearchStoreType defines a graphql type for ItemSearchStore
+var ItemSearchStoreType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "ItemSearchStore",
+		Fields: graphql.Fields{
+			"store": &graphql.Field{
+				Type: graphql.NewNonNull(StoreType),
+			},
+			"trips": &graphql.Field{
+				Type: graphql.NewList(ItemSearchTripType),
+			},
+		},
+	},
+)
+
+// ItemSearchResultsType defines a graphql type for ItemSearchResults
+var ItemSearchResultsType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "ItemSearchResults",
+		Fields: graphql.Fields{
+			"total": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "The number of matching items across all pages",
+			},
+			"stores": &graphql.Field{
+				Type: graphql.NewList(ItemSearchStoreType),
+			},
+		},
+	},
+)
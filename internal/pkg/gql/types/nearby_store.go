@@ -0,0 +1,26 @@
+package gql
+
+import (
+	"github.com/graphql-go/graphql"
+)
+
+// NearbyStoreType defines a graphql type for a store and its distance from
+// the user
+var NearbyStoreType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "NearbyStore",
+		Fields: graphql.Fields{
+			"store": &graphql.Field{
+				Type: StoreType,
+			},
+			"distance": &graphql.Field{
+				Type:        graphql.Float,
+				Description: "Distance from the store in metres",
+			},
+			"withinGeofence": &graphql.Field{
+				Type:        graphql.Boolean,
+				Description: "Whether the user is within the store's geofence radius",
+			},
+		},
+	},
+)
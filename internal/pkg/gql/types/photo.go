@@ -0,0 +1,48 @@
+package gql
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/photos"
+	"github.com/graphql-go/graphql"
+)
+
+// PhotoType defines a graphql type for Photo
+var PhotoType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "Photo",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"contentType": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"size": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+			},
+			"width": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+			},
+			"height": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+			},
+			"url": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "Signed URL of the photo, which expires after an hour",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return photos.URL(p.Source.(*models.Photo).Key)
+				},
+			},
+			"thumbnailUrl": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "Signed URL of a thumbnail of the photo, which expires after an hour",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return photos.URL(p.Source.(*models.Photo).ThumbnailKey)
+				},
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+		},
+	},
+)
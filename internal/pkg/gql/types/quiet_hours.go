@@ -0,0 +1,36 @@
+package gql
+
+import "github.com/graphql-go/graphql"
+
+// QuietHoursType defines a graphql type for QuietHours
+var QuietHoursType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "QuietHours",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"userId": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"start": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"end": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"timezone": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"holdNotifications": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+			"updatedAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+		},
+	},
+)
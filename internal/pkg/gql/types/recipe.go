@@ -2,6 +2,7 @@ package gql
 
 import (
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/photos"
 	"github.com/graphql-go/graphql"
 )
 
@@ -28,6 +29,13 @@ var RecipeType = graphql.NewObject(
 			"imageUrl": &graphql.Field{
 				Type: graphql.String,
 			},
+			"image": &graphql.Field{
+				Type:        PhotoType,
+				Description: "The image uploaded for the recipe (imageUrl is the image of an imported recipe)",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return photos.RetrievePhoto(models.PhotoOwnerRecipe, p.Source.(models.Recipe).ID)
+				},
+			},
 			"ingredients": &graphql.Field{
 				Type: graphql.NewList(RecipeIngredientType),
 				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
@@ -58,6 +58,63 @@ var StoreType = graphql.NewObject(
 					return storeUsers, nil
 				},
 			},
+			"householdId": &graphql.Field{
+				Type: graphql.ID,
+			},
+			"address": &graphql.Field{
+				Type: graphql.String,
+			},
+			"latitude": &graphql.Field{
+				Type: graphql.Float,
+			},
+			"longitude": &graphql.Field{
+				Type: graphql.Float,
+			},
+			"geofenceRadius": &graphql.Field{
+				Type: graphql.Int,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					radius := p.Source.(models.Store).GeofenceRadius
+					if radius == nil {
+						return models.DefaultGeofenceRadius, nil
+					}
+					return *radius, nil
+				},
+			},
+			"mergeDuplicateItems": &graphql.Field{
+				Type:        graphql.Boolean,
+				Description: "Whether adding an item that's already on the trip increases its quantity instead of adding it again",
+			},
+			"schedule": &graphql.Field{
+				Type: StoreScheduleType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					storeID := p.Source.(models.Store).ID
+					schedule, err := trips.RetrieveStoreSchedule(storeID)
+					if err != nil {
+						return nil, err
+					}
+					if schedule == nil {
+						return nil, nil
+					}
+					return *schedule, nil
+				},
+			},
+			"budget": &graphql.Field{
+				Type: StoreBudgetType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					storeID := p.Source.(models.Store).ID
+					budget, err := stores.RetrieveStoreBudget(storeID)
+					if err != nil {
+						return nil, err
+					}
+					if budget == nil {
+						return nil, nil
+					}
+					return *budget, nil
+				},
+			},
+			"archivedAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
 			"createdAt": &graphql.Field{
 				Type: graphql.DateTime,
 			},
@@ -0,0 +1,126 @@
+package gql
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/graphql-go/graphql"
+	uuid "github.com/satori/go.uuid"
+)
+
+// storeActivityChange is a single changed attribute in a store activity entry
+type storeActivityChange struct {
+	Attribute string `json:"attribute"`
+	Before    string `json:"before"`
+	After     string `json:"after"`
+}
+
+// StoreActivityChangeType defines a graphql type for an attribute changed by
+// the action in a store activity entry
+var StoreActivityChangeType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "StoreActivityChange",
+		Fields: graphql.Fields{
+			"attribute": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"before": &graphql.Field{
+				Type: graphql.String,
+			},
+			"after": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+	},
+)
+
+// StoreActivityType defines a graphql type for StoreActivity
+var StoreActivityType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "StoreActivity",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"storeId": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"userId": &graphql.Field{
+				Type:        graphql.ID,
+				Description: "The user who performed the action, or null if it was performed by the system (e.g. a store schedule completing a trip)",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					userID := p.Source.(models.StoreActivity).UserID
+					if userID == uuid.Nil {
+						return nil, nil
+					}
+					return userID, nil
+				},
+			},
+			"user": &graphql.Field{
+				Type: BasicUserType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					userID := p.Source.(models.StoreActivity).UserID
+					if userID == uuid.Nil {
+						return nil, nil
+					}
+					user := &models.User{}
+					if err := db.Manager.Where("id = ?", userID).Find(&user).Error; err != nil {
+						return nil, err
+					}
+					return user, nil
+				},
+			},
+			"action": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"entityType": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"entityId": &graphql.Field{
+				Type: graphql.ID,
+			},
+			"entityName": &graphql.Field{
+				Type: graphql.String,
+			},
+			"changes": &graphql.Field{
+				Type: graphql.NewList(StoreActivityChangeType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					entry := p.Source.(models.StoreActivity)
+					changes := []storeActivityChange{}
+					if len(entry.Changes) == 0 {
+						return changes, nil
+					}
+					var changesMap map[string]activity.Change
+					if err := json.Unmarshal(entry.Changes, &changesMap); err != nil {
+						return nil, err
+					}
+					for attribute, change := range changesMap {
+						changes = append(changes, storeActivityChange{
+							Attribute: attribute,
+							Before:    formatActivityValue(change.Before),
+							After:     formatActivityValue(change.After),
+						})
+					}
+					sort.Slice(changes, func(i, j int) bool {
+						return changes[i].Attribute < changes[j].Attribute
+					})
+					return changes, nil
+				},
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+		},
+	},
+)
+
+func formatActivityValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
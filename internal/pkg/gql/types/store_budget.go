@@ -0,0 +1,73 @@
+package gql
+
+import "github.com/graphql-go/graphql"
+
+// StoreBudgetType defines a graphql type for StoreBudget
+var StoreBudgetType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "StoreBudget",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"storeId": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"monthlyAmount": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "The monthly budget in the minor unit of the currency (i.e. cents)",
+			},
+			"currency": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+			"updatedAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+		},
+	},
+)
+
+// CategorySpendingType defines a graphql type for the spending in a store category
+var CategorySpendingType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "CategorySpending",
+		Fields: graphql.Fields{
+			"storeCategoryId": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"name": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"currency": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"amount": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "The amount spent in the minor unit of the currency (i.e. cents)",
+			},
+		},
+	},
+)
+
+// TripTotalType defines a graphql type for the total price of a trip in a currency
+var TripTotalType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "TripTotal",
+		Fields: graphql.Fields{
+			"currency": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"estimated": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "The total of every item with a price, in the minor unit of the currency",
+			},
+			"actual": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "The total of the items that have been picked up, in the minor unit of the currency",
+			},
+		},
+	},
+)
@@ -17,6 +17,9 @@ var StoreCategoryType = graphql.NewObject(
 			"name": &graphql.Field{
 				Type: graphql.NewNonNull(graphql.String),
 			},
+			"position": &graphql.Field{
+				Type: graphql.Int,
+			},
 			"createdAt": &graphql.Field{
 				Type: graphql.DateTime,
 			},
@@ -0,0 +1,34 @@
+package gql
+
+import "github.com/graphql-go/graphql"
+
+// StoreScheduleType defines a graphql type for StoreSchedule
+var StoreScheduleType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "StoreSchedule",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"storeId": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"weekday": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "The day of the week to shop on, where 0 is Sunday",
+			},
+			"timezone": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"copyRemainingItems": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+			"updatedAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+		},
+	},
+)
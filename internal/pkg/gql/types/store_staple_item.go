@@ -1,9 +1,39 @@
 package gql
 
 import (
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
 	"github.com/graphql-go/graphql"
 )
 
+// StapleCadenceEnum defines how often a staple item is added to new trips
+var StapleCadenceEnum = graphql.NewEnum(
+	graphql.EnumConfig{
+		Name: "StapleCadence",
+		Values: graphql.EnumValueConfigMap{
+			"everyTrip": &graphql.EnumValueConfig{
+				Value:       models.StapleCadenceEveryTrip,
+				Description: "Every new trip",
+			},
+			"trips": &graphql.EnumValueConfig{
+				Value:       models.StapleCadenceTrips,
+				Description: "Every interval new trips",
+			},
+			"days": &graphql.EnumValueConfig{
+				Value:       models.StapleCadenceDays,
+				Description: "Once interval days have passed since it was last purchased",
+			},
+			"weekdays": &graphql.EnumValueConfig{
+				Value:       models.StapleCadenceWeekdays,
+				Description: "Once one of its weekdays has come around since it was last added",
+			},
+		},
+	},
+)
+
 // StoreStapleItemType defines a graphql type for Item
 var StoreStapleItemType = graphql.NewObject(
 	graphql.ObjectConfig{
@@ -18,6 +48,64 @@ var StoreStapleItemType = graphql.NewObject(
 			"name": &graphql.Field{
 				Type: graphql.NewNonNull(graphql.String),
 			},
+			"quantity": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"decimalQuantity": &graphql.Field{
+				Type:        graphql.Float,
+				Description: "The measured amount of the staple (e.g. 1.5 for 1.5 kg), if it has a unit",
+			},
+			"unit": &graphql.Field{
+				Type: graphql.String,
+			},
+			"notes": &graphql.Field{
+				Type: graphql.String,
+			},
+			"storeCategoryId": &graphql.Field{
+				Type:        graphql.ID,
+				Description: "The category the staple is pinned to, if any",
+			},
+			"storeCategory": &graphql.Field{
+				Type:        StoreCategoryType,
+				Description: "The category the staple is pinned to, if any",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					storeCategoryID := p.Source.(models.StoreStapleItem).StoreCategoryID
+					if storeCategoryID == nil {
+						return nil, nil
+					}
+					storeCategory := models.StoreCategory{}
+					if err := db.Manager.Select("id, name").Where("id = ?", storeCategoryID).First(&storeCategory).Error; err != nil {
+						return nil, err
+					}
+					return storeCategory, nil
+				},
+			},
+			"cadence": &graphql.Field{
+				Type: StapleCadenceEnum,
+			},
+			"interval": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"weekdays": &graphql.Field{
+				Type:        graphql.NewList(graphql.Int),
+				Description: "The days of the week (0 is Sunday) for the weekdays cadence",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					staple := p.Source.(models.StoreStapleItem)
+					weekdays := []int{}
+					for day := time.Sunday; day <= time.Saturday; day++ {
+						if staple.OnWeekday(day) {
+							weekdays = append(weekdays, int(day))
+						}
+					}
+					return weekdays, nil
+				},
+			},
+			"lastAddedAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+			"lastPurchasedAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
 			"createdAt": &graphql.Field{
 				Type: graphql.DateTime,
 			},
@@ -27,3 +115,34 @@ var StoreStapleItemType = graphql.NewObject(
 		},
 	},
 )
+
+// UpcomingStapleItemType defines a graphql type for UpcomingStapleItem
+var UpcomingStapleItemType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "UpcomingStapleItem",
+		Fields: graphql.Fields{
+			"stapleItem": &graphql.Field{
+				Type: graphql.NewNonNull(StoreStapleItemType),
+			},
+			"dueNextTrip": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Whether the staple will be added to the next new trip",
+			},
+			"nextDueDate": &graphql.Field{
+				Type:        graphql.String,
+				Description: "The date (YYYY-MM-DD) the staple is next due, for the days and weekdays cadences",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					date := p.Source.(stores.UpcomingStapleItem).NextDueDate
+					if date == nil {
+						return nil, nil
+					}
+					return date.Format(models.ShoppingDateLayout), nil
+				},
+			},
+			"tripsUntilDue": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "How many more new trips until the staple is due, for the trips cadence",
+			},
+		},
+	},
+)
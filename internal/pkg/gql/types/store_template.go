@@ -0,0 +1,64 @@
+package gql
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
+	"github.com/graphql-go/graphql"
+)
+
+// StoreTemplateType defines a graphql type for StoreTemplate
+var StoreTemplateType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "StoreTemplate",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"name": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"categories": &graphql.Field{
+				Type: graphql.NewList(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					template := p.Source.(models.StoreTemplate)
+					return template.CategoryNames()
+				},
+			},
+			"stapleItems": &graphql.Field{
+				Type: graphql.NewList(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					template := p.Source.(models.StoreTemplate)
+					return template.StapleItemNames()
+				},
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+			"updatedAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+		},
+	},
+)
+
+// StoreDuplicateIncludeEnum defines the parts of a store that can be copied
+// when duplicating it
+var StoreDuplicateIncludeEnum = graphql.NewEnum(
+	graphql.EnumConfig{
+		Name: "StoreDuplicateInclude",
+		Values: graphql.EnumValueConfigMap{
+			"staples": &graphql.EnumValueConfig{
+				Value:       stores.DuplicateStaples,
+				Description: "Staple items",
+			},
+			"categorySettings": &graphql.EnumValueConfig{
+				Value:       stores.DuplicateCategorySettings,
+				Description: "Saved item category settings",
+			},
+			"members": &graphql.EnumValueConfig{
+				Value:       stores.DuplicateMembers,
+				Description: "Members, who are invited to the new store",
+			},
+		},
+	},
+)
@@ -1,6 +1,9 @@
 package gql
 
-import "github.com/graphql-go/graphql"
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/graphql-go/graphql"
+)
 
 // StoreUserPreferenceType defines a graphql type for StoreUserPreference
 var StoreUserPreferenceType = graphql.NewObject(
@@ -16,7 +19,58 @@ var StoreUserPreferenceType = graphql.NewObject(
 			"defaultStore": &graphql.Field{
 				Type: graphql.Boolean,
 			},
+			"suggestions": &graphql.Field{
+				Type:        graphql.Boolean,
+				Description: "Whether the user sees suggested items from their purchase history",
+			},
 			"notifications": &graphql.Field{
+				Type:              graphql.Boolean,
+				DeprecationReason: "Use the push and email preferences for each kind of notification",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					sup := p.Source.(models.StoreUserPreference)
+					return sup.PushEnabled(), nil
+				},
+			},
+			"pushItemsAdded": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"pushMeals": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"pushMemberJoined": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"pushStoreChanges": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"pushTripReminders": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"pushBudgetAlerts": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"pushItemAssigned": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"emailItemsAdded": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"emailMeals": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"emailMemberJoined": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"emailStoreChanges": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"emailTripReminders": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"emailBudgetAlerts": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"emailItemAssigned": &graphql.Field{
 				Type: graphql.Boolean,
 			},
 			"createdAt": &graphql.Field{
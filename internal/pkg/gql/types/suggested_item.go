@@ -0,0 +1,32 @@
+package gql
+
+import (
+	"github.com/graphql-go/graphql"
+)
+
+// SuggestedItemType defines a graphql type for SuggestedItem
+var SuggestedItemType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "SuggestedItem",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"reason": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "Why the item is suggested, i.e. bought every ~9 days, last bought 12 days ago",
+			},
+			"purchaseCount": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "How many times the item has been bought in the last year",
+			},
+			"intervalDays": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "How many days there typically are between purchases of the item",
+			},
+			"lastPurchasedAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+		},
+	},
+)
@@ -0,0 +1,259 @@
+package households
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/mailer"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// CreateHousehold creates a household for a user
+//
+// Note: The creator is added to the household in the AfterCreate hook on the model
+func CreateHousehold(userID uuid.UUID, name string) (household models.Household, err error) {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return household, errors.New("household name can't be blank")
+	}
+	household = models.Household{UserID: userID, Name: name}
+	if err := db.Manager.Create(&household).Error; err != nil {
+		return household, err
+	}
+	return household, nil
+}
+
+// RetrieveUserHouseholds retrieves the households that the user is an active member of
+func RetrieveUserHouseholds(userID uuid.UUID) (households []models.Household, err error) {
+	query := db.Manager.
+		Select("households.*").
+		Joins("INNER JOIN household_users ON household_users.household_id = households.id").
+		Where("household_users.deleted_at IS NULL").
+		Where("household_users.user_id = ? AND household_users.active = ?", userID, true).
+		Order("households.created_at ASC").
+		Find(&households).
+		Error
+	if err := query; err != nil {
+		return households, err
+	}
+	return households, nil
+}
+
+// RetrieveInvitedUserHouseholds retrieves the households that the user has
+// been invited to but hasn't joined yet
+func RetrieveInvitedUserHouseholds(user models.User) (households []models.Household, err error) {
+	query := db.Manager.
+		Select("households.*").
+		Joins("INNER JOIN household_users ON household_users.household_id = households.id").
+		Where("household_users.deleted_at IS NULL").
+		Where("household_users.email = ? AND household_users.active = ?", user.Email, false).
+		Order("households.created_at ASC").
+		Find(&households).
+		Error
+	if err := query; err != nil {
+		return households, err
+	}
+	return households, nil
+}
+
+// RetrieveHouseholdUsers retrieves the active members of a household
+func RetrieveHouseholdUsers(householdID uuid.UUID) (householdUsers []models.HouseholdUser, err error) {
+	query := db.Manager.
+		Where("household_id = ? AND active = ?", householdID, true).
+		Order("created_at ASC").
+		Find(&householdUsers).
+		Error
+	if err := query; err != nil {
+		return householdUsers, err
+	}
+	return householdUsers, nil
+}
+
+// RetrieveHouseholdStores retrieves the stores in a household
+func RetrieveHouseholdStores(householdID uuid.UUID) (stores []models.Store, err error) {
+	query := db.Manager.
+		Where("household_id = ?", householdID).
+		Where("archived_at IS NULL").
+		Order("created_at ASC").
+		Find(&stores).
+		Error
+	if err := query; err != nil {
+		return stores, err
+	}
+	return stores, nil
+}
+
+// InviteToHousehold creates a pending household_users record for the email
+// provided and emails them the invitation. Once the invitation is accepted
+// with JoinHousehold, the user is added to every store in the household
+func InviteToHousehold(householdID interface{}, userID uuid.UUID, email string) (householdUser models.HouseholdUser, err error) {
+	household, err := fetchMemberHousehold(householdID, userID)
+	if err != nil {
+		return householdUser, err
+	}
+
+	var count int64
+	existsQuery := db.Manager.
+		Model(&models.HouseholdUser{}).
+		Joins("LEFT OUTER JOIN users ON users.id = household_users.user_id").
+		Where("household_users.household_id = ?", household.ID).
+		Where("household_users.email = ? OR users.email = ?", email, email).
+		Count(&count).
+		Error
+	if err := existsQuery; err != nil {
+		return householdUser, err
+	}
+	if count > 0 {
+		return householdUser, errors.New("this household is already being shared with this user")
+	}
+
+	active := false
+	householdUser = models.HouseholdUser{
+		HouseholdID: household.ID,
+		Email:       email,
+		Active:      &active,
+	}
+	if err := db.Manager.Create(&householdUser).Error; err != nil {
+		return householdUser, err
+	}
+
+	// The invitation is only sent once the household user has been created
+	inviter := models.User{}
+	if err := db.Manager.Select("name").Where("id = ?", userID).First(&inviter).Error; err != nil {
+		return householdUser, err
+	}
+	if _, err := mailer.SendHouseholdInvitationEmail(household.Name, email, inviter.Name); err != nil {
+		return householdUser, err
+	}
+	return householdUser, nil
+}
+
+// JoinHousehold accepts a pending invitation to a household, and records the
+// user joining each of the household's stores in its activity log
+//
+// Note: The user is added to the household's stores in the AfterSave hook on the model
+func JoinHousehold(householdID interface{}, user models.User) (householdUser models.HouseholdUser, err error) {
+	query := db.Manager.
+		Where("household_id = ? AND email = ? AND active = ?", householdID, user.Email, false).
+		First(&householdUser).
+		Error
+	if err := query; err != nil {
+		return householdUser, errors.New("couldn't find an invitation to this household")
+	}
+
+	active := true
+	householdUser.Email = ""
+	householdUser.UserID = user.ID
+	householdUser.Active = &active
+	err = db.Manager.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&householdUser).Error; err != nil {
+			return err
+		}
+		for _, storeUser := range householdUser.JoinedStoreUsers {
+			activity.Record(tx, storeUser.StoreID, user.ID, models.ActivityMemberJoined, memberEntity(storeUser, user), nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return householdUser, err
+	}
+	return householdUser, nil
+}
+
+// LeaveHousehold removes a user from a household (or declines an invitation
+// to it), along with the stores that the household added them to. Stores they
+// created or were invited to directly are kept. The user leaving each store
+// is recorded in its activity log
+func LeaveHousehold(householdID interface{}, user models.User) (householdUser models.HouseholdUser, err error) {
+	query := db.Manager.
+		Where("household_id = ?", householdID).
+		Where("user_id = ? OR email = ?", user.ID, user.Email).
+		First(&householdUser).
+		Error
+	if err := query; err != nil {
+		return householdUser, errors.New("couldn't retrieve household")
+	}
+	if householdUser.Creator != nil && *householdUser.Creator {
+		return householdUser, errors.New("the creator of a household can't leave it")
+	}
+
+	err = db.Manager.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&householdUser).Error; err != nil {
+			return err
+		}
+		if householdUser.UserID == uuid.Nil {
+			return nil
+		}
+		var storeUsers []models.StoreUser
+		storeUsersQuery := tx.
+			Where("user_id = ? AND household_id = ?", householdUser.UserID, householdUser.HouseholdID).
+			Find(&storeUsers).
+			Error
+		if err := storeUsersQuery; err != nil {
+			return err
+		}
+		for i := range storeUsers {
+			if err := tx.Delete(&storeUsers[i]).Error; err != nil {
+				return err
+			}
+			activity.Record(tx, storeUsers[i].StoreID, user.ID, models.ActivityMemberLeft, memberEntity(storeUsers[i], user), nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return householdUser, err
+	}
+	return householdUser, nil
+}
+
+// SetStoreHousehold adds a store to a household, or removes it from its
+// household if householdID is nil. Only the creator of the store can do this.
+//
+// Members who were added to the store through a household keep their access
+// when the store is removed from it
+func SetStoreHousehold(storeID interface{}, userID uuid.UUID, householdID interface{}) (store models.Store, err error) {
+	if err := db.Manager.Where("id = ? AND user_id = ?", storeID, userID).First(&store).Error; err != nil {
+		return store, errors.New("couldn't retrieve store")
+	}
+
+	if householdID != nil {
+		household, err := fetchMemberHousehold(householdID, userID)
+		if err != nil {
+			return store, err
+		}
+		store.HouseholdID = &household.ID
+	} else {
+		store.HouseholdID = nil
+	}
+
+	// Note: Household members are added to the store in the AfterSave hook on the model
+	if err := db.Manager.Save(&store).Error; err != nil {
+		return store, err
+	}
+	return store, nil
+}
+
+// fetchMemberHousehold retrieves a household that the user is an active member of
+func fetchMemberHousehold(householdID interface{}, userID uuid.UUID) (household models.Household, err error) {
+	query := db.Manager.
+		Select("households.*").
+		Joins("INNER JOIN household_users ON household_users.household_id = households.id").
+		Where("household_users.deleted_at IS NULL").
+		Where("households.id = ?", householdID).
+		Where("household_users.user_id = ? AND household_users.active = ?", userID, true).
+		First(&household).
+		Error
+	if err := query; err != nil {
+		return household, errors.New("couldn't retrieve household")
+	}
+	return household, nil
+}
+
+// memberEntity identifies a store member in the store's activity log
+func memberEntity(storeUser models.StoreUser, user models.User) activity.Entity {
+	return activity.Entity{Type: activity.EntityStoreUser, ID: storeUser.ID, Name: user.Name}
+}
@@ -0,0 +1,244 @@
+package households
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type AnyTime struct{}
+
+// Match satisfies sqlmock.Argument interface
+func (a AnyTime) Match(v driver.Value) bool {
+	_, ok := v.(time.Time)
+	return ok
+}
+
+type Suite struct {
+	suite.Suite
+
+	DB   *gorm.DB
+	mock sqlmock.Sqlmock
+}
+
+func (s *Suite) SetupSuite() {
+	var (
+		dbMock *sql.DB
+		err    error
+	)
+
+	dbMock, s.mock, err = sqlmock.New()
+	require.NoError(s.T(), err)
+	s.DB, err = gorm.Open(postgres.New(postgres.Config{Conn: dbMock}), &gorm.Config{})
+	require.NoError(s.T(), err)
+
+	db.Manager = s.DB
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) TestCreateHousehold_BlankName() {
+	_, e := CreateHousehold(uuid.NewV4(), " ")
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "household name can't be blank", e.Error())
+}
+
+func (s *Suite) TestCreateHousehold_Created() {
+	userID := uuid.NewV4()
+	householdID := uuid.NewV4()
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^INSERT INTO \"households\" (.+)$").
+		WithArgs(userID, "The Smiths", AnyTime{}, AnyTime{}, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(householdID))
+	s.mock.ExpectQuery("^INSERT INTO \"household_users\" (.+)$").
+		WithArgs(householdID, userID, "", true, true, AnyTime{}, AnyTime{}, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	// The creator joins the household's stores, of which there are none yet
+	s.mock.ExpectQuery("^SELECT \"id\" FROM \"stores\"*").
+		WithArgs(householdID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+	s.mock.ExpectCommit()
+
+	household, err := CreateHousehold(userID, "The Smiths")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), householdID, household.ID)
+}
+
+func (s *Suite) TestJoinHousehold_NoInvitation() {
+	householdID := uuid.NewV4()
+	user := models.User{ID: uuid.NewV4(), Email: "test@example.com"}
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"household_users\"*").
+		WithArgs(householdID, user.Email, false).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	_, e := JoinHousehold(householdID, user)
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "couldn't find an invitation to this household", e.Error())
+}
+
+func (s *Suite) TestJoinHousehold_JoinsHouseholdStores() {
+	householdID := uuid.NewV4()
+	householdUserID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	user := models.User{ID: uuid.NewV4(), Email: "test@example.com", Name: "Jane"}
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"household_users\"*").
+		WithArgs(householdID, user.Email, false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "household_id", "email", "creator", "active"}).AddRow(householdUserID, householdID, user.Email, false, false))
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("^UPDATE \"household_users\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectQuery("^SELECT \"id\" FROM \"stores\"*").
+		WithArgs(householdID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(storeID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(storeID, user.ID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+	storeUserID := uuid.NewV4()
+	s.mock.ExpectQuery("^INSERT INTO \"store_users\" (.+)$").
+		WithArgs(storeID, user.ID, "", false, true, householdID, AnyTime{}, AnyTime{}, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(storeUserID))
+	s.mock.ExpectQuery("^INSERT INTO \"store_user_preferences\" (.+)$").
+		WithArgs(storeUserID, false, true, true, true, true, true, true, true, true, false, false, false, false, false, false, false, AnyTime{}, AnyTime{}, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectExec("^SAVEPOINT sp(.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(storeID, user.ID, "member_joined", "store_user", storeUserID, user.Name, nil, AnyTime{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	householdUser, err := JoinHousehold(householdID, user)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), user.ID, householdUser.UserID)
+	assert.Equal(s.T(), "", householdUser.Email)
+	assert.True(s.T(), *householdUser.Active)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestLeaveHousehold_Creator() {
+	householdID := uuid.NewV4()
+	user := models.User{ID: uuid.NewV4(), Email: "test@example.com"}
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"household_users\"*").
+		WithArgs(householdID, user.ID, user.Email).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "household_id", "user_id", "creator", "active"}).AddRow(uuid.NewV4(), householdID, user.ID, true, true))
+
+	_, e := LeaveHousehold(householdID, user)
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "the creator of a household can't leave it", e.Error())
+}
+
+func (s *Suite) TestLeaveHousehold_LeavesHouseholdStores() {
+	householdID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	user := models.User{ID: uuid.NewV4(), Email: "test@example.com", Name: "Jane"}
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"household_users\"*").
+		WithArgs(householdID, user.ID, user.Email).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "household_id", "user_id", "creator", "active"}).AddRow(uuid.NewV4(), householdID, user.ID, false, true))
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("^UPDATE \"household_users\" SET \"deleted_at\"(.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	storeUserID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(user.ID, householdID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "user_id"}).AddRow(storeUserID, storeID, user.ID))
+	s.mock.ExpectExec("^UPDATE \"store_users\" SET \"deleted_at\"(.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^SAVEPOINT sp(.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(storeID, user.ID, "member_left", "store_user", storeUserID, user.Name, nil, AnyTime{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	_, err := LeaveHousehold(householdID, user)
+	require.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestSetStoreHousehold_NotStoreCreator() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	_, e := SetStoreHousehold(storeID, userID, uuid.NewV4())
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "couldn't retrieve store", e.Error())
+}
+
+func (s *Suite) TestSetStoreHousehold_SameHousehold() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	householdID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "household_id"}).AddRow(storeID, userID, "Metro", householdID))
+	s.mock.ExpectQuery("^SELECT households.\\* FROM \"households\"*").
+		WithArgs(householdID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(householdID, "Home"))
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT \"household_id\" FROM \"stores\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"household_id"}).AddRow(householdID))
+	s.mock.ExpectExec("^UPDATE \"stores\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// The store is already in the household, so its members aren't added again
+	s.mock.ExpectCommit()
+
+	store, e := SetStoreHousehold(storeID, userID, householdID)
+	require.NoError(s.T(), e)
+	assert.Equal(s.T(), householdID, *store.HouseholdID)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestSetStoreHousehold_AddsHouseholdMembers() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	memberID := uuid.NewV4()
+	householdID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name"}).AddRow(storeID, userID, "Metro"))
+	s.mock.ExpectQuery("^SELECT households.\\* FROM \"households\"*").
+		WithArgs(householdID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(householdID, "Home"))
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT \"household_id\" FROM \"stores\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"household_id"}).AddRow(nil))
+	s.mock.ExpectExec("^UPDATE \"stores\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectQuery("^SELECT \"user_id\" FROM \"household_users\"*").
+		WithArgs(householdID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(memberID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(storeID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+	s.mock.ExpectQuery("^INSERT INTO \"store_users\" (.+)$").
+		WithArgs(storeID, memberID, "", false, true, householdID, AnyTime{}, AnyTime{}, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectQuery("^INSERT INTO \"store_user_preferences\" (.+)$").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	store, e := SetStoreHousehold(storeID, userID, householdID)
+	require.NoError(s.T(), e)
+	assert.Equal(s.T(), householdID, *store.HouseholdID)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
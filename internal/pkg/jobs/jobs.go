@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/notifications"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/photos"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/trips"
+)
+
+// Start kicks off the recurring background jobs. Each job runs once
+// immediately and then on its interval for the lifetime of the process.
+//
+// Every instance of the API starts the jobs, so each run takes a lock on the
+// job first and is skipped if another instance is already running it
+func Start() {
+	go every(time.Hour, "purge archived stores", stores.PurgeArchivedStores)
+	go every(time.Minute, "deliver pending notifications", notifications.DeliverPendingNotifications)
+	go every(15*time.Minute, "run store schedules", trips.RunStoreSchedules)
+	go every(15*time.Minute, "send shopping reminders", trips.SendShoppingReminders)
+	go every(time.Hour, "purge orphaned photos", photos.PurgeOrphanedPhotos)
+}
+
+// every runs fn on the interval provided, logging (but otherwise ignoring) errors
+func every(interval time.Duration, name string, fn func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for ; true; <-ticker.C {
+		if err := runLocked(name, fn); err != nil {
+			log.Printf("[jobs] %v: %v\n", name, err)
+		}
+	}
+}
+
+// runLocked runs fn while holding a Postgres advisory lock on the job's name,
+// so that only one instance runs the job at a time. fn isn't run if another
+// instance holds the lock. Advisory locks belong to a session, so the lock is
+// taken and released on the same connection
+func runLocked(name string, fn func() error) error {
+	sqlDB, err := db.Manager.DB()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", name).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", name); err != nil {
+			log.Printf("[jobs] %v: %v\n", name, err)
+		}
+	}()
+	return fn()
+}
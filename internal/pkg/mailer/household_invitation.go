@@ -0,0 +1,38 @@
+package mailer
+
+import (
+	"os"
+
+	"github.com/sendgrid/sendgrid-go"
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+)
+
+// SendHouseholdInvitationEmail sends an email to a person being invited to join a household
+func SendHouseholdInvitationEmail(householdName string, email string, inviterName string) (interface{}, error) {
+	m := mail.NewV3Mail()
+	from := mail.NewEmail("GroceryTime", "noreply@grocerytime.app")
+	m.SetFrom(from)
+	m.SetTemplateID("d-5c2f0e4b7a9d4e1f8b3c6a2d9e7f1b40")
+
+	p := mail.NewPersonalization()
+	toAddresses := []*mail.Email{
+		mail.NewEmail("", email),
+	}
+	p.AddTos(toAddresses...)
+
+	p.SetDynamicTemplateData("first_name", inviterName)
+	p.SetDynamicTemplateData("household_name", householdName)
+	p.SetDynamicTemplateData("email", email)
+
+	m.AddPersonalizations(p)
+
+	request := sendgrid.GetRequest(os.Getenv("SENDGRID_API_KEY"), "/v3/mail/send", "https://api.sendgrid.com")
+	request.Method = "POST"
+	var Body = mail.GetRequestBody(m)
+	request.Body = Body
+	response, err := sendgrid.API(request)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
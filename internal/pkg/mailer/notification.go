@@ -0,0 +1,26 @@
+package mailer
+
+import (
+	"os"
+
+	"github.com/sendgrid/sendgrid-go"
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+)
+
+// SendNotificationEmail sends the email version of a notification to a store
+// member who has opted in to receiving it by email
+func SendNotificationEmail(title string, body string, email string) (interface{}, error) {
+	from := mail.NewEmail("GroceryTime", "noreply@grocerytime.app")
+	to := mail.NewEmail("", email)
+	m := mail.NewSingleEmail(from, title, to, body, "")
+
+	request := sendgrid.GetRequest(os.Getenv("SENDGRID_API_KEY"), "/v3/mail/send", "https://api.sendgrid.com")
+	request.Method = "POST"
+	var Body = mail.GetRequestBody(m)
+	request.Body = Body
+	response, err := sendgrid.API(request)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
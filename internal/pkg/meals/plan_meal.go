@@ -78,7 +78,6 @@ func AddMealIngredientsToStore(meal models.Meal, storeID uuid.UUID, userID uuid.
 		item := itemsArg[i].(map[string]interface{})
 		quantity := item["quantity"].(int)
 		if quantity > 0 {
-			// TODO: attribute meal_id somehow (probably need to refactor AddItemsToStore "items" arg to support quantity and meal_id etc)
 			items = append(items, fmt.Sprintf("%s x %d", item["name"], item["quantity"]))
 		}
 	}
@@ -89,34 +88,18 @@ func AddMealIngredientsToStore(meal models.Meal, storeID uuid.UUID, userID uuid.
 		return addedItems, errors.New("store not found for storeId")
 	}
 
+	// The meal is attributed on the items as they're added, so that items
+	// merged into ones already on the trip keep their own meal
 	args := map[string]interface{}{
 		"storeName": store.Name,
 		"items":     items,
+		"mealId":    meal.ID,
+		"mealName":  meal.Name,
 	}
 	itemsAdded, err := trips.AddItemsToStore(userID, args)
 	if err != nil {
 		return addedItems, err
 	}
 
-	// Update items to attribute meal (meal_id + item note)
-	//
-	// Note: Ideally, I'd like this to be done at the time of adding the items to avoid
-	// a second query to update them to attribute meal_id. The challenge here is that
-	// the mutation that calls trips.AddItemsToStore cannot be easily updated to modify
-	// the shape of its "items" argument (such as to allow a meal_id or quantity parameter),
-	// since we cannot rely on every user updating the app.
-	var itemIds []uuid.UUID
-	for i := range itemsAdded {
-		itemIds = append(itemIds, itemsAdded[i].ID)
-	}
-	updateQuery := db.Manager.
-		Model(&models.Item{}).
-		Where("id IN (?)", itemIds).
-		UpdateColumns(models.Item{MealID: &meal.ID, MealName: &meal.Name}). // UpdateColumns to avoid hooks
-		Error
-	if err := updateQuery; err != nil {
-		return addedItems, err
-	}
-
 	return itemsAdded, nil
 }
@@ -101,6 +101,9 @@ func (s *Suite) TestPlanMeal_Valid() {
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
 		WithArgs(storeID, false).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(tripID, storeID))
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(userID, tripID, false, strings.ToLower(itemName), true).
+		WillReturnRows(sqlmock.NewRows([]string{}))
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
 		WithArgs(tripID).
 		WillReturnRows(sqlmock.NewRows([]string{"store_id"}).AddRow(storeID))
@@ -113,18 +116,22 @@ func (s *Suite) TestPlanMeal_Valid() {
 		WithArgs(storeID, strings.ToLower(itemName)).
 		WillReturnRows(sqlmock.NewRows([]string{}))
 
+	categoryID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT grocery_trip_categories.id FROM \"grocery_trip_categories\"*").
+		WithArgs(tripID, "Condiments").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(categoryID))
+
 	itemID := uuid.NewV4()
-	// UPDATE for before item insertion hook
-	s.mock.ExpectExec("^UPDATE items SET (.+)$").
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	// Rank for before item insertion hook
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\"*").
+		WithArgs(categoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}))
 	s.mock.ExpectQuery("^INSERT INTO \"items\" (.+)$").
-		WithArgs(tripID, sqlmock.AnyArg(), userID, nil, itemName, quantity, false, 1, nil, nil, nil, AnyTime{}, AnyTime{}, nil).
+		WithArgs(tripID, categoryID, "classifier", 1.0, userID, nil, nil, itemName, quantity, nil, nil, false, "i", nil, mealID, args["name"], nil, nil, nil, AnyTime{}, AnyTime{}, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
 	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
-		WillReturnResult(sqlmock.NewResult(1, 1))
 	s.mock.ExpectCommit()
 
 	meal, err := PlanMeal(userID, args)
@@ -6,8 +6,9 @@ import (
 	uuid "github.com/satori/go.uuid"
 )
 
-// StoreDeviceToken creates a Device record to store device tokens for iOS push notifications
-func StoreDeviceToken(token string, userID uuid.UUID) (device *models.Device, err error) {
+// StoreDeviceToken creates a Device record to store device tokens for iOS
+// push notifications, along with the app scheme the device is using
+func StoreDeviceToken(token string, userID uuid.UUID, appScheme string) (device *models.Device, err error) {
 	userDevice := &models.Device{UserID: userID, Token: token}
 	query := db.Manager.
 		Where(userDevice).
@@ -16,6 +17,12 @@ func StoreDeviceToken(token string, userID uuid.UUID) (device *models.Device, er
 	if err := query; err != nil {
 		return device, err
 	}
+	if len(appScheme) > 0 && userDevice.Scheme() != appScheme {
+		if err := db.Manager.Model(&userDevice).UpdateColumn("app_scheme", appScheme).Error; err != nil {
+			return device, err
+		}
+		userDevice.AppScheme = &appScheme
+	}
 	return userDevice, nil
 }
 
@@ -32,3 +39,11 @@ func DeviceTokensForUser(userID uuid.UUID) (tokens []string, err error) {
 	}
 	return tokens, nil
 }
+
+// DevicesForUser fetches all the devices stored for a user by ID
+func DevicesForUser(userID uuid.UUID) (devices []models.Device, err error) {
+	if err := db.Manager.Where("user_id = ?", userID).Find(&devices).Error; err != nil {
+		return devices, err
+	}
+	return devices, nil
+}
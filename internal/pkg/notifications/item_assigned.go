@@ -0,0 +1,41 @@
+package notifications
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+)
+
+// ItemAssigned sends a notification to the store user an item was assigned
+// to. Users aren't notified about items they assign to themselves
+func ItemAssigned(user models.User, item models.Item, appScheme string) {
+	if item.AssigneeID == nil {
+		return
+	}
+	var store models.Store
+	query := db.Manager.
+		Select("stores.id, stores.name").
+		Joins("INNER JOIN grocery_trips ON grocery_trips.store_id = stores.id").
+		Where("grocery_trips.id = ?", item.GroceryTripID).
+		First(&store).
+		Error
+	if err := query; err != nil {
+		log.Println(err)
+		return
+	}
+
+	var recipients []recipient
+	if err := storeRecipients(store.ID, user.ID).Where("store_users.user_id = ?", item.AssigneeID).Scan(&recipients).Error; err != nil {
+		log.Println(err)
+	}
+	msg := message{
+		Kind:       models.NotificationItemAssigned,
+		Title:      "Item Assigned",
+		Body:       fmt.Sprintf("%v asked you to pick up %v from %v", user.Name, item.Name, store.Name),
+		EntityName: "Store",
+		EntityID:   store.ID.String(),
+	}
+	notifyRecipients(recipients, msg, appScheme)
+}
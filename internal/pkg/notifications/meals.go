@@ -9,7 +9,7 @@ import (
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/utils"
 )
 
-// NewMeal sends a push notification about a new meal
+// NewMeal sends a notification about a new meal
 func NewMeal(meal *models.Meal, appScheme string) {
 	var user models.User
 	userQuery := db.Manager.
@@ -23,16 +23,17 @@ func NewMeal(meal *models.Meal, appScheme string) {
 
 	title := "Meal Planned"
 	body := fmt.Sprintf("%v added a meal to your meal plan", user.Name)
-	deviceTokens, err := MealUserTokens(meal)
-	if err != nil {
-		log.Println(err)
-	}
-	for i := range deviceTokens {
-		Send(title, body, deviceTokens[i], "Meal", meal.ID.String(), appScheme)
+	msg := message{
+		Kind:       models.NotificationMeals,
+		Title:      title,
+		Body:       body,
+		EntityName: "Meal",
+		EntityID:   meal.ID.String(),
 	}
+	notifyMealUsers(meal, msg, appScheme)
 }
 
-// MealRemoved sends a push notification about a meal being removed from a meal plan
+// MealRemoved sends a notification about a meal being removed from a meal plan
 func MealRemoved(meal models.Meal, appScheme string) {
 	var user models.User
 	userQuery := db.Manager.
@@ -47,16 +48,17 @@ func MealRemoved(meal models.Meal, appScheme string) {
 	title := "Meal Removed"
 	nameTruncated := utils.TruncateString(meal.Name, 12)
 	body := fmt.Sprintf("%v removed \"%v\" from your meal plan", user.Name, nameTruncated)
-	deviceTokens, err := MealUserTokens(&meal)
-	if err != nil {
-		log.Println(err)
-	}
-	for i := range deviceTokens {
-		Send(title, body, deviceTokens[i], "Meal", meal.ID.String(), appScheme)
+	msg := message{
+		Kind:       models.NotificationMeals,
+		Title:      title,
+		Body:       body,
+		EntityName: "Meal",
+		EntityID:   meal.ID.String(),
 	}
+	notifyMealUsers(&meal, msg, appScheme)
 }
 
-// MealUpdated sends a push notification about a meal being updated in a meal plan
+// MealUpdated sends a notification about a meal being updated in a meal plan
 func MealUpdated(meal models.Meal, origMealName string, appScheme string) {
 	var user models.User
 	userQuery := db.Manager.
@@ -71,42 +73,26 @@ func MealUpdated(meal models.Meal, origMealName string, appScheme string) {
 	title := "Meal Updated"
 	nameTruncated := utils.TruncateString(origMealName, 12)
 	body := fmt.Sprintf("%v updated \"%v\" in your meal plan", user.Name, nameTruncated)
-	deviceTokens, err := MealUserTokens(&meal)
-	if err != nil {
-		log.Println(err)
-	}
-	for i := range deviceTokens {
-		Send(title, body, deviceTokens[i], "Meal", meal.ID.String(), appScheme)
+	msg := message{
+		Kind:       models.NotificationMeals,
+		Title:      title,
+		Body:       body,
+		EntityName: "Meal",
+		EntityID:   meal.ID.String(),
 	}
+	notifyMealUsers(&meal, msg, appScheme)
 }
 
-// MealUserTokens fetches apns device tokens for all meal users
-func MealUserTokens(meal *models.Meal) (tokens []string, err error) {
-	var mealUsers []models.MealUser
-	mealUsersQuery := db.Manager.
-		Select("meal_users.user_id").
-		Joins("INNER JOIN meals ON meals.id = meal_users.meal_id").
-		Joins("INNER JOIN stores ON stores.id = meals.store_id").
-		Joins("INNER JOIN store_users ON store_users.store_id = stores.id").
-		Joins("INNER JOIN store_user_preferences ON store_user_preferences.store_user_id = store_users.id").
-		Where("meal_users.meal_id = ?", meal.ID).
-		Where("meal_users.user_id NOT IN (meals.user_id)").
-		Where("store_user_preferences.notifications = ?", true).
-		Group("meal_users.user_id").
-		Find(&mealUsers).
+// notifyMealUsers sends a notification about a meal to the users it's
+// planned for, except for the user who planned it
+func notifyMealUsers(meal *models.Meal, msg message, appScheme string) {
+	var recipients []recipient
+	query := storeRecipients(meal.StoreID, meal.UserID).
+		Where("store_users.user_id IN (?)", db.Manager.Model(&models.MealUser{}).Select("user_id").Where("meal_id = ?", meal.ID)).
+		Scan(&recipients).
 		Error
-	if err := mealUsersQuery; err != nil {
-		return tokens, err
-	}
-
-	// Fetch the tokens for each store user
-	var t []string
-	for i := range mealUsers {
-		userTokens, err := DeviceTokensForUser(mealUsers[i].UserID)
-		if err != nil {
-			return tokens, err
-		}
-		t = append(t, userTokens...)
+	if err := query; err != nil {
+		log.Println(err)
 	}
-	return t, nil
+	notifyRecipients(recipients, msg, appScheme)
 }
@@ -1,36 +1,166 @@
 package notifications
 
 import (
+	"log"
+	"time"
+
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/mailer"
 	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
 )
 
-// StoreUserTokens fetches apns device tokens for all store users associated
-// with the item provided, excluding for those users who have disabled
-// notifications in store user preference settings
-func StoreUserTokens(storeID uuid.UUID, userID uuid.UUID) (tokens []string, err error) {
-	var storeUsers []models.StoreUser
-	storeUsersQuery := db.Manager.
-		Select("store_users.user_id").
+// message is a notification to be delivered to store users
+type message struct {
+	Kind       string
+	Title      string
+	Body       string
+	EntityName string
+	EntityID   string
+}
+
+// recipient is a store user who may receive a notification, along with their
+// notification preferences for the store
+type recipient struct {
+	UserID      uuid.UUID
+	Email       string
+	Preferences models.StoreUserPreference `gorm:"embedded"`
+}
+
+// storeRecipients builds a query for the active users of a store, excluding
+// the user who triggered the notification
+func storeRecipients(storeID uuid.UUID, userID uuid.UUID) *gorm.DB {
+	return db.Manager.
+		Table("store_users").
+		Select("store_users.user_id, users.email, store_user_preferences.*").
+		Joins("INNER JOIN users ON users.id = store_users.user_id").
 		Joins("INNER JOIN store_user_preferences ON store_user_preferences.store_user_id = store_users.id").
 		Where("store_users.store_id = ?", storeID).
-		Where("store_users.user_id NOT IN (?)", userID).
-		Where("store_user_preferences.notifications = ?", true).
-		Find(&storeUsers).
-		Error
-	if err := storeUsersQuery; err != nil {
-		return tokens, err
+		Where("store_users.user_id <> ?", userID).
+		Where("store_users.active = ?", true).
+		Where("store_users.deleted_at IS NULL")
+}
+
+// notifyStoreUsers sends a notification about a store to all of its users,
+// except for the user who triggered it
+func notifyStoreUsers(userID uuid.UUID, storeID uuid.UUID, msg message, appScheme string) {
+	var recipients []recipient
+	if err := storeRecipients(storeID, userID).Scan(&recipients).Error; err != nil {
+		log.Println(err)
 	}
+	notifyRecipients(recipients, msg, appScheme)
+}
+
+// notifyRecipients delivers a notification to each recipient through the
+// channels they have enabled for its kind. During a recipient's quiet hours
+// the notification is either held until they end or dropped
+func notifyRecipients(recipients []recipient, msg message, appScheme string) {
+	for i := range recipients {
+		r := recipients[i]
+		push := r.Preferences.NotificationEnabled(models.NotificationChannelPush, msg.Kind)
+		email := r.Preferences.NotificationEnabled(models.NotificationChannelEmail, msg.Kind)
+		if !push && !email {
+			continue
+		}
 
-	// Fetch the tokens for each store user
-	var t []string
-	for i := range storeUsers {
-		userTokens, err := DeviceTokensForUser(storeUsers[i].UserID)
+		var deliverAt *time.Time
+		quietHours, err := RetrieveQuietHours(r.UserID)
 		if err != nil {
-			return tokens, err
+			log.Println(err)
+		}
+		if quietHours != nil {
+			until, quiet, err := quietHours.Until(time.Now())
+			if err != nil {
+				log.Println(err)
+			}
+			if quiet {
+				if !*quietHours.HoldNotifications {
+					continue
+				}
+				deliverAt = &until
+			}
+		}
+
+		if push {
+			devices, err := DevicesForUser(r.UserID)
+			if err != nil {
+				log.Println(err)
+			}
+			for j := range devices {
+				// Notifications sent by background jobs don't have an app scheme
+				// from a request, so the device's own scheme is used instead
+				scheme := appScheme
+				if len(scheme) == 0 {
+					scheme = devices[j].Scheme()
+				}
+				deliver(r.UserID, models.NotificationChannelPush, devices[j].Token, msg, scheme, deliverAt)
+			}
+		}
+		if email && len(r.Email) > 0 {
+			deliver(r.UserID, models.NotificationChannelEmail, r.Email, msg, appScheme, deliverAt)
+		}
+	}
+}
+
+// deliver sends a notification to a device token or email address, or holds
+// it as a pending notification if deliverAt is provided
+func deliver(userID uuid.UUID, channel string, to string, msg message, appScheme string, deliverAt *time.Time) {
+	if deliverAt == nil {
+		send(channel, to, msg.Title, msg.Body, msg.EntityName, msg.EntityID, appScheme)
+		return
+	}
+	pending := models.PendingNotification{
+		UserID:     userID,
+		Channel:    channel,
+		Recipient:  to,
+		Title:      msg.Title,
+		Body:       msg.Body,
+		EntityName: msg.EntityName,
+		EntityID:   msg.EntityID,
+		AppScheme:  appScheme,
+		DeliverAt:  *deliverAt,
+	}
+	if err := db.Manager.Create(&pending).Error; err != nil {
+		log.Println(err)
+	}
+}
+
+// send sends a notification through the channel provided right away
+func send(channel string, to string, title string, body string, entityName string, entityID string, appScheme string) {
+	if channel == models.NotificationChannelEmail {
+		if _, err := mailer.SendNotificationEmail(title, body, to); err != nil {
+			log.Println(err)
+		}
+		return
+	}
+	Send(title, body, to, entityName, entityID, appScheme)
+}
+
+// DeliverPendingNotifications sends the notifications that were held during
+// quiet hours which have since ended. Each notification is claimed by deleting
+// it, and is only sent by the caller whose delete removed it, so that it isn't
+// sent twice when another instance is delivering at the same time
+func DeliverPendingNotifications() error {
+	var pending []models.PendingNotification
+	query := db.Manager.
+		Where("deliver_at <= ?", time.Now()).
+		Order("deliver_at ASC").
+		Find(&pending).
+		Error
+	if err := query; err != nil {
+		return err
+	}
+	for i := range pending {
+		n := pending[i]
+		claim := db.Manager.Delete(&n)
+		if err := claim.Error; err != nil {
+			return err
+		}
+		if claim.RowsAffected != 1 {
+			continue
 		}
-		t = append(t, userTokens...)
+		send(n.Channel, n.Recipient, n.Title, n.Body, n.EntityName, n.EntityID, n.AppScheme)
 	}
-	return t, nil
+	return nil
 }
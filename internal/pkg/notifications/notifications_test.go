@@ -0,0 +1,76 @@
+package notifications
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type AnyTime struct{}
+
+// Match satisfies sqlmock.Argument interface
+func (a AnyTime) Match(v driver.Value) bool {
+	_, ok := v.(time.Time)
+	return ok
+}
+
+type Suite struct {
+	suite.Suite
+
+	DB   *gorm.DB
+	mock sqlmock.Sqlmock
+}
+
+func (s *Suite) SetupSuite() {
+	var (
+		dbMock *sql.DB
+		err    error
+	)
+
+	dbMock, s.mock, err = sqlmock.New()
+	require.NoError(s.T(), err)
+	s.DB, err = gorm.Open(postgres.New(postgres.Config{Conn: dbMock}), &gorm.Config{})
+	require.NoError(s.T(), err)
+
+	db.Manager = s.DB
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) TestDeliverPendingNotifications_NonePending() {
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"pending_notifications\"*").
+		WithArgs(AnyTime{}).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	err := DeliverPendingNotifications()
+	require.NoError(s.T(), err)
+}
+
+func (s *Suite) TestDeliverPendingNotifications_ClaimedByAnotherInstance() {
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"pending_notifications\"*").
+		WithArgs(AnyTime{}).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "channel", "recipient", "title", "body"}).
+			AddRow(uuid.NewV4(), "push", "token", "Trip 1", "Apples were added"))
+	// Another instance deleted the notification first, so it's left for that
+	// instance to send
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("^DELETE FROM \"pending_notifications\"*").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	err := DeliverPendingNotifications()
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
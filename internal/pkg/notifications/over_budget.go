@@ -0,0 +1,40 @@
+package notifications
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+)
+
+// OverBudget sends a notification to store users when the estimate for the
+// current trip pushes the month's spending at a store over its budget
+func OverBudget(storeID uuid.UUID, budget models.StoreBudget, projected int64) {
+	var store models.Store
+	if err := db.Manager.Select("id, name").Where("id = ?", storeID).First(&store).Error; err != nil {
+		log.Println(err)
+	}
+
+	msg := message{
+		Kind:  models.NotificationBudgetAlerts,
+		Title: "Over Budget",
+		Body: fmt.Sprintf(
+			"Your %v trip puts this month's spending at %v over its %v budget.",
+			store.Name,
+			formatAmount(projected-budget.MonthlyAmount, budget.Currency),
+			formatAmount(budget.MonthlyAmount, budget.Currency),
+		),
+		EntityName: "Store",
+		EntityID:   store.ID.String(),
+	}
+	// Budget alerts are triggered by the trip as a whole, so every store user receives them
+	notifyStoreUsers(uuid.Nil, store.ID, msg, "")
+}
+
+// formatAmount formats an amount in the minor unit of a currency for display,
+// assuming that the currency has two decimal places
+func formatAmount(amount int64, currency string) string {
+	return fmt.Sprintf("%d.%02d %v", amount/100, amount%100, currency)
+}
@@ -0,0 +1,89 @@
+package notifications
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// RetrieveQuietHours retrieves a user's quiet hours, or nil if they haven't set any
+func RetrieveQuietHours(userID uuid.UUID) (quietHours *models.QuietHours, err error) {
+	var q models.QuietHours
+	query := db.Manager.Where("user_id = ?", userID).First(&q).Error
+	if errors.Is(query, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err := query; err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// SetQuietHours creates or updates a user's quiet hours. Start and end are
+// times in the format HH:MM in the timezone provided
+func SetQuietHours(userID uuid.UUID, args map[string]interface{}) (quietHours models.QuietHours, err error) {
+	query := db.Manager.Where("user_id = ?", userID).First(&quietHours).Error
+	if err := query; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return quietHours, err
+	}
+	quietHours.UserID = userID
+
+	start := args["start"].(string)
+	end := args["end"].(string)
+	if _, err := time.Parse(models.QuietHoursLayout, start); err != nil {
+		return quietHours, errors.New("quiet hours start must be in the format HH:MM")
+	}
+	if _, err := time.Parse(models.QuietHoursLayout, end); err != nil {
+		return quietHours, errors.New("quiet hours end must be in the format HH:MM")
+	}
+	if start == end {
+		return quietHours, errors.New("quiet hours must start and end at different times")
+	}
+	timezone := args["timezone"].(string)
+	if _, err := time.LoadLocation(timezone); err != nil || len(timezone) == 0 {
+		return quietHours, errors.New("invalid timezone")
+	}
+	quietHours.Start = start
+	quietHours.End = end
+	quietHours.Timezone = timezone
+
+	if args["holdNotifications"] != nil {
+		hold := args["holdNotifications"].(bool)
+		quietHours.HoldNotifications = &hold
+	}
+	if quietHours.HoldNotifications == nil {
+		hold := true
+		quietHours.HoldNotifications = &hold
+	}
+
+	if err := db.Manager.Save(&quietHours).Error; err != nil {
+		return quietHours, err
+	}
+	return quietHours, nil
+}
+
+// DeleteQuietHours removes a user's quiet hours. Any notifications being held
+// are delivered by the next run of DeliverPendingNotifications
+func DeleteQuietHours(userID uuid.UUID) (quietHours models.QuietHours, err error) {
+	if err := db.Manager.Where("user_id = ?", userID).First(&quietHours).Error; err != nil {
+		return quietHours, errors.New("couldn't retrieve quiet hours")
+	}
+	err = db.Manager.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&quietHours).Error; err != nil {
+			return err
+		}
+		return tx.
+			Model(&models.PendingNotification{}).
+			Where("user_id = ?", userID).
+			UpdateColumn("deliver_at", time.Now()).
+			Error
+	})
+	if err != nil {
+		return quietHours, err
+	}
+	return quietHours, nil
+}
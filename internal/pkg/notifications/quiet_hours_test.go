@@ -0,0 +1,102 @@
+package notifications
+
+import (
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) TestRetrieveQuietHours_NotSet() {
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"quiet_hours\"*").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	quietHours, err := RetrieveQuietHours(userID)
+	require.NoError(s.T(), err)
+	assert.Nil(s.T(), quietHours)
+}
+
+func (s *Suite) TestSetQuietHours_InvalidStart() {
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"quiet_hours\"*").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	args := map[string]interface{}{"start": "10pm", "end": "07:00", "timezone": "America/Toronto"}
+	_, e := SetQuietHours(userID, args)
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "quiet hours start must be in the format HH:MM", e.Error())
+}
+
+func (s *Suite) TestSetQuietHours_InvalidTimezone() {
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"quiet_hours\"*").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	args := map[string]interface{}{"start": "22:00", "end": "07:00", "timezone": "Mars/Olympus_Mons"}
+	_, e := SetQuietHours(userID, args)
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "invalid timezone", e.Error())
+}
+
+func (s *Suite) TestSetQuietHours_Created() {
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"quiet_hours\"*").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^INSERT INTO \"quiet_hours\" (.+)$").
+		WithArgs(userID, "22:00", "07:00", "America/Toronto", false, AnyTime{}, AnyTime{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	args := map[string]interface{}{
+		"start":             "22:00",
+		"end":               "07:00",
+		"timezone":          "America/Toronto",
+		"holdNotifications": false,
+	}
+	quietHours, err := SetQuietHours(userID, args)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "22:00", quietHours.Start)
+	assert.Equal(s.T(), false, *quietHours.HoldNotifications)
+}
+
+func (s *Suite) TestQuietHoursUntil_Overnight() {
+	quietHours := models.QuietHours{Start: "22:00", End: "07:00", Timezone: "UTC"}
+
+	until, quiet, err := quietHours.Until(time.Date(2026, 1, 1, 23, 30, 0, 0, time.UTC))
+	require.NoError(s.T(), err)
+	assert.True(s.T(), quiet)
+	assert.Equal(s.T(), time.Date(2026, 1, 2, 7, 0, 0, 0, time.UTC), until.UTC())
+
+	until, quiet, err = quietHours.Until(time.Date(2026, 1, 1, 6, 59, 0, 0, time.UTC))
+	require.NoError(s.T(), err)
+	assert.True(s.T(), quiet)
+	assert.Equal(s.T(), time.Date(2026, 1, 1, 7, 0, 0, 0, time.UTC), until.UTC())
+
+	_, quiet, err = quietHours.Until(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(s.T(), err)
+	assert.False(s.T(), quiet)
+}
+
+func (s *Suite) TestQuietHoursUntil_Timezone() {
+	quietHours := models.QuietHours{Start: "13:00", End: "15:00", Timezone: "America/Toronto"}
+
+	// 18:30 UTC is 13:30 in Toronto in January
+	until, quiet, err := quietHours.Until(time.Date(2026, 1, 1, 18, 30, 0, 0, time.UTC))
+	require.NoError(s.T(), err)
+	assert.True(s.T(), quiet)
+	assert.Equal(s.T(), time.Date(2026, 1, 1, 20, 0, 0, 0, time.UTC), until.UTC())
+
+	_, quiet, err = quietHours.Until(time.Date(2026, 1, 1, 13, 30, 0, 0, time.UTC))
+	require.NoError(s.T(), err)
+	assert.False(s.T(), quiet)
+}
@@ -0,0 +1,45 @@
+package notifications
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ShoppingReminder sends a notification to store users the evening before a
+// trip's planned shopping date
+func ShoppingReminder(trip models.GroceryTrip) {
+	var store models.Store
+	if err := db.Manager.Select("id, name").Where("id = ?", trip.StoreID).First(&store).Error; err != nil {
+		log.Println(err)
+	}
+
+	var count int64
+	query := db.Manager.
+		Model(&models.Item{}).
+		Where("grocery_trip_id = ? AND completed = ?", trip.ID, false).
+		Count(&count).
+		Error
+	if err := query; err != nil {
+		log.Println(err)
+	}
+
+	body := fmt.Sprintf("Your %v trip is planned for tomorrow.", store.Name)
+	if count == 1 {
+		body = fmt.Sprintf("%v There's 1 item on the list.", body)
+	} else if count > 1 {
+		body = fmt.Sprintf("%v There are %d items on the list.", body, count)
+	}
+	msg := message{
+		Kind:       models.NotificationTripReminders,
+		Title:      "Shopping Tomorrow",
+		Body:       body,
+		EntityName: "Store",
+		EntityID:   store.ID.String(),
+	}
+	// Reminders aren't triggered by a user, so every store user receives them
+	notifyStoreUsers(uuid.Nil, store.ID, msg, "")
+}
@@ -0,0 +1,33 @@
+package notifications
+
+import (
+	"fmt"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+)
+
+// StoreArchived sends a notification to store users when the store
+// creator deletes (archives) a store
+func StoreArchived(user models.User, store models.Store, appScheme string) {
+	msg := message{
+		Kind:       models.NotificationStoreChanges,
+		Title:      "Store Deleted",
+		Body:       fmt.Sprintf("%v deleted the %v store. It can be restored for the next 30 days.", user.Name, store.Name),
+		EntityName: "Store",
+		EntityID:   store.ID.String(),
+	}
+	notifyStoreUsers(user.ID, store.ID, msg, appScheme)
+}
+
+// StoreRestored sends a notification to store users when an archived
+// store is restored by its creator
+func StoreRestored(user models.User, store models.Store, appScheme string) {
+	msg := message{
+		Kind:       models.NotificationStoreChanges,
+		Title:      "Store Restored",
+		Body:       fmt.Sprintf("%v restored the %v store. Your trips and items are back!", user.Name, store.Name),
+		EntityName: "Store",
+		EntityID:   store.ID.String(),
+	}
+	notifyStoreUsers(user.ID, store.ID, msg, appScheme)
+}
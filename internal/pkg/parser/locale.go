@@ -0,0 +1,75 @@
+package parser
+
+import "strings"
+
+// Locales that the parser understands number words and connectors for
+const (
+	LocaleEnglish = "en"
+	LocaleFrench  = "fr"
+	LocaleSpanish = "es"
+)
+
+// DefaultLocale is used when a locale isn't provided or isn't supported
+const DefaultLocale = LocaleEnglish
+
+// language holds the words the parser recognises in a locale
+type language struct {
+	// numbers maps number words to their value
+	numbers map[string]float64
+	// dozens are the words for a dozen, which multiply the number before them
+	// (or stand for 12 on their own, after an article)
+	dozens []string
+	// articles can come before a dozen word (i.e. "a dozen eggs")
+	articles []string
+	// connectors can come between an amount and the item (i.e. "2 lbs of apples")
+	connectors []string
+	// meal introduces the meal an item is for (i.e. "beef for tacos")
+	meal string
+}
+
+var languages = map[string]language{
+	LocaleEnglish: {
+		numbers: map[string]float64{
+			"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+			"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+		},
+		dozens:     []string{"dozen", "dozens"},
+		articles:   []string{"a", "one"},
+		connectors: []string{"of"},
+		meal:       "for",
+	},
+	LocaleFrench: {
+		numbers: map[string]float64{
+			"un": 1, "une": 1, "deux": 2, "trois": 3, "quatre": 4, "cinq": 5, "six": 6,
+			"sept": 7, "huit": 8, "neuf": 9, "dix": 10, "onze": 11, "douze": 12,
+		},
+		dozens:     []string{"douzaine", "douzaines"},
+		articles:   []string{"une"},
+		connectors: []string{"de", "d'", "des", "du"},
+		meal:       "pour",
+	},
+	LocaleSpanish: {
+		numbers: map[string]float64{
+			"un": 1, "uno": 1, "una": 1, "dos": 2, "tres": 3, "cuatro": 4, "cinco": 5, "seis": 6,
+			"siete": 7, "ocho": 8, "nueve": 9, "diez": 10, "once": 11, "doce": 12,
+		},
+		dozens:     []string{"docena", "docenas"},
+		articles:   []string{"una"},
+		connectors: []string{"de", "del"},
+		meal:       "para",
+	},
+}
+
+// NormaliseLocale returns the supported locale for a locale or an
+// Accept-Language header (i.e. fr-CA,fr;q=0.9,en;q=0.8 is fr), falling back
+// to DefaultLocale
+func NormaliseLocale(locale string) string {
+	for _, tag := range strings.Split(locale, ",") {
+		tag = strings.TrimSpace(strings.SplitN(tag, ";", 2)[0])
+		primary := strings.ToLower(strings.SplitN(strings.Replace(tag, "_", "-", 1), "-", 2)[0])
+		if _, ok := languages[primary]; ok {
+			return primary
+		}
+	}
+	return DefaultLocale
+}
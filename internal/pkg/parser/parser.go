@@ -0,0 +1,261 @@
+// Package parser turns what people type when adding an item to a list (i.e.
+// "2 lbs apples", "½ lb ham", "bread (whole wheat)", "beef for tacos") into
+// the item's name, amount, unit and notes
+package parser
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Result is a parsed item
+type Result struct {
+	// Name is the item's name with any amount, notes and meal taken out
+	Name string
+	// Amount is nil when the text didn't include an amount
+	Amount *float64
+	// Unit is the normalised unit of Amount, if it's a measured amount
+	Unit *string
+	// Notes are the parenthetical notes, followed by the meal phrase
+	Notes []string
+	// Meal is the meal the item is for (i.e. tacos in "beef for tacos")
+	Meal string
+}
+
+// Quantity returns the whole number quantity of the item, which is 1 when
+// the text didn't include an amount
+func (r Result) Quantity() int {
+	if r.Amount == nil {
+		return 1
+	}
+	return WholeQuantity(*r.Amount, r.Unit)
+}
+
+// DecimalQuantity returns the amount of the item when it can't be expressed
+// by Quantity alone: fractional or measured amounts
+func (r Result) DecimalQuantity() *float64 {
+	if r.Amount == nil {
+		return nil
+	}
+	if r.Unit == nil && *r.Amount == math.Trunc(*r.Amount) {
+		return nil
+	}
+	return r.Amount
+}
+
+// fractions maps unicode vulgar fractions to their value
+var fractions = map[string]float64{
+	"½": 0.5, "⅓": 1.0 / 3, "⅔": 2.0 / 3, "¼": 0.25, "¾": 0.75,
+	"⅕": 0.2, "⅖": 0.4, "⅗": 0.6, "⅘": 0.8, "⅙": 1.0 / 6, "⅚": 5.0 / 6,
+	"⅛": 0.125, "⅜": 0.375, "⅝": 0.625, "⅞": 0.875,
+}
+
+const (
+	fractionChars = "½⅓⅔¼¾⅕⅖⅗⅘⅙⅚⅛⅜⅝⅞"
+	// numberPattern matches mixed numbers (1 1/2, 1½), fractions (1/2, ½)
+	// and decimals (1.5, 1,5)
+	numberPattern = `(\d+\s+\d+/\d+|\d+\s*[` + fractionChars + `]|\d+/\d+|[` + fractionChars + `]|\d+(?:[.,]\d+)?)`
+)
+
+var (
+	whitespace    = regexp.MustCompile(`\s+`)
+	parenthetical = regexp.MustCompile(`\s*\(([^()]*)\)`)
+	multiplier    = regexp.MustCompile(`(?i)^(.+?)\s+[x×]\s?(\d+)$`)
+	leadingNumber = regexp.MustCompile(`^` + numberPattern)
+	leadingTimes  = regexp.MustCompile(`(?i)^\s*[x×]\s+`)
+	unitPattern   = `(` + strings.Join(quoteAll(unitNames()), "|") + `)\.?`
+	leadingUnit   = regexp.MustCompile(`(?i)^\s*` + unitPattern + `(\s+|$)`)
+	trailingUnit  = regexp.MustCompile(`(?i)^(.+?)\s+` + numberPattern + `\s*` + unitPattern + `$`)
+)
+
+// Parse parses the text of an item in the locale provided (see
+// NormaliseLocale). Text that doesn't parse to a name is returned as the name
+func Parse(text string, locale string) Result {
+	lang := languages[NormaliseLocale(locale)]
+	text = strings.TrimSpace(whitespace.ReplaceAllString(text, " "))
+	result := Result{Name: text}
+
+	rest := text
+	for _, match := range parenthetical.FindAllStringSubmatch(rest, -1) {
+		if note := strings.TrimSpace(match[1]); note != "" {
+			result.Notes = append(result.Notes, note)
+		}
+	}
+	rest = strings.TrimSpace(parenthetical.ReplaceAllString(rest, ""))
+
+	// A multiplier comes last (i.e. "beef for tacos x 2")
+	var amount *float64
+	var unit *string
+	if match := multiplier.FindStringSubmatch(rest); match != nil {
+		value, _ := strconv.ParseFloat(match[2], 64)
+		rest, amount = match[1], &value
+	}
+
+	if name, meal, phrase, ok := parseMeal(rest, lang); ok {
+		rest = name
+		result.Meal = meal
+		result.Notes = append(result.Notes, phrase)
+	}
+
+	if amount == nil {
+		rest, amount, unit = parseAmount(rest, lang)
+	}
+	if rest == "" {
+		return Result{Name: text}
+	}
+	result.Name = rest
+	result.Amount = amount
+	result.Unit = unit
+	return result
+}
+
+// parseMeal splits the meal phrase off the end of text (i.e. "for tacos")
+func parseMeal(text string, lang language) (name string, meal string, phrase string, ok bool) {
+	words := strings.Split(text, " ")
+	// The meal word can't be the first word, and needs a meal after it
+	for i := len(words) - 2; i > 0; i-- {
+		if strings.ToLower(words[i]) == lang.meal {
+			name = strings.Join(words[:i], " ")
+			meal = strings.Join(words[i+1:], " ")
+			return name, meal, strings.Join(words[i:], " "), true
+		}
+	}
+	return text, "", "", false
+}
+
+// parseAmount finds an amount at the start ("2 lbs apples", "3x eggs",
+// "a dozen eggs") or end ("apples 500g") of text, and returns the rest of the
+// text as the name
+func parseAmount(text string, lang language) (name string, amount *float64, unit *string) {
+	if value, rest, ok := leadingAmount(text, lang); ok {
+		if match := leadingTimes.FindStringIndex(rest); match != nil {
+			return rest[match[1]:], round(value), nil
+		}
+		if match := leadingUnit.FindStringSubmatch(rest); match != nil {
+			normalised := units[strings.ToLower(match[1])]
+			return trimConnector(rest[len(match[0]):], lang), round(value), &normalised
+		}
+		if strings.HasPrefix(rest, " ") {
+			return trimConnector(strings.TrimSpace(rest), lang), round(value), nil
+		}
+	}
+
+	if match := trailingUnit.FindStringSubmatch(text); match != nil {
+		if value, ok := parseNumber(match[2]); ok {
+			normalised := units[strings.ToLower(match[3])]
+			return match[1], round(value), &normalised
+		}
+	}
+	return text, nil, nil
+}
+
+// leadingAmount parses a number (or number word, or dozen) at the start of
+// text and returns its value and the rest of the text
+func leadingAmount(text string, lang language) (value float64, rest string, ok bool) {
+	if match := leadingNumber.FindString(text); match != "" {
+		value, ok = parseNumber(match)
+		rest = text[len(match):]
+	} else {
+		word := strings.SplitN(text, " ", 2)[0]
+		lower := strings.ToLower(word)
+		if number, found := lang.numbers[lower]; found {
+			value, ok = number, true
+			rest = text[len(word):]
+		}
+		if contains(lang.articles, lower) && contains(lang.dozens, strings.ToLower(nextWord(text[len(word):]))) {
+			value, ok = 1, true
+			rest = text[len(word):]
+		}
+	}
+	if !ok || value <= 0 {
+		return 0, text, false
+	}
+	// "2 dozen eggs" is 24 eggs
+	if dozen := nextWord(rest); contains(lang.dozens, strings.ToLower(dozen)) {
+		value *= 12
+		rest = strings.TrimPrefix(strings.TrimSpace(rest), dozen)
+		if strings.TrimSpace(rest) == "" {
+			return 0, text, false
+		}
+		rest = " " + trimConnector(strings.TrimSpace(rest), lang)
+	}
+	return value, rest, true
+}
+
+// parseNumber parses the value of a number matched by numberPattern
+func parseNumber(text string) (float64, bool) {
+	text = strings.TrimSpace(text)
+	for fraction, value := range fractions {
+		if strings.HasSuffix(text, fraction) {
+			whole := strings.TrimSpace(strings.TrimSuffix(text, fraction))
+			if whole == "" {
+				return value, true
+			}
+			n, err := strconv.ParseFloat(whole, 64)
+			return n + value, err == nil
+		}
+	}
+	if parts := strings.Fields(text); len(parts) == 2 {
+		whole, ok := parseNumber(parts[0])
+		fraction, ok2 := parseNumber(parts[1])
+		return whole + fraction, ok && ok2
+	}
+	if parts := strings.SplitN(text, "/", 2); len(parts) == 2 {
+		numerator, err := strconv.ParseFloat(parts[0], 64)
+		denominator, err2 := strconv.ParseFloat(parts[1], 64)
+		if err != nil || err2 != nil || denominator == 0 {
+			return 0, false
+		}
+		return numerator / denominator, true
+	}
+	value, err := strconv.ParseFloat(strings.Replace(text, ",", ".", 1), 64)
+	return value, err == nil
+}
+
+// trimConnector removes a connector word from the start of the name (i.e.
+// "of" in "2 lbs of apples", or "d'" in "2 kg d'oranges")
+func trimConnector(name string, lang language) string {
+	lower := strings.ToLower(name)
+	for _, connector := range lang.connectors {
+		if strings.HasSuffix(connector, "'") && strings.HasPrefix(lower, connector) && len(name) > len(connector) {
+			return name[len(connector):]
+		}
+		if strings.HasPrefix(lower, connector+" ") && len(name) > len(connector)+1 {
+			return strings.TrimSpace(name[len(connector)+1:])
+		}
+	}
+	return name
+}
+
+// round rounds an amount to the precision that items store (3 decimal places)
+func round(value float64) *float64 {
+	rounded := math.Round(value*1000) / 1000
+	return &rounded
+}
+
+func nextWord(text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+func contains(words []string, word string) bool {
+	for _, w := range words {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+func quoteAll(words []string) []string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(word)
+	}
+	return quoted
+}
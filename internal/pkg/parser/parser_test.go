@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// TestParse_Golden parses every item in testdata/items.txt and compares the
+// results to testdata/items.golden
+func TestParse_Golden(t *testing.T) {
+	file, err := os.Open(filepath.Join("testdata", "items.txt"))
+	require.NoError(t, err)
+	defer file.Close()
+
+	var output strings.Builder
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "|", 2)
+		require.Len(t, parts, 2, "malformed line: %q", line)
+		locale, text := strings.TrimSpace(parts[0]), strings.TrimPrefix(parts[1], " ")
+		fmt.Fprintf(&output, "%s | %q\n\t%s\n", locale, text, describe(Parse(text, locale)))
+	}
+	require.NoError(t, scanner.Err())
+
+	golden := filepath.Join("testdata", "items.golden")
+	if *update {
+		require.NoError(t, os.WriteFile(golden, []byte(output.String()), 0644))
+	}
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), output.String())
+}
+
+func TestNormaliseLocale(t *testing.T) {
+	assert.Equal(t, "fr", NormaliseLocale("fr"))
+	assert.Equal(t, "fr", NormaliseLocale("fr_CA"))
+	assert.Equal(t, "es", NormaliseLocale("de-DE,es;q=0.8"))
+	assert.Equal(t, DefaultLocale, NormaliseLocale("de"))
+	assert.Equal(t, DefaultLocale, NormaliseLocale(""))
+}
+
+func TestWholeQuantity(t *testing.T) {
+	kg := "kg"
+	assert.Equal(t, 1, WholeQuantity(2.5, &kg))
+	assert.Equal(t, 3, WholeQuantity(2.5, nil))
+	assert.Equal(t, 1, WholeQuantity(0.25, nil))
+}
+
+// describe formats a result for the golden file
+func describe(result Result) string {
+	description := fmt.Sprintf("name=%q quantity=%d", result.Name, result.Quantity())
+	if amount := result.DecimalQuantity(); amount != nil {
+		description += fmt.Sprintf(" decimalQuantity=%g", *amount)
+	}
+	if result.Unit != nil {
+		description += fmt.Sprintf(" unit=%s", *result.Unit)
+	}
+	if len(result.Notes) > 0 {
+		description += fmt.Sprintf(" notes=%q", result.Notes)
+	}
+	if result.Meal != "" {
+		description += fmt.Sprintf(" meal=%q", result.Meal)
+	}
+	return description
+}
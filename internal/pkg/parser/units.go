@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// units maps the ways a unit can be written (in any supported locale) to its
+// normalised form
+var units = map[string]string{
+	"lb": "lb", "lbs": "lb", "pound": "lb", "pounds": "lb", "livre": "lb", "livres": "lb", "libra": "lb", "libras": "lb",
+	"oz": "oz", "ounce": "oz", "ounces": "oz", "onces": "oz", "onza": "oz", "onzas": "oz",
+	"kg": "kg", "kgs": "kg", "kilo": "kg", "kilos": "kg", "kilogram": "kg", "kilograms": "kg", "kilogramme": "kg", "kilogrammes": "kg", "kilogramo": "kg", "kilogramos": "kg",
+	"g": "g", "gr": "g", "gram": "g", "grams": "g", "gramme": "g", "grammes": "g", "gramo": "g", "gramos": "g",
+	"l": "l", "litre": "l", "litres": "l", "liter": "l", "liters": "l", "litro": "l", "litros": "l",
+	"ml": "ml", "millilitre": "ml", "millilitres": "ml", "milliliter": "ml", "milliliters": "ml", "mililitro": "ml", "mililitros": "ml",
+}
+
+// unitNames returns the ways units can be written, longest first so that
+// alternations prefer "lbs" over "lb"
+func unitNames() []string {
+	names := make([]string, 0, len(units))
+	for name := range units {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// NormaliseUnit returns the normalised form of a unit, and whether it's one
+// that items can be measured in
+func NormaliseUnit(unit string) (string, bool) {
+	normalised, ok := units[strings.ToLower(strings.TrimSpace(unit))]
+	return normalised, ok
+}
+
+// WholeQuantity approximates an amount as a whole number for older app
+// versions. Measured amounts count as a single item (500 g of flour is one
+// thing to pick up), and other amounts are rounded up
+func WholeQuantity(amount float64, unit *string) int {
+	if unit != nil && *unit != "" {
+		return 1
+	}
+	quantity := int(math.Ceil(amount))
+	if quantity < 1 {
+		return 1
+	}
+	return quantity
+}
@@ -0,0 +1,149 @@
+package photos
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"strings"
+
+	// Register the GIF decoder for image.Decode
+	_ "image/gif"
+)
+
+// MaxSize is the largest photo (in bytes) that can be uploaded
+const MaxSize = 10 << 20
+
+// MaxDimension is the widest or tallest photo (in pixels) that can be
+// uploaded, which keeps decoding it for a thumbnail within a sane amount
+// of memory
+const MaxDimension = 6000
+
+// ThumbnailSize is the width or height (whichever is larger) of thumbnails
+const ThumbnailSize = 320
+
+// contentTypes are the types of image that can be uploaded, and the
+// extension they're stored with
+var contentTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+// processed is a validated photo and its thumbnail
+type processed struct {
+	data                 []byte
+	contentType          string
+	width                int
+	height               int
+	thumbnail            []byte
+	thumbnailContentType string
+}
+
+// process validates the data and content type of an uploaded photo, and
+// generates its thumbnail
+func process(data []byte, contentType string) (*processed, error) {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if contentType == "image/jpg" {
+		contentType = "image/jpeg"
+	}
+	if _, ok := contentTypes[contentType]; !ok {
+		return nil, errors.New("photo must be a JPEG, PNG or GIF image")
+	}
+	if len(data) == 0 {
+		return nil, errors.New("photo is empty")
+	}
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("photo must be smaller than %d MB", MaxSize>>20)
+	}
+	if http.DetectContentType(data) != contentType {
+		return nil, errors.New("photo does not match its content type")
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("photo could not be read")
+	}
+	if config.Width > MaxDimension || config.Height > MaxDimension {
+		return nil, fmt.Errorf("photo must be at most %dx%d pixels", MaxDimension, MaxDimension)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("photo could not be read")
+	}
+
+	photo := &processed{
+		data:        data,
+		contentType: contentType,
+		width:       config.Width,
+		height:      config.Height,
+	}
+	var thumbnail bytes.Buffer
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&thumbnail, resize(img, ThumbnailSize), &jpeg.Options{Quality: 80})
+		photo.thumbnailContentType = "image/jpeg"
+	} else {
+		err = png.Encode(&thumbnail, resize(img, ThumbnailSize))
+		photo.thumbnailContentType = "image/png"
+	}
+	if err != nil {
+		return nil, err
+	}
+	photo.thumbnail = thumbnail.Bytes()
+	return photo, nil
+}
+
+// resize scales an image down so that its larger side is size pixels,
+// averaging the source pixels that fall into each destination pixel. Images
+// that are already small enough are copied as they are
+func resize(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	dstWidth, dstHeight := width, height
+	if width > size || height > size {
+		if width >= height {
+			dstWidth, dstHeight = size, max(1, height*size/width)
+		} else {
+			dstWidth, dstHeight = max(1, width*size/height), size
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		y0 := bounds.Min.Y + y*height/dstHeight
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*height/dstHeight)
+		for x := 0; x < dstWidth; x++ {
+			x0 := bounds.Min.X + x*width/dstWidth
+			x1 := max(x0+1, bounds.Min.X+(x+1)*width/dstWidth)
+
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					count++
+				}
+			}
+			// Average the premultiplied colour, then unpremultiply it
+			pixel := color.RGBA64{
+				R: uint16(r / count),
+				G: uint16(g / count),
+				B: uint16(b / count),
+				A: uint16(a / count),
+			}
+			dst.Set(x, y, color.NRGBAModel.Convert(pixel))
+		}
+	}
+	return dst
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,279 @@
+// Package photos handles photos uploaded for items and recipes. Each item or
+// recipe has at most one photo, which is stored (along with a thumbnail) in
+// the storage backend and served through signed, expiring URLs
+package photos
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/storage"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// URLExpiry is how long the URLs of photos and thumbnails are valid for
+const URLExpiry = time.Hour
+
+// purgeBatchSize is how many orphaned photos are purged per run
+const purgeBatchSize = 500
+
+// AttachItemPhoto uploads a photo for an item, replacing its existing photo.
+// The user must belong to the store of the item's trip
+func AttachItemPhoto(userID uuid.UUID, itemID interface{}, data string, contentType string) (item models.Item, err error) {
+	if item, err = findItem(userID, itemID); err != nil {
+		return item, err
+	}
+	if _, err := attach(userID, models.PhotoOwnerItem, item.ID, data, contentType); err != nil {
+		return item, err
+	}
+	return item, nil
+}
+
+// RemoveItemPhoto removes the photo of an item
+func RemoveItemPhoto(userID uuid.UUID, itemID interface{}) (item models.Item, err error) {
+	if item, err = findItem(userID, itemID); err != nil {
+		return item, err
+	}
+	if err := remove(models.PhotoOwnerItem, item.ID); err != nil {
+		return item, err
+	}
+	return item, nil
+}
+
+// AttachRecipeImage uploads an image for a recipe, replacing its existing
+// image. Only the user who created the recipe can upload its image
+func AttachRecipeImage(userID uuid.UUID, recipeID interface{}, data string, contentType string) (recipe models.Recipe, err error) {
+	if recipe, err = findRecipe(userID, recipeID); err != nil {
+		return recipe, err
+	}
+	if _, err := attach(userID, models.PhotoOwnerRecipe, recipe.ID, data, contentType); err != nil {
+		return recipe, err
+	}
+	return recipe, nil
+}
+
+// RemoveRecipeImage removes the uploaded image of a recipe
+func RemoveRecipeImage(userID uuid.UUID, recipeID interface{}) (recipe models.Recipe, err error) {
+	if recipe, err = findRecipe(userID, recipeID); err != nil {
+		return recipe, err
+	}
+	if err := remove(models.PhotoOwnerRecipe, recipe.ID); err != nil {
+		return recipe, err
+	}
+	return recipe, nil
+}
+
+// RetrievePhoto retrieves the photo of an item or recipe, or nil if it
+// doesn't have one
+func RetrievePhoto(ownerType string, ownerID uuid.UUID) (*models.Photo, error) {
+	var photo models.Photo
+	query := db.Manager.
+		Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).
+		Order("created_at DESC").
+		First(&photo).
+		Error
+	if errors.Is(query, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err := query; err != nil {
+		return nil, err
+	}
+	return &photo, nil
+}
+
+// URL returns a signed URL for a stored photo or thumbnail key
+func URL(key string) (string, error) {
+	backend, err := storage.Default()
+	if err != nil {
+		return "", err
+	}
+	return backend.URL(key, URLExpiry)
+}
+
+// PurgeOrphanedPhotos deletes the photos of items and recipes that were
+// deleted more than models.PhotoRetentionPeriod ago (or no longer exist)
+// from the storage backend and the database
+func PurgeOrphanedPhotos() (err error) {
+	deletedBefore := time.Now().Add(-models.PhotoRetentionPeriod)
+	var orphaned []models.Photo
+	query := db.Manager.
+		Where("owner_type = ? AND NOT EXISTS (SELECT 1 FROM items WHERE items.id = photos.owner_id AND (items.deleted_at IS NULL OR items.deleted_at > ?))", models.PhotoOwnerItem, deletedBefore).
+		Or("owner_type = ? AND NOT EXISTS (SELECT 1 FROM recipes WHERE recipes.id = photos.owner_id AND (recipes.deleted_at IS NULL OR recipes.deleted_at > ?))", models.PhotoOwnerRecipe, deletedBefore).
+		Limit(purgeBatchSize).
+		Find(&orphaned).
+		Error
+	if err := query; err != nil {
+		return err
+	}
+	if len(orphaned) == 0 {
+		return nil
+	}
+
+	backend, err := storage.Default()
+	if err != nil {
+		return err
+	}
+	var purgedIDs []uuid.UUID
+	for _, photo := range orphaned {
+		// Photos whose blobs couldn't be deleted are left for the next run
+		if err := deleteBlobs(backend, photo); err != nil {
+			log.Printf("[photos] couldn't delete blobs of photo %v: %v\n", photo.ID, err)
+			continue
+		}
+		purgedIDs = append(purgedIDs, photo.ID)
+	}
+	if len(purgedIDs) == 0 {
+		return nil
+	}
+	return db.Manager.Where("id IN (?)", purgedIDs).Delete(&models.Photo{}).Error
+}
+
+// findItem finds an item in a trip of a store that the user belongs to
+func findItem(userID uuid.UUID, itemID interface{}) (item models.Item, err error) {
+	query := db.Manager.
+		Select("items.*").
+		Joins("INNER JOIN grocery_trips ON grocery_trips.id = items.grocery_trip_id").
+		Joins("INNER JOIN store_users ON store_users.store_id = grocery_trips.store_id").
+		Where("items.id = ? AND store_users.user_id = ? AND store_users.active = ?", itemID, userID, true).
+		First(&item).
+		Error
+	if err := query; err != nil {
+		return item, errors.New("item not found")
+	}
+	return item, nil
+}
+
+// findRecipe finds a recipe created by the user
+func findRecipe(userID uuid.UUID, recipeID interface{}) (recipe models.Recipe, err error) {
+	query := db.Manager.
+		Preload("Ingredients").
+		Where("id = ? AND user_id = ?", recipeID, userID).
+		First(&recipe).
+		Error
+	if err := query; err != nil {
+		return recipe, errors.New("recipe not found")
+	}
+	return recipe, nil
+}
+
+// attach validates and stores an uploaded photo and its thumbnail, and
+// replaces the existing photo of its owner
+func attach(userID uuid.UUID, ownerType string, ownerID uuid.UUID, data string, contentType string) (*models.Photo, error) {
+	decoded, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	processed, err := process(decoded, contentType)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := storage.Default()
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.NewV4()
+	photo := models.Photo{
+		ID:           id,
+		UserID:       userID,
+		OwnerType:    ownerType,
+		OwnerID:      ownerID,
+		Key:          fmt.Sprintf("photos/%s.%s", id, contentTypes[processed.contentType]),
+		ThumbnailKey: fmt.Sprintf("photos/%s_thumb.%s", id, contentTypes[processed.thumbnailContentType]),
+		ContentType:  processed.contentType,
+		Size:         int64(len(processed.data)),
+		Width:        processed.width,
+		Height:       processed.height,
+	}
+	if err := backend.Put(photo.Key, processed.data, processed.contentType); err != nil {
+		return nil, err
+	}
+	if err := backend.Put(photo.ThumbnailKey, processed.thumbnail, processed.thumbnailContentType); err != nil {
+		logBlobError(deleteBlobs(backend, photo))
+		return nil, err
+	}
+
+	var replaced []models.Photo
+	err = db.Manager.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).Find(&replaced).Error; err != nil {
+			return err
+		}
+		if len(replaced) > 0 {
+			if err := tx.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).Delete(&models.Photo{}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&photo).Error
+	})
+	if err != nil {
+		logBlobError(deleteBlobs(backend, photo))
+		return nil, err
+	}
+	for _, old := range replaced {
+		logBlobError(deleteBlobs(backend, old))
+	}
+	return &photo, nil
+}
+
+// remove deletes the photo of an item or recipe
+func remove(ownerType string, ownerID uuid.UUID) error {
+	var removed []models.Photo
+	if err := db.Manager.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).Find(&removed).Error; err != nil {
+		return err
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	if err := db.Manager.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).Delete(&models.Photo{}).Error; err != nil {
+		return err
+	}
+	backend, err := storage.Default()
+	if err != nil {
+		return err
+	}
+	for _, photo := range removed {
+		logBlobError(deleteBlobs(backend, photo))
+	}
+	return nil
+}
+
+// decode decodes base64 photo data, which may be a data URL
+// (i.e. data:image/png;base64,...)
+func decode(data string) ([]byte, error) {
+	if strings.HasPrefix(data, "data:") {
+		if comma := strings.Index(data, ","); comma != -1 {
+			data = data[comma+1:]
+		}
+	}
+	if base64.StdEncoding.DecodedLen(len(data)) > MaxSize+3 {
+		return nil, fmt.Errorf("photo must be smaller than %d MB", MaxSize>>20)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	if err != nil {
+		return nil, errors.New("photo data must be base64 encoded")
+	}
+	return decoded, nil
+}
+
+// deleteBlobs deletes a photo and its thumbnail from the storage backend
+func deleteBlobs(backend storage.Backend, photo models.Photo) error {
+	if err := backend.Delete(photo.Key); err != nil {
+		return err
+	}
+	return backend.Delete(photo.ThumbnailKey)
+}
+
+// logBlobError logs (but otherwise ignores) errors deleting blobs that are
+// no longer needed. They're left behind in the storage backend
+func logBlobError(err error) {
+	if err != nil {
+		log.Printf("[photos] couldn't delete blobs: %v\n", err)
+	}
+}
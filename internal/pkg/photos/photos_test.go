@@ -0,0 +1,156 @@
+package photos
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/storage"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type Suite struct {
+	suite.Suite
+
+	DB   *gorm.DB
+	mock sqlmock.Sqlmock
+}
+
+func (s *Suite) SetupSuite() {
+	var (
+		dbMock *sql.DB
+		err    error
+	)
+
+	dbMock, s.mock, err = sqlmock.New()
+	require.NoError(s.T(), err)
+	s.DB, err = gorm.Open(postgres.New(postgres.Config{Conn: dbMock}), &gorm.Config{})
+	require.NoError(s.T(), err)
+
+	db.Manager = s.DB
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+// testPNG returns a base64 encoded PNG of the size provided
+func testPNG(width int, height int) string {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.NRGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func (s *Suite) TestAttachItemPhoto_ItemNotFound() {
+	itemID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	_, e := AttachItemPhoto(userID, itemID, testPNG(10, 10), "image/png")
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "item not found", e.Error())
+}
+
+func (s *Suite) TestAttachItemPhoto_InvalidContentType() {
+	itemID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
+
+	_, e := AttachItemPhoto(userID, itemID, testPNG(10, 10), "image/webp")
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "photo must be a JPEG, PNG or GIF image", e.Error())
+}
+
+func (s *Suite) TestAttachItemPhoto_ContentTypeMismatch() {
+	itemID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
+
+	_, e := AttachItemPhoto(userID, itemID, testPNG(10, 10), "image/jpeg")
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "photo does not match its content type", e.Error())
+}
+
+func (s *Suite) TestAttachItemPhoto_TooLarge() {
+	itemID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
+
+	_, e := AttachItemPhoto(userID, itemID, testPNG(MaxDimension+1, 1), "image/png")
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "photo must be at most 6000x6000 pixels", e.Error())
+}
+
+func (s *Suite) TestAttachRecipeImage_ReplacesExistingImage() {
+	root := s.T().TempDir()
+	storage.SetDefault(&storage.Local{Root: root, SigningKey: []byte("secret")})
+	oldKey := filepath.Join(root, "photos", "old.png")
+	require.NoError(s.T(), os.MkdirAll(filepath.Dir(oldKey), 0755))
+	require.NoError(s.T(), os.WriteFile(oldKey, []byte("old"), 0644))
+
+	recipeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"recipes\"*").
+		WithArgs(recipeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(recipeID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"recipe_ingredients\"*").
+		WithArgs(recipeID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"photos\"*").
+		WithArgs("recipe", recipeID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "key", "thumbnail_key"}).AddRow(uuid.NewV4(), "photos/old.png", "photos/old_thumb.png"))
+	s.mock.ExpectExec("^DELETE FROM \"photos\"*").
+		WithArgs("recipe", recipeID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectQuery("^INSERT INTO \"photos\" (.+)$").
+		WithArgs(userID, "recipe", recipeID, sqlmock.AnyArg(), sqlmock.AnyArg(), "image/png", sqlmock.AnyArg(), 640, 480, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	recipe, err := AttachRecipeImage(userID, recipeID, testPNG(640, 480), "image/png")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), recipeID, recipe.ID)
+
+	_, err = os.Stat(oldKey)
+	assert.True(s.T(), os.IsNotExist(err))
+	stored, err := filepath.Glob(filepath.Join(root, "photos", "*.png"))
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), stored, 2)
+}
+
+func (s *Suite) TestResize() {
+	thumbnail := resize(image.NewNRGBA(image.Rect(0, 0, 640, 480)), ThumbnailSize)
+	assert.Equal(s.T(), image.Rect(0, 0, 320, 240), thumbnail.Bounds())
+
+	thumbnail = resize(image.NewNRGBA(image.Rect(0, 0, 100, 1000)), ThumbnailSize)
+	assert.Equal(s.T(), image.Rect(0, 0, 32, 320), thumbnail.Bounds())
+
+	thumbnail = resize(image.NewNRGBA(image.Rect(0, 0, 50, 40)), ThumbnailSize)
+	assert.Equal(s.T(), image.Rect(0, 0, 50, 40), thumbnail.Bounds())
+}
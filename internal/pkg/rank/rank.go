@@ -0,0 +1,170 @@
+// Package rank generates rank keys: strings that order things by comparing
+// them byte by byte, where a key can always be generated between any two
+// others. Moving something only means giving it a new key between its new
+// neighbours, so nothing else has to be renumbered.
+//
+// Keys are base 36 fractions (the digits after the point), written without
+// trailing zeros so that there is always room before a key.
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+// Digits are the digits keys are made of, in the order they sort in
+const Digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// MaxLength is the length past which keys are considered too long, and the
+// things they order should be given new keys with Spread
+const MaxLength = 16
+
+// ErrInvalidRange is returned when the keys to generate a key between are out
+// of order (or are the same key)
+var ErrInvalidRange = errors.New("rank: before must sort before after")
+
+// ErrInvalidKey is returned when a key isn't a valid rank key
+var ErrInvalidKey = errors.New("rank: invalid key")
+
+// Between returns a key that sorts after before and before after. An empty
+// before means the start of the keys, and an empty after means the end
+func Between(before string, after string) (string, error) {
+	if err := validate(before); err != nil {
+		return "", err
+	}
+	if err := validate(after); err != nil {
+		return "", err
+	}
+	if after != "" && before >= after {
+		return "", ErrInvalidRange
+	}
+	return midpoint(before, after), nil
+}
+
+// BetweenN returns n keys in order between before and after, spread out so
+// that they're as short as they can be
+func BetweenN(before string, after string, n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	key, err := Between(before, after)
+	if err != nil {
+		return nil, err
+	}
+	left, err := BetweenN(before, key, n/2)
+	if err != nil {
+		return nil, err
+	}
+	right, err := BetweenN(key, after, n-1-n/2)
+	if err != nil {
+		return nil, err
+	}
+	keys := append(left, key)
+	return append(keys, right...), nil
+}
+
+// Spread returns n keys in order, evenly spaced and all the same length
+// (before trailing zeros are dropped), to give things new keys from scratch
+func Spread(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	width, space := 1, int64(len(Digits))
+	for space <= int64(n) {
+		width++
+		space *= int64(len(Digits))
+	}
+	step := space / int64(n+1)
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = format(step*int64(i+1), width)
+	}
+	return keys
+}
+
+// TooLong returns whether any of the keys is longer than MaxLength
+func TooLong(keys ...string) bool {
+	for _, key := range keys {
+		if len(key) > MaxLength {
+			return true
+		}
+	}
+	return false
+}
+
+// midpoint returns a key between a and b (where an empty b is the end of the
+// keys), preferring the shortest such key
+func midpoint(a string, b string) string {
+	if b != "" {
+		// Keep the digits a and b have in common, and find a key between
+		// what follows them
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + midpoint(rest, b[n:])
+		}
+	}
+
+	low := 0
+	if a != "" {
+		low = strings.IndexByte(Digits, a[0])
+	}
+	high := len(Digits)
+	if b != "" {
+		high = strings.IndexByte(Digits, b[0])
+	}
+	if high-low > 1 {
+		return string(Digits[(low+high+1)/2])
+	}
+	// The first digits are consecutive, so the key starts with a's first digit
+	// (or b's, when b has more digits that can be dropped)
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(Digits[low]) + midpoint(rest, "")
+}
+
+// digitAt returns the digit of key at i, which is zero past its end
+func digitAt(key string, i int) byte {
+	if i < len(key) {
+		return key[i]
+	}
+	return Digits[0]
+}
+
+// format writes value as a key of width digits, dropping trailing zeros
+func format(value int64, width int) string {
+	key := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		key[i] = Digits[value%int64(len(Digits))]
+		value /= int64(len(Digits))
+	}
+	return strings.TrimRight(string(key), Digits[:1])
+}
+
+// validate checks that key is made of Digits and has no trailing zeros. The
+// empty key is valid, as it stands for the start or end of the keys
+func validate(key string) error {
+	if key == "" {
+		return nil
+	}
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(Digits, key[i]) < 0 {
+			return ErrInvalidKey
+		}
+	}
+	if key[len(key)-1] == Digits[0] {
+		return ErrInvalidKey
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package rank
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		before string
+		after  string
+		key    string
+	}{
+		{"", "", "i"},
+		{"", "1", "0i"},
+		{"", "i", "9"},
+		{"z", "", "zi"},
+		{"a", "b", "ai"},
+		{"a", "c", "b"},
+		{"p", "q", "pi"},
+		{"a", "a1", "a0i"},
+		{"ai", "b", "ar"},
+		{"9", "i", "e"},
+	}
+	for _, test := range tests {
+		key, err := Between(test.before, test.after)
+		require.NoError(t, err)
+		assert.Equal(t, test.key, key, "between %q and %q", test.before, test.after)
+	}
+}
+
+func TestBetween_Invalid(t *testing.T) {
+	_, err := Between("b", "a")
+	assert.Equal(t, ErrInvalidRange, err)
+	_, err = Between("a", "a")
+	assert.Equal(t, ErrInvalidRange, err)
+	_, err = Between("a0", "")
+	assert.Equal(t, ErrInvalidKey, err)
+	_, err = Between("", "A")
+	assert.Equal(t, ErrInvalidKey, err)
+}
+
+// TestBetween_RepeatedInserts inserts keys at random places among the keys so
+// far, and checks the keys stay in order and distinct
+func TestBetween_RepeatedInserts(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	var keys []string
+	for i := 0; i < 1000; i++ {
+		at := random.Intn(len(keys) + 1)
+		before, after := "", ""
+		if at > 0 {
+			before = keys[at-1]
+		}
+		if at < len(keys) {
+			after = keys[at]
+		}
+		key, err := Between(before, after)
+		require.NoError(t, err)
+		keys = append(keys[:at], append([]string{key}, keys[at:]...)...)
+	}
+	assertOrdered(t, keys)
+}
+
+// TestBetween_InsertsAtTop inserts keys at the top repeatedly, which is what
+// adding items does, and checks how many inserts it takes for the keys to get
+// too long (i.e. how often a category is rebalanced)
+func TestBetween_InsertsAtTop(t *testing.T) {
+	first, inserts := "", 0
+	for !TooLong(first) {
+		key, err := Between("", first)
+		require.NoError(t, err)
+		if first != "" {
+			require.Less(t, key, first)
+		}
+		first = key
+		inserts++
+	}
+	assert.GreaterOrEqual(t, inserts, 75)
+}
+
+func TestBetweenN(t *testing.T) {
+	keys, err := BetweenN("a", "b", 200)
+	require.NoError(t, err)
+	require.Len(t, keys, 200)
+	assertOrdered(t, keys)
+	assert.Greater(t, keys[0], "a")
+	assert.Less(t, keys[len(keys)-1], "b")
+	assert.False(t, TooLong(keys...))
+
+	keys, err = BetweenN("", "", 0)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = BetweenN("p", "p", 2)
+	assert.Equal(t, ErrInvalidRange, err)
+}
+
+func TestSpread(t *testing.T) {
+	assert.Empty(t, Spread(0))
+	assert.Equal(t, []string{"i"}, Spread(1))
+	assert.Equal(t, []string{"9", "i", "r"}, Spread(3))
+	for _, n := range []int{35, 36, 1000, 50000} {
+		keys := Spread(n)
+		require.Len(t, keys, n)
+		assertOrdered(t, keys)
+		for _, key := range keys {
+			require.NoError(t, validate(key))
+		}
+	}
+}
+
+// assertOrdered checks that keys are valid, distinct and in order
+func assertOrdered(t *testing.T, keys []string) {
+	t.Helper()
+	for i, key := range keys {
+		require.NoError(t, validate(key), "key %q", key)
+		if i > 0 {
+			require.Less(t, keys[i-1], key)
+		}
+	}
+	assert.True(t, sort.StringsAreSorted(keys))
+}
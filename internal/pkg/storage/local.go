@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalPathPrefix is the path that the local backend's URLs are served
+// from (see handlers.BlobHandler)
+const LocalPathPrefix = "/blobs/"
+
+// Local stores blobs on the local filesystem under Root. Its URLs point at
+// BaseURL + LocalPathPrefix, and are signed with SigningKey
+type Local struct {
+	Root       string
+	BaseURL    string
+	SigningKey []byte
+}
+
+// NewLocalFromEnv configures the local backend from STORAGE_PATH (defaults to
+// ./storage), STORAGE_URL (the public URL of the API) and STORAGE_SIGNING_KEY
+func NewLocalFromEnv() (*Local, error) {
+	root := os.Getenv("STORAGE_PATH")
+	if root == "" {
+		root = "storage"
+	}
+	key := os.Getenv("STORAGE_SIGNING_KEY")
+	if key == "" {
+		return nil, errors.New("STORAGE_SIGNING_KEY must be set for local storage")
+	}
+	return &Local{
+		Root:       root,
+		BaseURL:    strings.TrimSuffix(os.Getenv("STORAGE_URL"), "/"),
+		SigningKey: []byte(key),
+	}, nil
+}
+
+// Put writes data to the file for key
+func (l *Local) Put(key string, data []byte, contentType string) error {
+	file, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
+
+// Delete removes the file for key
+func (l *Local) Delete(key string) error {
+	file, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// URL returns a URL for key that's signed until expiry has passed
+func (l *Local) URL(key string, expiry time.Duration) (string, error) {
+	if _, err := l.path(key); err != nil {
+		return "", err
+	}
+	expires := time.Now().Add(expiry).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", l.sign(key, expires))
+	return fmt.Sprintf("%s%s%s?%s", l.BaseURL, LocalPathPrefix, key, query.Encode()), nil
+}
+
+// Open verifies the signature of a URL generated by URL, and returns the
+// path of the file for key
+func (l *Local) Open(key string, expires string, signature string) (string, error) {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return "", errors.New("URL has expired")
+	}
+	if !hmac.Equal([]byte(signature), []byte(l.sign(key, expiresAt))) {
+		return "", errors.New("URL signature is invalid")
+	}
+	return l.path(key)
+}
+
+// sign signs a key and expiry time
+func (l *Local) sign(key string, expires int64) string {
+	mac := hmac.New(sha256.New, l.SigningKey)
+	fmt.Fprintf(mac, "%s\n%d", key, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path returns the file for key, making sure it's inside Root
+func (l *Local) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if key == "" || cleaned != "/"+key || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(l.Root, filepath.FromSlash(cleaned)), nil
+}
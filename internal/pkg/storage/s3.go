@@ -0,0 +1,270 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// s3Timeout is how long requests to the S3 API can take
+const s3Timeout = 30 * time.Second
+
+// S3 stores blobs in a bucket of an S3 compatible service (AWS S3, MinIO,
+// DigitalOcean Spaces...), using requests signed with AWS Signature Version 4.
+// With PathStyle, buckets are addressed as Endpoint/Bucket instead of as a
+// subdomain of Endpoint, which most non-AWS services need
+type S3 struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool
+
+	client *http.Client
+	// now is overridden in tests
+	now func() time.Time
+}
+
+// NewS3FromEnv configures the S3 backend from S3_ENDPOINT (defaults to AWS
+// for S3_REGION), S3_REGION, S3_BUCKET, S3_ACCESS_KEY_ID,
+// S3_SECRET_ACCESS_KEY and S3_PATH_STYLE
+func NewS3FromEnv() (*S3, error) {
+	backend := &S3{
+		Endpoint:        strings.TrimSuffix(os.Getenv("S3_ENDPOINT"), "/"),
+		Region:          os.Getenv("S3_REGION"),
+		Bucket:          os.Getenv("S3_BUCKET"),
+		AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		PathStyle:       os.Getenv("S3_PATH_STYLE") == "true",
+	}
+	if backend.Region == "" || backend.Bucket == "" || backend.AccessKeyID == "" || backend.SecretAccessKey == "" {
+		return nil, errors.New("S3_REGION, S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY must be set for S3 storage")
+	}
+	if backend.Endpoint == "" {
+		backend.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", backend.Region)
+	}
+	return backend, nil
+}
+
+// Put uploads data to the bucket
+func (s *S3) Put(key string, data []byte, contentType string) error {
+	headers := map[string]string{"Content-Type": contentType}
+	return s.do(http.MethodPut, key, data, headers)
+}
+
+// Delete removes the object for key from the bucket
+func (s *S3) Delete(key string) error {
+	return s.do(http.MethodDelete, key, nil, nil)
+}
+
+// URL returns a presigned URL for the object for key
+func (s *S3) URL(key string, expiry time.Duration) (string, error) {
+	if key == "" {
+		return "", ErrInvalidKey
+	}
+	objectURL, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+	now := s.time()
+	amzDate := now.Format("20060102T150405Z")
+	scope := s.scope(now)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		objectURL.EscapedPath(),
+		canonicalQuery(query),
+		"host:" + objectURL.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(now, amzDate, canonicalRequest))
+	objectURL.RawQuery = canonicalQuery(query)
+	return objectURL.String(), nil
+}
+
+// do sends a signed request for the object for key
+func (s *S3) do(method string, key string, body []byte, headers map[string]string) error {
+	if key == "" {
+		return ErrInvalidKey
+	}
+	objectURL, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest(method, objectURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	s.sign(request, body)
+
+	client := s.client
+	if client == nil {
+		client = &http.Client{Timeout: s3Timeout}
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 && !(method == http.MethodDelete && response.StatusCode == http.StatusNotFound) {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("S3 %s %s failed with %s: %s", method, key, response.Status, message)
+	}
+	return nil
+}
+
+// sign adds the Signature Version 4 Authorization header to a request
+func (s *S3) sign(request *http.Request, body []byte) {
+	now := s.time()
+	amzDate := now.Format("20060102T150405Z")
+	payloadHash := sha256Hex(body)
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	names := []string{"host"}
+	values := map[string]string{"host": request.URL.Host}
+	for name := range request.Header {
+		lower := strings.ToLower(name)
+		names = append(names, lower)
+		values[lower] = strings.TrimSpace(request.Header.Get(name))
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + values[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		canonicalQuery(request.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	signature := s.signature(now, amzDate, canonicalRequest)
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKeyID, s.scope(now), signedHeaders, signature,
+	))
+}
+
+// signature signs a canonical request with the derived signing key
+func (s *S3) signature(now time.Time, amzDate string, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		s.scope(now),
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// scope is the credential scope of requests signed at now
+func (s *S3) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.Region + "/s3/aws4_request"
+}
+
+// objectURL is the URL of the object for key
+func (s *S3) objectURL(key string) (*url.URL, error) {
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	objectPath := "/" + key
+	if s.PathStyle {
+		objectPath = "/" + s.Bucket + objectPath
+	} else {
+		endpoint.Host = s.Bucket + "." + endpoint.Host
+	}
+	endpoint.Path = objectPath
+	endpoint.RawPath = uriEncodePath(objectPath)
+	return endpoint, nil
+}
+
+func (s *S3) time() time.Time {
+	if s.now != nil {
+		return s.now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+// canonicalQuery encodes a query the way Signature Version 4 expects: sorted
+// by key, with spaces as %20
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, uriEncode(key)+"="+uriEncode(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncodePath encodes each segment of a path
+func uriEncodePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// uriEncode percent-encodes everything but unreserved characters
+func uriEncode(value string) string {
+	var encoded strings.Builder
+	for _, b := range []byte(value) {
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') || b == '-' || b == '_' || b == '.' || b == '~' {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return encoded.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
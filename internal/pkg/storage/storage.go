@@ -0,0 +1,52 @@
+// Package storage stores blobs (i.e. photos) in a backend, and generates
+// signed, expiring URLs to serve them from
+package storage
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// Backend is a blob store. Keys are slash separated paths
+// (i.e. photos/<id>.jpg)
+type Backend interface {
+	// Put stores data under key, replacing anything already stored there
+	Put(key string, data []byte, contentType string) error
+	// Delete removes the blob stored under key. Deleting a key that doesn't
+	// exist isn't an error
+	Delete(key string) error
+	// URL returns a signed URL that the blob can be downloaded from until
+	// expiry has passed
+	URL(key string, expiry time.Duration) (string, error)
+}
+
+var (
+	defaultBackend Backend
+	defaultErr     error
+	once           sync.Once
+)
+
+// Default returns the backend configured by the environment: the S3 backend
+// when STORAGE_BACKEND is s3 (see NewS3FromEnv), otherwise the local
+// filesystem backend (see NewLocalFromEnv)
+func Default() (Backend, error) {
+	once.Do(func() {
+		if os.Getenv("STORAGE_BACKEND") == "s3" {
+			defaultBackend, defaultErr = NewS3FromEnv()
+		} else {
+			defaultBackend, defaultErr = NewLocalFromEnv()
+		}
+	})
+	return defaultBackend, defaultErr
+}
+
+// SetDefault replaces the default backend (i.e. in tests)
+func SetDefault(backend Backend) {
+	once.Do(func() {})
+	defaultBackend, defaultErr = backend, nil
+}
+
+// ErrInvalidKey is returned for keys that could escape the storage root
+var ErrInvalidKey = errors.New("invalid storage key")
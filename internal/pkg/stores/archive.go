@@ -0,0 +1,137 @@
+package stores
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// ArchiveStore archives a store so that it is hidden from the user's stores but
+// can still be restored for models.ArchiveRetentionPeriod.
+//
+// Only the creator of a store can archive it
+func ArchiveStore(storeID interface{}, userID uuid.UUID) (store models.Store, err error) {
+	if err := db.Manager.Where("id = ? AND user_id = ?", storeID, userID).First(&store).Error; err != nil {
+		return store, errors.New("couldn't retrieve store")
+	}
+	if store.ArchivedAt != nil {
+		return store, errors.New("this store has already been deleted")
+	}
+
+	archivedAt := time.Now()
+	if err := db.Manager.Model(&store).UpdateColumn("archived_at", archivedAt).Error; err != nil {
+		return store, err
+	}
+	store.ArchivedAt = &archivedAt
+	return store, nil
+}
+
+// RestoreStore restores an archived store, as long as it was archived within
+// the last models.ArchiveRetentionPeriod
+func RestoreStore(storeID interface{}, userID uuid.UUID) (store models.Store, err error) {
+	if err := db.Manager.Where("id = ? AND user_id = ?", storeID, userID).First(&store).Error; err != nil {
+		return store, errors.New("couldn't retrieve store")
+	}
+	if store.ArchivedAt == nil {
+		return store, errors.New("this store has not been deleted")
+	}
+	if time.Since(*store.ArchivedAt) > models.ArchiveRetentionPeriod {
+		return store, errors.New("this store can no longer be restored")
+	}
+
+	if err := db.Manager.Model(&store).UpdateColumn("archived_at", nil).Error; err != nil {
+		return store, err
+	}
+	store.ArchivedAt = nil
+	return store, nil
+}
+
+// RetrieveArchivedUserStores retrieves the archived stores that the user created
+// and can still restore
+func RetrieveArchivedUserStores(user models.User) (stores []models.Store, err error) {
+	query := db.Manager.
+		Where("user_id = ?", user.ID).
+		Where("archived_at > ?", time.Now().Add(-models.ArchiveRetentionPeriod)).
+		Order("archived_at DESC").
+		Find(&stores).
+		Error
+	if err := query; err != nil {
+		return stores, err
+	}
+	return stores, nil
+}
+
+// PurgeArchivedStores permanently deletes stores that were archived longer
+// than models.ArchiveRetentionPeriod ago. A store that can't be deleted doesn't
+// stop the others from being purged; the errors are returned together once
+// they've all been tried
+//
+// Note: Associated trips, items, store users etc. are deleted by purgeStore
+func PurgeArchivedStores() (err error) {
+	var stores []models.Store
+	query := db.Manager.
+		Where("archived_at < ?", time.Now().Add(-models.ArchiveRetentionPeriod)).
+		Find(&stores).
+		Error
+	if err := query; err != nil {
+		return err
+	}
+	var failures []string
+	for i := range stores {
+		if err := purgeStore(&stores[i]); err != nil {
+			log.Printf("[stores] purge store %v: %v\n", stores[i].ID, err)
+			failures = append(failures, fmt.Sprintf("%v: %v", stores[i].ID, err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("couldn't purge %d of %d archived stores (%s)", len(failures), len(stores), strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// purgeStore deletes a store along with its trips, items, members, activity
+// log, budget, schedule, dismissed suggestions and product names. The rows are
+// deleted here rather than in the Store.AfterDelete hook, which would email
+// the members about a store that was deleted long ago, so the hooks are
+// skipped (the activity entries can't otherwise be deleted either)
+func purgeStore(store *models.Store) error {
+	return db.Manager.Transaction(func(tx *gorm.DB) error {
+		purge := tx.Session(&gorm.Session{SkipHooks: true})
+		storeRecords := []interface{}{
+			&models.StoreActivity{},
+			&models.StoreBudget{},
+			&models.StoreSchedule{},
+			&models.SuggestionDismissal{},
+			&models.ProductName{},
+		}
+		for _, record := range storeRecords {
+			if err := purge.Where("store_id = ?", store.ID).Delete(record).Error; err != nil {
+				return err
+			}
+		}
+
+		// The items are deleted before the trips, so that the trips can still
+		// be found
+		itemsQuery := purge.
+			Where("grocery_trip_id IN (?)", tx.Model(&models.GroceryTrip{}).Select("id").Where("store_id = ?", store.ID)).
+			Delete(&models.Item{}).
+			Error
+		if err := itemsQuery; err != nil {
+			return err
+		}
+		if err := purge.Where("store_id = ?", store.ID).Delete(&models.GroceryTrip{}).Error; err != nil {
+			return err
+		}
+		if err := purge.Where("store_id = ?", store.ID).Delete(&models.StoreUser{}).Error; err != nil {
+			return err
+		}
+		return purge.Delete(store).Error
+	})
+}
@@ -0,0 +1,144 @@
+package stores
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/notifications"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// RetrieveStoreBudget retrieves the monthly budget for a store, or nil if the
+// store doesn't have one
+func RetrieveStoreBudget(storeID uuid.UUID) (*models.StoreBudget, error) {
+	var budget models.StoreBudget
+	query := db.Manager.Where("store_id = ?", storeID).First(&budget).Error
+	if errors.Is(query, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err := query; err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// StoreCurrency returns the currency assumed for item prices in a store that
+// don't specify one: the currency of the store's budget, or DefaultCurrency
+func StoreCurrency(storeID uuid.UUID) (string, error) {
+	budget, err := RetrieveStoreBudget(storeID)
+	if err != nil {
+		return models.DefaultCurrency, err
+	}
+	if budget == nil {
+		return models.DefaultCurrency, nil
+	}
+	return budget.Currency, nil
+}
+
+// SetStoreBudget creates or updates the monthly budget for a store
+func SetStoreBudget(storeID interface{}, userID uuid.UUID, args map[string]interface{}) (budget models.StoreBudget, err error) {
+	store, err := fetchMemberStore(storeID, userID)
+	if err != nil {
+		return budget, err
+	}
+
+	amount := int64(args["monthlyAmount"].(int))
+	if amount <= 0 {
+		return budget, errors.New("budget must be greater than zero")
+	}
+	currency := strings.ToUpper(strings.TrimSpace(args["currency"].(string)))
+	if !models.ValidCurrency(currency) {
+		return budget, errors.New("currency must be a three letter currency code")
+	}
+
+	query := db.Manager.Where("store_id = ?", store.ID).First(&budget).Error
+	if err := query; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return budget, err
+	}
+	budget.StoreID = store.ID
+	budget.MonthlyAmount = amount
+	budget.Currency = currency
+	// A new budget amount may need to be alerted about again this month
+	budget.NotifiedMonth = nil
+	if err := db.Manager.Save(&budget).Error; err != nil {
+		return budget, err
+	}
+	return budget, nil
+}
+
+// DeleteStoreBudget removes the monthly budget for a store
+func DeleteStoreBudget(storeID interface{}, userID uuid.UUID) (budget models.StoreBudget, err error) {
+	store, err := fetchMemberStore(storeID, userID)
+	if err != nil {
+		return budget, err
+	}
+	if err := db.Manager.Where("store_id = ?", store.ID).First(&budget).Error; err != nil {
+		return budget, errors.New("couldn't retrieve store budget")
+	}
+	if err := db.Manager.Delete(&budget).Error; err != nil {
+		return budget, err
+	}
+	return budget, nil
+}
+
+// CheckTripBudget notifies store members once a month when the estimated total
+// of a trip, on top of what has already been spent at the store this month,
+// goes over the store's budget. Only prices in the budget's currency count
+func CheckTripBudget(tripID uuid.UUID) error {
+	var trip models.GroceryTrip
+	if err := db.Manager.Select("id, store_id").Where("id = ?", tripID).First(&trip).Error; err != nil {
+		return err
+	}
+	budget, err := RetrieveStoreBudget(trip.StoreID)
+	if err != nil || budget == nil {
+		return err
+	}
+	now := time.Now().UTC()
+	month := now.Format(models.BudgetMonthLayout)
+	if budget.NotifiedMonth != nil && *budget.NotifiedMonth == month {
+		return nil
+	}
+
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	var spent int64
+	spentQuery := pricedItems(budget.Currency).
+		Joins("INNER JOIN grocery_trips ON grocery_trips.id = items.grocery_trip_id").
+		Where("grocery_trips.store_id = ? AND items.grocery_trip_id <> ?", trip.StoreID, trip.ID).
+		Where("items.completed = ? AND items.updated_at >= ?", true, monthStart).
+		Scan(&spent).
+		Error
+	if err := spentQuery; err != nil {
+		return err
+	}
+	var estimate int64
+	estimateQuery := pricedItems(budget.Currency).
+		Where("items.grocery_trip_id = ?", trip.ID).
+		Scan(&estimate).
+		Error
+	if err := estimateQuery; err != nil {
+		return err
+	}
+	if spent+estimate <= budget.MonthlyAmount {
+		return nil
+	}
+
+	if err := db.Manager.Model(budget).UpdateColumn("notified_month", month).Error; err != nil {
+		return err
+	}
+	go notifications.OverBudget(trip.StoreID, *budget, spent+estimate)
+	return nil
+}
+
+// pricedItems builds a query for the total price of items in the currency
+// provided. Items without a currency are assumed to be in that currency
+func pricedItems(currency string) *gorm.DB {
+	return db.Manager.
+		Model(&models.Item{}).
+		Select("COALESCE(SUM("+models.ItemPriceSQL+"), 0)").
+		Where("items.unit_price IS NOT NULL").
+		Where("COALESCE(items.currency, ?) = ?", currency, currency)
+}
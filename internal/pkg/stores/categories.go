@@ -0,0 +1,274 @@
+package stores
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// CreateStoreCategory adds a category with the name provided to the end of a store's categories
+func CreateStoreCategory(storeID interface{}, userID uuid.UUID, name string) (category models.StoreCategory, err error) {
+	store, err := fetchMemberStore(storeID, userID)
+	if err != nil {
+		return category, err
+	}
+	name, err = validateCategoryName(store.ID, name, uuid.Nil)
+	if err != nil {
+		return category, err
+	}
+
+	var position int
+	query := db.Manager.
+		Model(&models.StoreCategory{}).
+		Select("COALESCE(MAX(position), 0)").
+		Where("store_id = ?", store.ID).
+		Scan(&position).
+		Error
+	if err := query; err != nil {
+		return category, err
+	}
+
+	category = models.StoreCategory{StoreID: store.ID, Name: name, Position: position + 1}
+	if err := db.Manager.Create(&category).Error; err != nil {
+		return category, err
+	}
+	return category, nil
+}
+
+// RenameStoreCategory renames a store category
+func RenameStoreCategory(categoryID interface{}, userID uuid.UUID, name string) (category models.StoreCategory, err error) {
+	category, err = fetchStoreCategory(categoryID, userID)
+	if err != nil {
+		return category, err
+	}
+	if category.Name == models.MiscCategoryName {
+		return category, errors.New("this category can't be renamed")
+	}
+	name, err = validateCategoryName(category.StoreID, name, category.ID)
+	if err != nil {
+		return category, err
+	}
+
+	if err := db.Manager.Model(&category).UpdateColumn("name", name).Error; err != nil {
+		return category, err
+	}
+	category.Name = name
+	return category, nil
+}
+
+// DeleteStoreCategory deletes a store category. Any items in the category (and
+// any saved item category settings pointing to it) are moved to the category
+// with the ID reassignToID, or to the Misc. category if reassignToID is nil
+func DeleteStoreCategory(categoryID interface{}, userID uuid.UUID, reassignToID interface{}) (category models.StoreCategory, err error) {
+	category, err = fetchStoreCategory(categoryID, userID)
+	if err != nil {
+		return category, err
+	}
+	if category.Name == models.MiscCategoryName {
+		return category, errors.New("this category can't be deleted")
+	}
+
+	var target models.StoreCategory
+	targetQuery := db.Manager.Where("store_id = ?", category.StoreID)
+	if reassignToID != nil {
+		targetQuery = targetQuery.Where("id = ?", reassignToID)
+	} else {
+		targetQuery = targetQuery.Where("name = ?", models.MiscCategoryName)
+	}
+	if err := targetQuery.First(&target).Error; err != nil {
+		return category, errors.New("couldn't find category to move items to")
+	}
+	if target.ID == category.ID {
+		return category, errors.New("items can't be moved to the category being deleted")
+	}
+
+	err = db.Manager.Transaction(func(tx *gorm.DB) error {
+		if err := reassignTripCategories(category, target, tx); err != nil {
+			return err
+		}
+		if err := reassignItemCategorySettings(category, target, tx); err != nil {
+			return err
+		}
+		return tx.Delete(&category).Error
+	})
+	if err != nil {
+		return category, err
+	}
+	return category, nil
+}
+
+// ReorderStoreCategories persists the order of a store's categories. The
+// categoryIDs provided must contain every category in the store
+func ReorderStoreCategories(storeID interface{}, userID uuid.UUID, categoryIDs []interface{}) (categories []models.StoreCategory, err error) {
+	store, err := fetchMemberStore(storeID, userID)
+	if err != nil {
+		return categories, err
+	}
+	if err := db.Manager.Where("store_id = ?", store.ID).Find(&categories).Error; err != nil {
+		return categories, err
+	}
+	if len(categoryIDs) != len(categories) {
+		return categories, errors.New("all of the store's categories must be provided")
+	}
+
+	positions := make(map[uuid.UUID]int)
+	for i := range categoryIDs {
+		id, err := uuid.FromString(categoryIDs[i].(string))
+		if err != nil {
+			return categories, err
+		}
+		positions[id] = i + 1
+	}
+	for i := range categories {
+		position, ok := positions[categories[i].ID]
+		if !ok {
+			return categories, errors.New("all of the store's categories must be provided")
+		}
+		categories[i].Position = position
+	}
+
+	err = db.Manager.Transaction(func(tx *gorm.DB) error {
+		for i := range categories {
+			if err := tx.Model(&categories[i]).UpdateColumn("position", categories[i].Position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return categories, err
+	}
+
+	ordered := make([]models.StoreCategory, len(categories))
+	for i := range categories {
+		ordered[categories[i].Position-1] = categories[i]
+	}
+	return ordered, nil
+}
+
+// fetchMemberStore retrieves a store that the user is an active member of
+func fetchMemberStore(storeID interface{}, userID uuid.UUID) (store models.Store, err error) {
+	query := db.Manager.
+		Select("stores.id").
+		Joins("INNER JOIN store_users ON store_users.store_id = stores.id").
+		Where("stores.id = ?", storeID).
+		Where("store_users.user_id = ? AND store_users.active = ?", userID, true).
+		First(&store).
+		Error
+	if err := query; err != nil {
+		return store, errors.New("couldn't retrieve store")
+	}
+	return store, nil
+}
+
+// fetchStoreCategory retrieves a category in a store that the user is an active member of
+func fetchStoreCategory(categoryID interface{}, userID uuid.UUID) (category models.StoreCategory, err error) {
+	query := db.Manager.
+		Select("store_categories.*").
+		Joins("INNER JOIN store_users ON store_users.store_id = store_categories.store_id").
+		Where("store_categories.id = ?", categoryID).
+		Where("store_users.user_id = ? AND store_users.active = ?", userID, true).
+		First(&category).
+		Error
+	if err := query; err != nil {
+		return category, errors.New("couldn't retrieve category")
+	}
+	return category, nil
+}
+
+// validateCategoryName trims the name provided and ensures that it isn't blank
+// and that no other category in the store (other than excludeID) already uses it
+func validateCategoryName(storeID uuid.UUID, name string, excludeID uuid.UUID) (string, error) {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return name, errors.New("category name can't be blank")
+	}
+
+	var count int64
+	query := db.Manager.
+		Model(&models.StoreCategory{}).
+		Where("store_id = ? AND LOWER(name) = LOWER(?)", storeID, name).
+		Where("id <> ?", excludeID).
+		Count(&count).
+		Error
+	if err := query; err != nil {
+		return name, err
+	}
+	if count > 0 {
+		return name, errors.New("a category with this name already exists")
+	}
+	return name, nil
+}
+
+// reassignTripCategories moves the items in every trip's category for the
+// store category being deleted into the target category in the same trip
+func reassignTripCategories(category models.StoreCategory, target models.StoreCategory, tx *gorm.DB) error {
+	var tripCategories []models.GroceryTripCategory
+	if err := tx.Where("store_category_id = ?", category.ID).Find(&tripCategories).Error; err != nil {
+		return err
+	}
+	for i := range tripCategories {
+		targetTripCategory := models.GroceryTripCategory{
+			GroceryTripID:   tripCategories[i].GroceryTripID,
+			StoreCategoryID: target.ID,
+		}
+		if err := tx.Where(targetTripCategory).FirstOrCreate(&targetTripCategory).Error; err != nil {
+			return err
+		}
+		itemsQuery := tx.
+			Model(&models.Item{}).
+			Where("category_id = ?", tripCategories[i].ID).
+			UpdateColumn("category_id", targetTripCategory.ID).
+			Error
+		if err := itemsQuery; err != nil {
+			return err
+		}
+		if err := tx.Delete(&tripCategories[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reassignItemCategorySettings points any saved item category settings for
+// the store category being deleted at the target category instead
+func reassignItemCategorySettings(category models.StoreCategory, target models.StoreCategory, tx *gorm.DB) error {
+	var settings models.StoreItemCategorySettings
+	query := tx.Where("store_id = ?", category.StoreID).First(&settings).Error
+	if errors.Is(query, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err := query; err != nil {
+		return err
+	}
+	if len(settings.Items) == 0 {
+		return nil
+	}
+
+	var settingsMap map[string]interface{}
+	if err := json.Unmarshal(settings.Items, &settingsMap); err != nil {
+		return err
+	}
+	changed := false
+	for name, id := range settingsMap {
+		if id == category.ID.String() {
+			settingsMap[name] = target.ID.String()
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	items, err := json.Marshal(settingsMap)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&settings).UpdateColumn("items", datatypes.JSON(items)).Error
+}
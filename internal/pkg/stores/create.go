@@ -10,11 +10,25 @@ import (
 
 // CreateStore creates a store for a user if it does not already exist by name
 func CreateStore(userID uuid.UUID, name string) (models.Store, error) {
+	return createStore(userID, name, nil)
+}
+
+// CreateStoreFromTemplate creates a store for a user from one of the user's
+// store templates, if it does not already exist by name
+func CreateStoreFromTemplate(userID uuid.UUID, name string, templateID interface{}) (models.Store, error) {
+	var template models.StoreTemplate
+	if err := db.Manager.Where("id = ? AND user_id = ?", templateID, userID).First(&template).Error; err != nil {
+		return models.Store{}, errors.New("couldn't retrieve store template")
+	}
+	return createStore(userID, name, &template)
+}
+
+func createStore(userID uuid.UUID, name string, template *models.StoreTemplate) (models.Store, error) {
 	dupeStore, _ := RetrieveStoreForUserByName(name, userID)
 	if dupeStore.Name != "" {
 		return models.Store{}, errors.New("you already added a store with this name")
 	}
-	store := models.Store{UserID: userID, Name: name}
+	store := models.Store{UserID: userID, Name: name, Template: template}
 	if err := db.Manager.Create(&store).Error; err != nil {
 		return models.Store{}, err
 	}
@@ -0,0 +1,56 @@
+package stores
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/utils"
+)
+
+// NearbyStore is a store along with its distance in metres from a location
+type NearbyStore struct {
+	Store          models.Store
+	Distance       float64
+	WithinGeofence bool
+}
+
+// RetrieveNearbyUserStores retrieves the user's stores that have a location,
+// sorted by their distance from the latitude and longitude provided
+func RetrieveNearbyUserStores(user models.User, lat float64, lng float64) (nearby []NearbyStore, err error) {
+	if !utils.ValidCoordinates(lat, lng) {
+		return nearby, errors.New("latitude or longitude is out of range")
+	}
+	userStores, err := RetrieveUserStores(user)
+	if err != nil {
+		return nearby, err
+	}
+	return SortStoresByDistance(userStores, lat, lng), nil
+}
+
+// SortStoresByDistance calculates the distance of each store from the latitude
+// and longitude provided and sorts them nearest first. Stores without a
+// location are left out
+func SortStoresByDistance(stores []models.Store, lat float64, lng float64) (nearby []NearbyStore) {
+	nearby = []NearbyStore{}
+	for i := range stores {
+		store := stores[i]
+		if store.Latitude == nil || store.Longitude == nil {
+			continue
+		}
+		distance := utils.HaversineDistance(lat, lng, *store.Latitude, *store.Longitude)
+		radius := models.DefaultGeofenceRadius
+		if store.GeofenceRadius != nil {
+			radius = *store.GeofenceRadius
+		}
+		nearby = append(nearby, NearbyStore{
+			Store:          store,
+			Distance:       distance,
+			WithinGeofence: distance <= float64(radius),
+		})
+	}
+	sort.SliceStable(nearby, func(i, j int) bool {
+		return nearby[i].Distance < nearby[j].Distance
+	})
+	return nearby
+}
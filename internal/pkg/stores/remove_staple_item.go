@@ -1,15 +1,16 @@
 package stores
 
 import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
 	uuid "github.com/satori/go.uuid"
 )
 
 // RemoveStapleItem dissocates any staple_item_id from items for this staple item and deletes the staple item
-func RemoveStapleItem(itemID uuid.UUID) (staple models.StoreStapleItem, err error) {
+func RemoveStapleItem(userID uuid.UUID, itemID uuid.UUID) (staple models.StoreStapleItem, err error) {
 	var item models.Item
-	if err := db.Manager.Select("staple_item_id").Where("id = ?", itemID).First(&item).Error; err != nil {
+	if err := db.Manager.Select("staple_item_id, grocery_trip_id, name").Where("id = ?", itemID).First(&item).Error; err != nil {
 		return staple, err
 	}
 	if err := db.Manager.Where("id = ?", item.StapleItemID).Delete(&staple).Error; err != nil {
@@ -25,5 +26,11 @@ func RemoveStapleItem(itemID uuid.UUID) (staple models.StoreStapleItem, err erro
 		return staple, err
 	}
 
+	entity := activity.Entity{Type: activity.EntityStapleItem, Name: item.Name}
+	if item.StapleItemID != nil {
+		entity.ID = *item.StapleItemID
+	}
+	activity.RecordForTrip(db.Manager, item.GroceryTripID, userID, models.ActivityStapleRemoved, entity, nil)
+
 	return staple, nil
 }
@@ -1,20 +1,44 @@
 package stores
 
 import (
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
 	uuid "github.com/satori/go.uuid"
 )
 
 // SaveStapleItem saves an item as a staple in the store ID provided
-func SaveStapleItem(storeID uuid.UUID, itemID uuid.UUID) (staple models.StoreStapleItem, err error) {
+func SaveStapleItem(userID uuid.UUID, storeID uuid.UUID, itemID uuid.UUID) (staple models.StoreStapleItem, err error) {
 	var item models.Item
 	if err := db.Manager.Where("id = ?", itemID).First(&item).Error; err != nil {
 		return staple, err
 	}
 
+	// A new staple keeps the item's amount, notes and category. The item is
+	// already on a trip, so the staple counts as added to it
+	now := time.Now()
+	attrs := models.StoreStapleItem{
+		Quantity:        item.Quantity,
+		DecimalQuantity: item.DecimalQuantity,
+		Unit:            item.Unit,
+		Notes:           item.Notes,
+		LastAddedAt:     &now,
+	}
+	if item.CategoryID != nil {
+		var category models.GroceryTripCategory
+		if err := db.Manager.Select("store_category_id").Where("id = ?", item.CategoryID).First(&category).Error; err == nil {
+			attrs.StoreCategoryID = &category.StoreCategoryID
+		}
+	}
 	stapleItem := models.StoreStapleItem{StoreID: storeID, Name: item.Name}
-	if err := db.Manager.Where(stapleItem).FirstOrCreate(&stapleItem).Error; err != nil {
+	query := db.Manager.
+		Where(stapleItem).
+		Attrs(attrs).
+		FirstOrCreate(&stapleItem).
+		Error
+	if err := query; err != nil {
 		return staple, err
 	}
 
@@ -23,5 +47,8 @@ func SaveStapleItem(storeID uuid.UUID, itemID uuid.UUID) (staple models.StoreSta
 		return stapleItem, err
 	}
 
+	entity := activity.Entity{Type: activity.EntityStapleItem, ID: stapleItem.ID, Name: stapleItem.Name}
+	activity.Record(db.Manager, storeID, userID, models.ActivityStapleAdded, entity, nil)
+
 	return stapleItem, nil
 }
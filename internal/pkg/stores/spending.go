@@ -0,0 +1,64 @@
+package stores
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+)
+
+// CategorySpending is the amount spent on items in a store category, in the
+// minor unit of the currency (i.e. cents)
+type CategorySpending struct {
+	StoreCategoryID uuid.UUID
+	Name            string
+	Currency        string
+	Amount          int64
+}
+
+// RetrieveSpending breaks down the spending at a store between the dates from
+// and to (inclusive, in the format YYYY-MM-DD) by store category. Spending is
+// the price of the items that were picked up, on the day they were picked up
+func RetrieveSpending(storeID interface{}, userID uuid.UUID, from string, to string) (spending []CategorySpending, err error) {
+	store, err := fetchMemberStore(storeID, userID)
+	if err != nil {
+		return spending, err
+	}
+	fromDate, err := time.Parse(models.ShoppingDateLayout, from)
+	if err != nil {
+		return spending, errors.New("from must be in the format YYYY-MM-DD")
+	}
+	toDate, err := time.Parse(models.ShoppingDateLayout, to)
+	if err != nil {
+		return spending, errors.New("to must be in the format YYYY-MM-DD")
+	}
+	if toDate.Before(fromDate) {
+		return spending, errors.New("to must be on or after from")
+	}
+	currency, err := StoreCurrency(store.ID)
+	if err != nil {
+		return spending, err
+	}
+
+	query := db.Manager.
+		Model(&models.Item{}).
+		Select("store_categories.id AS store_category_id, store_categories.name, COALESCE(items.currency, ?) AS currency, SUM("+models.ItemPriceSQL+") AS amount", currency).
+		Joins("INNER JOIN grocery_trips ON grocery_trips.id = items.grocery_trip_id").
+		Joins("INNER JOIN grocery_trip_categories ON grocery_trip_categories.id = items.category_id").
+		Joins("INNER JOIN store_categories ON store_categories.id = grocery_trip_categories.store_category_id").
+		Where("grocery_trips.store_id = ?", store.ID).
+		Where("items.completed = ? AND items.unit_price IS NOT NULL", true).
+		Where("items.updated_at >= ? AND items.updated_at < ?", fromDate, toDate.AddDate(0, 0, 1)).
+		// Note: 3 refers to the currency in the select, since grouping by the
+		// name "currency" would use the items.currency column instead
+		Group("store_categories.id, store_categories.name, store_categories.position, 3").
+		Order("store_categories.position ASC, currency ASC").
+		Scan(&spending).
+		Error
+	if err := query; err != nil {
+		return spending, err
+	}
+	return spending, nil
+}
@@ -0,0 +1,129 @@
+package stores
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+)
+
+// UpcomingStapleItem is a staple item and when it's next due to be added to
+// a trip
+type UpcomingStapleItem struct {
+	StapleItem models.StoreStapleItem
+	// DueNextTrip is whether the staple will be added to the next new trip
+	DueNextTrip bool
+	// NextDueDate is when the staple is next due, for the days and weekdays
+	// cadences
+	NextDueDate *time.Time
+	// TripsUntilDue is how many more new trips until the staple is due, for
+	// the trips cadence
+	TripsUntilDue *int
+}
+
+// SetStapleItemCadence sets how often a staple item is added to new trips.
+// The user must be a member of the staple item's store
+func SetStapleItemCadence(userID uuid.UUID, args map[string]interface{}) (staple models.StoreStapleItem, err error) {
+	staple, err = findStapleItemForUser(userID, args["stapleItemId"])
+	if err != nil {
+		return staple, err
+	}
+	before := activity.StapleItemAttributes(staple)
+
+	staple.Cadence = args["cadence"].(string)
+	staple.Interval = 1
+	staple.Weekdays = 0
+	switch staple.Cadence {
+	case models.StapleCadenceTrips, models.StapleCadenceDays:
+		if args["interval"] != nil {
+			staple.Interval = args["interval"].(int)
+		}
+		if staple.Interval < 1 || staple.Interval > 365 {
+			return staple, errors.New("interval must be between 1 and 365")
+		}
+	case models.StapleCadenceWeekdays:
+		if args["weekdays"] == nil {
+			return staple, errors.New("weekdays are required for the weekdays cadence")
+		}
+		for _, weekday := range args["weekdays"].([]interface{}) {
+			day := weekday.(int)
+			if day < 0 || day > 6 {
+				return staple, errors.New("weekdays must be between 0 (Sunday) and 6 (Saturday)")
+			}
+			staple.Weekdays |= 1 << uint(day)
+		}
+		if staple.Weekdays == 0 {
+			return staple, errors.New("weekdays are required for the weekdays cadence")
+		}
+	}
+
+	updates := map[string]interface{}{
+		"cadence":  staple.Cadence,
+		"interval": staple.Interval,
+		"weekdays": staple.Weekdays,
+	}
+	if err := updateStapleItem(userID, &staple, updates, before); err != nil {
+		return staple, err
+	}
+	return staple, nil
+}
+
+// RetrieveUpcomingStapleItems retrieves the staple items of a store along
+// with when they're next due, soonest first
+func RetrieveUpcomingStapleItems(userID uuid.UUID, storeID interface{}) (upcoming []UpcomingStapleItem, err error) {
+	if _, err := RetrieveStoreForUser(storeID, userID); err != nil {
+		return upcoming, errors.New("store not found")
+	}
+	var staples []models.StoreStapleItem
+	if err := db.Manager.Where("store_id = ?", storeID).Order("name ASC").Find(&staples).Error; err != nil {
+		return upcoming, err
+	}
+
+	now := time.Now()
+	for i := range staples {
+		staple := staples[i]
+		item := UpcomingStapleItem{StapleItem: staple}
+		switch staple.Cadence {
+		case models.StapleCadenceTrips:
+			tripsSinceAdded, err := staple.TripsSinceAdded(db.Manager)
+			if err != nil {
+				return upcoming, err
+			}
+			// The next new trip counts towards the interval
+			tripsUntilDue := 0
+			if staple.LastAddedAt != nil && tripsSinceAdded+1 < int64(staple.Interval) {
+				tripsUntilDue = staple.Interval - int(tripsSinceAdded) - 1
+			}
+			item.TripsUntilDue = &tripsUntilDue
+			item.DueNextTrip = tripsUntilDue == 0
+		case models.StapleCadenceDays, models.StapleCadenceWeekdays:
+			item.NextDueDate = staple.NextDueDate()
+			item.DueNextTrip = staple.DueOn(now, 0)
+		default:
+			item.DueNextTrip = true
+		}
+		upcoming = append(upcoming, item)
+	}
+
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcomingRank(upcoming[i]).Before(upcomingRank(upcoming[j]))
+	})
+	return upcoming, nil
+}
+
+// upcomingRank orders upcoming staple items: those due next trip first, then
+// by next due date, then those waiting on more trips
+func upcomingRank(item UpcomingStapleItem) time.Time {
+	if item.DueNextTrip {
+		return time.Time{}
+	}
+	if item.NextDueDate != nil {
+		return *item.NextDueDate
+	}
+	// Assume roughly a trip per week for staples due in a number of trips
+	return time.Now().AddDate(0, 0, 7*(*item.TripsUntilDue))
+}
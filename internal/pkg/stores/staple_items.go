@@ -0,0 +1,162 @@
+package stores
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/parser"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// RetrieveStapleItems retrieves the staple items of a store, by name
+func RetrieveStapleItems(userID uuid.UUID, storeID interface{}) (staples []models.StoreStapleItem, err error) {
+	if _, err := RetrieveStoreForUser(storeID, userID); err != nil {
+		return staples, errors.New("store not found")
+	}
+	if err := db.Manager.Where("store_id = ?", storeID).Order("name ASC").Find(&staples).Error; err != nil {
+		return staples, err
+	}
+	return staples, nil
+}
+
+// UpdateStapleItem updates the name, amount, notes and pinned category of a
+// staple item. Passing an empty unit, notes or storeCategoryId clears it.
+// The user must be a member of the staple item's store
+func UpdateStapleItem(userID uuid.UUID, args map[string]interface{}) (staple models.StoreStapleItem, err error) {
+	staple, err = findStapleItemForUser(userID, args["stapleItemId"])
+	if err != nil {
+		return staple, err
+	}
+	before := activity.StapleItemAttributes(staple)
+
+	updates := map[string]interface{}{}
+	if args["name"] != nil {
+		name := strings.TrimSpace(args["name"].(string))
+		if name == "" {
+			return staple, errors.New("name can't be blank")
+		}
+		staple.Name = name
+		updates["name"] = staple.Name
+	}
+	if args["quantity"] != nil || args["decimalQuantity"] != nil || args["unit"] != nil {
+		if err := setStapleItemAmount(&staple, args); err != nil {
+			return staple, err
+		}
+		updates["quantity"] = staple.Quantity
+		updates["decimal_quantity"] = staple.DecimalQuantity
+		updates["unit"] = staple.Unit
+	}
+	if args["notes"] != nil {
+		staple.Notes = nil
+		if notes := strings.TrimSpace(args["notes"].(string)); notes != "" {
+			staple.Notes = &notes
+		}
+		updates["notes"] = staple.Notes
+	}
+	if args["storeCategoryId"] != nil {
+		staple.StoreCategoryID = nil
+		if args["storeCategoryId"].(string) != "" {
+			storeCategoryID, err := uuid.FromString(args["storeCategoryId"].(string))
+			if err != nil {
+				return staple, errors.New("store category not found")
+			}
+			var storeCategory models.StoreCategory
+			query := db.Manager.
+				Select("id").
+				Where("id = ? AND store_id = ?", storeCategoryID, staple.StoreID).
+				First(&storeCategory).
+				Error
+			if err := query; err != nil {
+				return staple, errors.New("store category not found")
+			}
+			staple.StoreCategoryID = &storeCategory.ID
+		}
+		updates["store_category_id"] = staple.StoreCategoryID
+	}
+	if len(updates) == 0 {
+		return staple, nil
+	}
+
+	if err := updateStapleItem(userID, &staple, updates, before); err != nil {
+		return staple, err
+	}
+	return staple, nil
+}
+
+// updateStapleItem saves the changes to a staple item, and records them in
+// its store's activity log in the same transaction
+func updateStapleItem(userID uuid.UUID, staple *models.StoreStapleItem, updates map[string]interface{}, before map[string]interface{}) error {
+	return db.Manager.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(staple).Updates(updates).Error; err != nil {
+			return err
+		}
+		changes := activity.Diff(before, activity.StapleItemAttributes(*staple))
+		if len(changes) > 0 {
+			entity := activity.Entity{Type: activity.EntityStapleItem, ID: staple.ID, Name: staple.Name}
+			activity.Record(tx, staple.StoreID, userID, models.ActivityStapleChanged, entity, changes)
+		}
+		return nil
+	})
+}
+
+// setStapleItemAmount sets the quantity, decimal quantity and unit of a staple
+// item from the args, the same way they're set on items. A quantity on its
+// own replaces any measured amount
+func setStapleItemAmount(staple *models.StoreStapleItem, args map[string]interface{}) error {
+	if args["quantity"] != nil {
+		quantity := args["quantity"].(int)
+		if quantity < 1 {
+			return errors.New("quantity must be greater than zero")
+		}
+		staple.Quantity = quantity
+		if args["decimalQuantity"] == nil {
+			staple.DecimalQuantity = nil
+			staple.Unit = nil
+		}
+	}
+	if args["decimalQuantity"] != nil {
+		decimalQuantity := args["decimalQuantity"].(float64)
+		if decimalQuantity <= 0 {
+			return errors.New("quantity must be greater than zero")
+		}
+		staple.DecimalQuantity = &decimalQuantity
+	}
+	if args["unit"] != nil {
+		unit := args["unit"].(string)
+		if strings.TrimSpace(unit) == "" {
+			staple.Unit = nil
+		} else {
+			normalised, ok := parser.NormaliseUnit(unit)
+			if !ok {
+				return errors.New("unit must be one of lb, oz, kg, g, l or ml")
+			}
+			staple.Unit = &normalised
+		}
+	}
+	if staple.Unit != nil && staple.DecimalQuantity == nil {
+		decimalQuantity := float64(staple.Quantity)
+		staple.DecimalQuantity = &decimalQuantity
+	}
+	if staple.DecimalQuantity != nil {
+		staple.Quantity = parser.WholeQuantity(*staple.DecimalQuantity, staple.Unit)
+	}
+	return nil
+}
+
+// findStapleItemForUser finds a staple item in one of the user's stores
+func findStapleItemForUser(userID uuid.UUID, stapleItemID interface{}) (staple models.StoreStapleItem, err error) {
+	query := db.Manager.
+		Select("store_staple_items.*").
+		Joins("INNER JOIN store_users ON store_users.store_id = store_staple_items.store_id").
+		Where("store_staple_items.id = ? AND store_users.user_id = ? AND store_users.active = ?", stapleItemID, userID, true).
+		First(&staple).
+		Error
+	if err := query; err != nil {
+		return staple, errors.New("staple item not found")
+	}
+	return staple, nil
+}
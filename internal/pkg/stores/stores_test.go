@@ -75,21 +75,21 @@ func (s *Suite) TestCreateStore_Created() {
 	storeID := uuid.NewV4()
 	s.mock.ExpectBegin()
 	s.mock.ExpectQuery("^INSERT INTO \"stores\" (.+)$").
-		WithArgs(storeName, sqlmock.AnyArg(), AnyTime{}, AnyTime{}, nil, userID).
+		WithArgs(storeName, sqlmock.AnyArg(), nil, nil, nil, nil, nil, true, nil, AnyTime{}, AnyTime{}, nil, userID).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(storeID, userID))
 
 	storeUserID := uuid.NewV4()
 	s.mock.ExpectQuery("^INSERT INTO \"store_users\" (.+)$").
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "", true, true, AnyTime{}, AnyTime{}, nil).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "", true, true, nil, AnyTime{}, AnyTime{}, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(storeUserID))
 	s.mock.ExpectQuery("^INSERT INTO \"store_user_preferences\" (.+)$").
-		WithArgs(storeUserID, false, true, AnyTime{}, AnyTime{}, nil).
+		WithArgs(storeUserID, false, true, true, true, true, true, true, true, true, false, false, false, false, false, false, false, AnyTime{}, AnyTime{}, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
 
 	categories := fetchCategories()
 	for i := range categories {
 		s.mock.ExpectQuery("^INSERT INTO \"store_categories\" (.+)$").
-			WithArgs(sqlmock.AnyArg(), categories[i], AnyTime{}, AnyTime{}, nil).
+			WithArgs(sqlmock.AnyArg(), categories[i], i+1, AnyTime{}, AnyTime{}, nil).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
 	}
 
@@ -100,7 +100,7 @@ func (s *Suite) TestCreateStore_Created() {
 		WithArgs(likeTripName, sqlmock.AnyArg()).
 		WillReturnRows(s.mock.NewRows([]string{"count"}).AddRow(0))
 	s.mock.ExpectQuery("^INSERT INTO \"grocery_trips\" (.+)$").
-		WithArgs(sqlmock.AnyArg(), tripName, false, false, AnyTime{}, AnyTime{}, nil).
+		WithArgs(sqlmock.AnyArg(), tripName, false, false, nil, nil, AnyTime{}, AnyTime{}, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
 	s.mock.ExpectCommit()
 
@@ -174,6 +174,50 @@ func (s *Suite) TestUpdateStoreForUser_UpdateSingleColumn() {
 	assert.Equal(s.T(), "My Renamed Store", store.(*models.Store).Name)
 }
 
+func (s *Suite) TestUpdateStoreForUser_LatitudeWithoutLongitude() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(storeID, userID))
+
+	args := map[string]interface{}{"storeId": storeID, "latitude": 43.6532}
+	_, e := UpdateStoreForUser(userID, args)
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "latitude and longitude must be provided together", e.Error())
+}
+
+// Nearby stores
+
+func (s *Suite) TestRetrieveNearbyUserStores_InvalidCoordinates() {
+	_, e := RetrieveNearbyUserStores(models.User{ID: uuid.NewV4()}, 91, 0)
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "latitude or longitude is out of range", e.Error())
+}
+
+func (s *Suite) TestSortStoresByDistance() {
+	lat, lng := 43.6532, -79.3832
+	nearLat, nearLng := 43.6535, -79.3835
+	farLat, farLng := 43.7, -79.4
+	radius := 10
+	userStores := []models.Store{
+		{Name: "Far", Latitude: &farLat, Longitude: &farLng},
+		{Name: "No location"},
+		{Name: "Near", Latitude: &nearLat, Longitude: &nearLng},
+		{Name: "Near, small geofence", Latitude: &nearLat, Longitude: &nearLng, GeofenceRadius: &radius},
+	}
+
+	nearby := SortStoresByDistance(userStores, lat, lng)
+	require.Len(s.T(), nearby, 3)
+	assert.Equal(s.T(), "Near", nearby[0].Store.Name)
+	assert.True(s.T(), nearby[0].WithinGeofence)
+	assert.Equal(s.T(), "Near, small geofence", nearby[1].Store.Name)
+	assert.False(s.T(), nearby[1].WithinGeofence)
+	assert.Equal(s.T(), "Far", nearby[2].Store.Name)
+	assert.False(s.T(), nearby[2].WithinGeofence)
+	assert.Greater(s.T(), nearby[2].Distance, nearby[0].Distance)
+}
+
 // User stores
 
 func (s *Suite) TestRetrieveUserStores_NoStores() {
@@ -408,6 +452,345 @@ func (s *Suite) TestDeleteStore_StoreFound() {
 	assert.Equal(s.T(), storeID, store.ID)
 }
 
+// Archive/restore store
+
+func (s *Suite) TestArchiveStore_StoreNotFound() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	_, e := ArchiveStore(storeID, userID)
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "couldn't retrieve store", e.Error())
+}
+
+func (s *Suite) TestArchiveStore_StoreArchived() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "archived_at"}).AddRow(storeID, userID, nil))
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("^UPDATE \"stores\" SET \"archived_at\"(.+)$").
+		WithArgs(AnyTime{}, storeID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	store, err := ArchiveStore(storeID, userID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), storeID, store.ID)
+	assert.NotNil(s.T(), store.ArchivedAt)
+}
+
+func (s *Suite) TestRestoreStore_NotArchived() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "archived_at"}).AddRow(storeID, userID, nil))
+
+	_, e := RestoreStore(storeID, userID)
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "this store has not been deleted", e.Error())
+}
+
+func (s *Suite) TestRestoreStore_RetentionPeriodPassed() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	archivedAt := time.Now().Add(-models.ArchiveRetentionPeriod - time.Hour)
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "archived_at"}).AddRow(storeID, userID, archivedAt))
+
+	_, e := RestoreStore(storeID, userID)
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "this store can no longer be restored", e.Error())
+}
+
+func (s *Suite) TestRestoreStore_Restored() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	archivedAt := time.Now().Add(-24 * time.Hour)
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "archived_at"}).AddRow(storeID, userID, archivedAt))
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("^UPDATE \"stores\" SET \"archived_at\"(.+)$").
+		WithArgs(nil, storeID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	store, err := RestoreStore(storeID, userID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), storeID, store.ID)
+	assert.Nil(s.T(), store.ArchivedAt)
+}
+
+func (s *Suite) TestPurgeArchivedStores_ContinuesPastFailures() {
+	storeIDs := []uuid.UUID{uuid.NewV4(), uuid.NewV4()}
+	archivedAt := time.Now().Add(-models.ArchiveRetentionPeriod - time.Hour)
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\"*").
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "name", "archived_at"}).
+			AddRow(storeIDs[0], "Store 1", archivedAt).
+			AddRow(storeIDs[1], "Store 2", archivedAt))
+	// The first store can't be deleted, but the second is still tried
+	for _, storeID := range storeIDs {
+		s.mock.ExpectBegin()
+		s.expectPurgeStoreRecords(storeID)
+		s.mock.ExpectExec("^UPDATE \"stores\" SET \"deleted_at\"(.+)$").
+			WithArgs(AnyTime{}, storeID).
+			WillReturnError(fmt.Errorf("connection reset"))
+		s.mock.ExpectRollback()
+	}
+
+	err := PurgeArchivedStores()
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "couldn't purge 2 of 2 archived stores")
+	assert.Contains(s.T(), err.Error(), storeIDs[0].String())
+	assert.Contains(s.T(), err.Error(), storeIDs[1].String())
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestPurgeArchivedStores_Purged() {
+	storeID := uuid.NewV4()
+	archivedAt := time.Now().Add(-models.ArchiveRetentionPeriod - time.Hour)
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\"*").
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "name", "archived_at"}).
+			AddRow(storeID, "Store 1", archivedAt))
+	// Everything belonging to the store is deleted along with it, without
+	// emailing its members
+	s.mock.ExpectBegin()
+	s.expectPurgeStoreRecords(storeID)
+	s.mock.ExpectExec("^UPDATE \"stores\" SET \"deleted_at\"(.+)$").
+		WithArgs(AnyTime{}, storeID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := PurgeArchivedStores()
+	require.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+// expectPurgeStoreRecords expects the rows belonging to a store to be deleted
+// by purgeStore, before the store itself
+func (s *Suite) expectPurgeStoreRecords(storeID uuid.UUID) {
+	for _, table := range []string{"store_activities", "store_budgets", "store_schedules", "suggestion_dismissals", "product_names"} {
+		s.mock.ExpectExec("^DELETE FROM \"" + table + "\" WHERE store_id = (.+)$").
+			WithArgs(storeID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+	s.mock.ExpectExec("^UPDATE \"items\" SET \"deleted_at\"=(.+) WHERE grocery_trip_id IN \\(SELECT \"id\" FROM \"grocery_trips\" (.+)$").
+		WithArgs(AnyTime{}, storeID).
+		WillReturnResult(sqlmock.NewResult(1, 4))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET \"deleted_at\"=(.+)$").
+		WithArgs(AnyTime{}, storeID).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	s.mock.ExpectExec("^UPDATE \"store_users\" SET \"deleted_at\"=(.+)$").
+		WithArgs(AnyTime{}, storeID).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+}
+
+// Store categories
+
+func (s *Suite) TestCreateStoreCategory_BlankName() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT stores.id FROM \"stores\"*").
+		WithArgs(storeID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(storeID))
+
+	_, e := CreateStoreCategory(storeID, userID, "  ")
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "category name can't be blank", e.Error())
+}
+
+func (s *Suite) TestCreateStoreCategory_DupeName() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT stores.id FROM \"stores\"*").
+		WithArgs(storeID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(storeID))
+	s.mock.ExpectQuery("^SELECT count*").
+		WithArgs(storeID, "produce", uuid.Nil).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	_, e := CreateStoreCategory(storeID, userID, "produce")
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "a category with this name already exists", e.Error())
+}
+
+func (s *Suite) TestCreateStoreCategory_Created() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT stores.id FROM \"stores\"*").
+		WithArgs(storeID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(storeID))
+	s.mock.ExpectQuery("^SELECT count*").
+		WithArgs(storeID, "Bulk Foods", uuid.Nil).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	s.mock.ExpectQuery("^SELECT COALESCE(.+) FROM \"store_categories\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(20))
+	categoryID := uuid.NewV4()
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^INSERT INTO \"store_categories\" (.+)$").
+		WithArgs(storeID, "Bulk Foods", 21, AnyTime{}, AnyTime{}, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(categoryID))
+	s.mock.ExpectCommit()
+
+	category, err := CreateStoreCategory(storeID, userID, " Bulk Foods ")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), categoryID, category.ID)
+	assert.Equal(s.T(), "Bulk Foods", category.Name)
+	assert.Equal(s.T(), 21, category.Position)
+}
+
+func (s *Suite) TestRenameStoreCategory_MiscCategory() {
+	categoryID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT store_categories.(.+) FROM \"store_categories\"*").
+		WithArgs(categoryID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name"}).AddRow(categoryID, uuid.NewV4(), "Misc."))
+
+	_, e := RenameStoreCategory(categoryID, userID, "Other")
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "this category can't be renamed", e.Error())
+}
+
+func (s *Suite) TestDeleteStoreCategory_MiscCategory() {
+	categoryID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT store_categories.(.+) FROM \"store_categories\"*").
+		WithArgs(categoryID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name"}).AddRow(categoryID, uuid.NewV4(), "Misc."))
+
+	_, e := DeleteStoreCategory(categoryID, userID, nil)
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "this category can't be deleted", e.Error())
+}
+
+func (s *Suite) TestDeleteStoreCategory_SameTargetCategory() {
+	categoryID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT store_categories.(.+) FROM \"store_categories\"*").
+		WithArgs(categoryID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name"}).AddRow(categoryID, storeID, "Produce"))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_categories\"*").
+		WithArgs(storeID, categoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name"}).AddRow(categoryID, storeID, "Produce"))
+
+	_, e := DeleteStoreCategory(categoryID, userID, categoryID)
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "items can't be moved to the category being deleted", e.Error())
+}
+
+func (s *Suite) TestReorderStoreCategories_MissingCategory() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	produceID := uuid.NewV4()
+	bakeryID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT stores.id FROM \"stores\"*").
+		WithArgs(storeID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(storeID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_categories\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "position"}).AddRow(produceID, "Produce", 1).AddRow(bakeryID, "Bakery", 2))
+
+	_, e := ReorderStoreCategories(storeID, userID, []interface{}{bakeryID.String(), uuid.NewV4().String()})
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "all of the store's categories must be provided", e.Error())
+}
+
+// Store templates
+
+func (s *Suite) TestSetStoreBudget_InvalidCurrency() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT stores.id FROM \"stores\"*").
+		WithArgs(storeID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(storeID))
+
+	args := map[string]interface{}{"monthlyAmount": 50000, "currency": "dollars"}
+	_, e := SetStoreBudget(storeID, userID, args)
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "currency must be a three letter currency code", e.Error())
+}
+
+func (s *Suite) TestRetrieveSpending_ToBeforeFrom() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT stores.id FROM \"stores\"*").
+		WithArgs(storeID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(storeID))
+
+	_, e := RetrieveSpending(storeID, userID, "2026-10-01", "2026-09-30")
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "to must be on or after from", e.Error())
+}
+
+func (s *Suite) TestCreateStoreFromTemplate_TemplateNotFound() {
+	templateID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_templates\"*").
+		WithArgs(templateID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	_, e := CreateStoreFromTemplate(userID, "Costco", templateID)
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "couldn't retrieve store template", e.Error())
+}
+
+func (s *Suite) TestSaveStoreTemplate_BlankName() {
+	_, e := SaveStoreTemplate(uuid.NewV4(), uuid.NewV4(), "")
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "template name can't be blank", e.Error())
+}
+
+func (s *Suite) TestDeleteStoreTemplate_TemplateNotFound() {
+	templateID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_templates\"*").
+		WithArgs(templateID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	_, e := DeleteStoreTemplate(templateID, userID)
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "couldn't retrieve store template", e.Error())
+}
+
+func (s *Suite) TestBuildStoreTemplate_IncludeStaplesAndSettings() {
+	storeID := uuid.NewV4()
+	produceID := uuid.NewV4()
+	miscID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_categories\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "position"}).AddRow(produceID, "Produce", 1).AddRow(miscID, "Misc.", 2))
+	s.mock.ExpectQuery("^SELECT \"name\" FROM \"store_staple_items\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Bananas"))
+	settings := fmt.Sprintf(`{"bananas": "%s", "gum": "%s"}`, produceID, uuid.NewV4())
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_item_category_settings\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "items"}).AddRow(uuid.NewV4(), storeID, settings))
+
+	template, err := BuildStoreTemplate(storeID, true, true)
+	require.NoError(s.T(), err)
+	categories, _ := template.CategoryNames()
+	assert.Equal(s.T(), []string{"Produce", "Misc."}, categories)
+	staples, _ := template.StapleItemNames()
+	assert.Equal(s.T(), []string{"Bananas"}, staples)
+	// Settings pointing to a category that no longer exists are dropped
+	itemSettings, _ := template.ItemCategorySettingsMap()
+	assert.Equal(s.T(), map[string]string{"bananas": "Produce"}, itemSettings)
+}
+
 // Store users
 
 func (s *Suite) TestInviteToStoreByEmail_UserExistsNotYetAdded() {
@@ -425,7 +808,7 @@ func (s *Suite) TestInviteToStoreByEmail_UserExistsNotYetAdded() {
 		WillReturnRows(sqlmock.NewRows([]string{}))
 
 	s.mock.ExpectQuery("^INSERT INTO \"store_users\" (.+)$").
-		WithArgs(storeID, sqlmock.AnyArg(), email, false, false, AnyTime{}, AnyTime{}, nil).
+		WithArgs(storeID, sqlmock.AnyArg(), email, false, false, nil, AnyTime{}, AnyTime{}, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"store_id"}).AddRow(storeID))
 	s.mock.ExpectQuery("^SELECT name, user_id FROM \"stores\"*").
 		WithArgs(storeID).
@@ -613,10 +996,10 @@ func (s *Suite) TestAddUserToStoreWithCode_UserNotAlreadyInStore() {
 
 	storeUserID := uuid.NewV4()
 	s.mock.ExpectQuery("^INSERT INTO \"store_users\" (.+)$").
-		WithArgs(storeID, user.ID, "", false, true, AnyTime{}, AnyTime{}, nil).
+		WithArgs(storeID, user.ID, "", false, true, nil, AnyTime{}, AnyTime{}, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(storeUserID))
 	s.mock.ExpectQuery("^INSERT INTO \"store_user_preferences\" (.+)$").
-		WithArgs(storeUserID, false, true, AnyTime{}, AnyTime{}, nil).
+		WithArgs(storeUserID, false, true, true, true, true, true, true, true, true, false, false, false, false, false, false, false, AnyTime{}, AnyTime{}, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"store_user_id"}).AddRow(storeUserID))
 
 	su, err := AddUserToStoreWithCode(user, code, "Test")
@@ -659,7 +1042,7 @@ func (s *Suite) TestAddUserToStore_Success() {
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	s.mock.ExpectQuery("^INSERT INTO \"store_user_preferences\" (.+)$").
-		WithArgs(storeUserID, false, true, AnyTime{}, AnyTime{}, nil).
+		WithArgs(storeUserID, false, true, true, true, true, true, true, true, true, false, false, false, false, false, false, false, AnyTime{}, AnyTime{}, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"store_user_id"}).AddRow(storeUserID))
 
 	storeUser, err := AddUserToStore(user, storeID)
@@ -668,7 +1051,7 @@ func (s *Suite) TestAddUserToStore_Success() {
 	assert.Equal(s.T(), user.ID, storeUser.UserID)
 	assert.Equal(s.T(), "", storeUser.Email)
 	assert.Equal(s.T(), false, storeUser.Preferences.DefaultStore)
-	assert.Equal(s.T(), false, storeUser.Preferences.Notifications)
+	assert.Equal(s.T(), false, storeUser.Preferences.PushItemsAdded)
 }
 
 func (s *Suite) TestRetrieveStoreUsers_HasStoreUsers() {
@@ -812,7 +1195,7 @@ func (s *Suite) TestUpdateStoreUserPrefs_UpdateDefaultStoreMultiStores() {
 	storeUserPrefs, err := UpdateStoreUserPrefs(storeUserID, args)
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), true, storeUserPrefs.DefaultStore)
-	assert.Equal(s.T(), false, storeUserPrefs.Notifications)
+	assert.Equal(s.T(), false, storeUserPrefs.PushItemsAdded)
 }
 
 // TestUpdateStoreUserPrefs_UpdateDefaultStoreOnlyStores tests the case where
@@ -840,7 +1223,7 @@ func (s *Suite) TestUpdateStoreUserPrefs_UpdateDefaultStoreOnlyStores() {
 	storeUserPrefs, err := UpdateStoreUserPrefs(storeUserID, args)
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), true, storeUserPrefs.DefaultStore)
-	assert.Equal(s.T(), false, storeUserPrefs.Notifications)
+	assert.Equal(s.T(), false, storeUserPrefs.PushItemsAdded)
 }
 
 func (s *Suite) TestUpdateStoreUserPrefs_UpdateMultiColumns() {
@@ -868,7 +1251,7 @@ func (s *Suite) TestUpdateStoreUserPrefs_UpdateMultiColumns() {
 	storeUserPrefs, err := UpdateStoreUserPrefs(storeUserID, args)
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), true, storeUserPrefs.DefaultStore)
-	assert.Equal(s.T(), true, storeUserPrefs.Notifications)
+	assert.Equal(s.T(), true, storeUserPrefs.PushItemsAdded)
 }
 
 func (s *Suite) TestRetrieveStoreUserPrefs_NotFound() {
@@ -902,7 +1285,7 @@ func (s *Suite) TestSaveStapleItem_ItemNotFound() {
 		WillReturnRows(sqlmock.NewRows([]string{}))
 
 	storeID := uuid.NewV4()
-	_, err := SaveStapleItem(storeID, itemID)
+	_, err := SaveStapleItem(uuid.NewV4(), storeID, itemID)
 	require.Error(s.T(), err)
 	assert.Equal(s.T(), "record not found", err.Error())
 }
@@ -922,7 +1305,7 @@ func (s *Suite) TestSaveStapleItem_FindExistingStapleItem() {
 	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	stapleItem, err := SaveStapleItem(storeID, itemID)
+	stapleItem, err := SaveStapleItem(uuid.NewV4(), storeID, itemID)
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), stapleItemID, stapleItem.ID)
 }
@@ -940,24 +1323,56 @@ func (s *Suite) TestSaveStapleItem_CreateNewStapleItem() {
 		WithArgs(storeID, itemName).
 		WillReturnRows(sqlmock.NewRows([]string{}))
 	s.mock.ExpectQuery("^INSERT INTO \"store_staple_items\" (.+)$").
-		WithArgs(storeID, itemName, AnyTime{}, AnyTime{}, nil).
+		WithArgs(storeID, itemName, 1, nil, nil, nil, nil, "every_trip", 1, 0, AnyTime{}, nil, AnyTime{}, AnyTime{}, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(stapleItemID))
 
 	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	stapleItem, err := SaveStapleItem(storeID, itemID)
+	stapleItem, err := SaveStapleItem(uuid.NewV4(), storeID, itemID)
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), stapleItemID, stapleItem.ID)
 }
 
+func (s *Suite) TestSaveStapleItem_KeepsItemDetails() {
+	itemID := uuid.NewV4()
+	categoryID := uuid.NewV4()
+	itemName := "Milk"
+	notes := "2%"
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "category_id", "name", "quantity", "notes"}).AddRow(itemID, categoryID, itemName, 2, notes))
+
+	storeCategoryID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT \"store_category_id\" FROM \"grocery_trip_categories\"*").
+		WithArgs(categoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"store_category_id"}).AddRow(storeCategoryID))
+
+	stapleItemID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_staple_items\"*").
+		WithArgs(storeID, itemName).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+	s.mock.ExpectQuery("^INSERT INTO \"store_staple_items\" (.+)$").
+		WithArgs(storeID, itemName, 2, nil, nil, notes, storeCategoryID, "every_trip", 1, 0, AnyTime{}, nil, AnyTime{}, AnyTime{}, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(stapleItemID))
+
+	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	stapleItem, err := SaveStapleItem(uuid.NewV4(), storeID, itemID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, stapleItem.Quantity)
+	assert.Equal(s.T(), &storeCategoryID, stapleItem.StoreCategoryID)
+}
+
 func (s *Suite) TestRemoveStapleItem_StapleItemNotFound() {
 	itemID := uuid.NewV4()
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
 		WithArgs(itemID).
 		WillReturnRows(sqlmock.NewRows([]string{}))
 
-	_, err := RemoveStapleItem(itemID)
+	_, err := RemoveStapleItem(uuid.NewV4(), itemID)
 	require.Error(s.T(), err)
 	assert.Equal(s.T(), "record not found", err.Error())
 }
@@ -975,8 +1390,130 @@ func (s *Suite) TestRemoveStapleItem_StapleItemRemoved() {
 	s.mock.ExpectExec("^UPDATE \"items\"*").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	_, err := RemoveStapleItem(itemID)
+	_, err := RemoveStapleItem(uuid.NewV4(), itemID)
+	require.NoError(s.T(), err)
+}
+
+func (s *Suite) TestUpdateStapleItem_InvalidUnit() {
+	stapleItemID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT store_staple_items.\\* FROM \"store_staple_items\"*").
+		WithArgs(stapleItemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(stapleItemID, "Flour"))
+
+	args := map[string]interface{}{
+		"stapleItemId":    stapleItemID,
+		"decimalQuantity": 2.5,
+		"unit":            "cups",
+	}
+	_, err := UpdateStapleItem(userID, args)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "unit must be one of lb, oz, kg, g, l or ml", err.Error())
+}
+
+func (s *Suite) TestUpdateStapleItem_StoreCategoryNotInStore() {
+	stapleItemID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT store_staple_items.\\* FROM \"store_staple_items\"*").
+		WithArgs(stapleItemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name"}).AddRow(stapleItemID, storeID, "Flour"))
+
+	storeCategoryID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT \"id\" FROM \"store_categories\"*").
+		WithArgs(storeCategoryID, storeID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	args := map[string]interface{}{
+		"stapleItemId":    stapleItemID,
+		"storeCategoryId": storeCategoryID.String(),
+	}
+	_, err := UpdateStapleItem(userID, args)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "store category not found", err.Error())
+}
+
+func (s *Suite) TestUpdateStapleItem_Updated() {
+	stapleItemID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT store_staple_items.\\* FROM \"store_staple_items\"*").
+		WithArgs(stapleItemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "quantity"}).AddRow(stapleItemID, storeID, "Flour", 1))
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("^UPDATE \"store_staple_items\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// The changes are recorded in the store's activity log
+	changes := `{"decimalQuantity":{"before":null,"after":2.5},"notes":{"before":null,"after":"unbleached"},"unit":{"before":null,"after":"kg"}}`
+	s.mock.ExpectExec("^SAVEPOINT sp(.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(storeID, userID, "staple_changed", "staple_item", stapleItemID, "Flour", changes, AnyTime{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	args := map[string]interface{}{
+		"stapleItemId":    stapleItemID,
+		"decimalQuantity": 2.5,
+		"unit":            "Kg",
+		"notes":           "unbleached",
+	}
+	staple, err := UpdateStapleItem(userID, args)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2.5, *staple.DecimalQuantity)
+	assert.Equal(s.T(), "kg", *staple.Unit)
+	assert.Equal(s.T(), 1, staple.Quantity)
+	assert.Equal(s.T(), "unbleached", *staple.Notes)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestSetStapleItemCadence_InvalidWeekday() {
+	stapleItemID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT store_staple_items.\\* FROM \"store_staple_items\"*").
+		WithArgs(stapleItemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(stapleItemID, "Bread"))
+
+	args := map[string]interface{}{
+		"stapleItemId": stapleItemID,
+		"cadence":      "weekdays",
+		"weekdays":     []interface{}{1, 7},
+	}
+	_, err := SetStapleItemCadence(userID, args)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "weekdays must be between 0 (Sunday) and 6 (Saturday)", err.Error())
+}
+
+func (s *Suite) TestSetStapleItemCadence_EveryNTrips() {
+	stapleItemID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT store_staple_items.\\* FROM \"store_staple_items\"*").
+		WithArgs(stapleItemID, userID, true).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "store_id", "name", "cadence", "interval"}).
+			AddRow(stapleItemID, storeID, "Coffee", "every_trip", 1))
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("^UPDATE \"store_staple_items\" SET (.+)$").
+		WithArgs("trips", 2, 0, AnyTime{}, stapleItemID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^SAVEPOINT sp(.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(storeID, userID, "staple_changed", "staple_item", stapleItemID, "Coffee", `{"cadence":{"before":"every_trip","after":"trips"},"interval":{"before":1,"after":2}}`, AnyTime{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	args := map[string]interface{}{
+		"stapleItemId": stapleItemID,
+		"cadence":      "trips",
+		"interval":     2,
+	}
+	staple, err := SetStapleItemCadence(userID, args)
 	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "trips", staple.Cadence)
+	assert.Equal(s.T(), 2, staple.Interval)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
 }
 
 // TODO: duplicated code with the store model... DRY this up
@@ -0,0 +1,176 @@
+package stores
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// Parts of a store that can optionally be copied by DuplicateStore
+const (
+	DuplicateStaples          = "staples"
+	DuplicateCategorySettings = "categorySettings"
+	DuplicateMembers          = "members"
+)
+
+// SaveStoreTemplate saves the categories, staple items and item category
+// settings of a store as a template that the user can create new stores from
+func SaveStoreTemplate(storeID interface{}, userID uuid.UUID, name string) (template models.StoreTemplate, err error) {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return template, errors.New("template name can't be blank")
+	}
+	store, err := fetchMemberStore(storeID, userID)
+	if err != nil {
+		return template, err
+	}
+
+	template, err = BuildStoreTemplate(store.ID, true, true)
+	if err != nil {
+		return template, err
+	}
+	template.UserID = userID
+	template.Name = name
+	if err := db.Manager.Create(&template).Error; err != nil {
+		return template, err
+	}
+	return template, nil
+}
+
+// RetrieveStoreTemplates retrieves the store templates saved by the user
+func RetrieveStoreTemplates(userID uuid.UUID) (templates []models.StoreTemplate, err error) {
+	query := db.Manager.
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&templates).
+		Error
+	if err := query; err != nil {
+		return templates, err
+	}
+	return templates, nil
+}
+
+// DeleteStoreTemplate deletes one of the user's store templates
+func DeleteStoreTemplate(templateID interface{}, userID uuid.UUID) (template models.StoreTemplate, err error) {
+	if err := db.Manager.Where("id = ? AND user_id = ?", templateID, userID).First(&template).Error; err != nil {
+		return template, errors.New("couldn't retrieve store template")
+	}
+	if err := db.Manager.Delete(&template).Error; err != nil {
+		return template, err
+	}
+	return template, nil
+}
+
+// DuplicateStore creates a new store for the user with the same categories as
+// the store provided. include determines what else is copied over: staple
+// items, item category settings and/or members (who are invited to the new store)
+func DuplicateStore(storeID interface{}, userID uuid.UUID, name string, include []string) (store models.Store, err error) {
+	source, err := fetchMemberStore(storeID, userID)
+	if err != nil {
+		return store, err
+	}
+
+	includes := make(map[string]bool)
+	for i := range include {
+		includes[include[i]] = true
+	}
+	template, err := BuildStoreTemplate(source.ID, includes[DuplicateStaples], includes[DuplicateCategorySettings])
+	if err != nil {
+		return store, err
+	}
+	store, err = createStore(userID, name, &template)
+	if err != nil {
+		return store, err
+	}
+
+	if includes[DuplicateMembers] {
+		var emails []string
+		query := db.Manager.
+			Model(&models.StoreUser{}).
+			Select("users.email").
+			Joins("INNER JOIN users ON users.id = store_users.user_id").
+			Where("store_users.store_id = ?", source.ID).
+			Where("store_users.active = ?", true).
+			Where("store_users.user_id <> ?", userID).
+			Pluck("users.email", &emails).
+			Error
+		if err := query; err != nil {
+			return store, err
+		}
+		for i := range emails {
+			if _, err := InviteToStoreByEmail(store.ID, emails[i]); err != nil {
+				return store, err
+			}
+		}
+	}
+
+	return store, nil
+}
+
+// BuildStoreTemplate builds an unsaved template from a store's categories and,
+// optionally, its staple items and item category settings
+func BuildStoreTemplate(storeID uuid.UUID, includeStaples bool, includeSettings bool) (template models.StoreTemplate, err error) {
+	var categories []models.StoreCategory
+	query := db.Manager.
+		Where("store_id = ?", storeID).
+		Order("position ASC").
+		Find(&categories).
+		Error
+	if err := query; err != nil {
+		return template, err
+	}
+	categoryNames := make([]string, len(categories))
+	categoryNamesByID := make(map[string]string)
+	for i := range categories {
+		categoryNames[i] = categories[i].Name
+		categoryNamesByID[categories[i].ID.String()] = categories[i].Name
+	}
+	if template.Categories, err = json.Marshal(categoryNames); err != nil {
+		return template, err
+	}
+
+	if includeStaples {
+		var staples []string
+		query := db.Manager.
+			Model(&models.StoreStapleItem{}).
+			Where("store_id = ?", storeID).
+			Pluck("name", &staples).
+			Error
+		if err := query; err != nil {
+			return template, err
+		}
+		if template.StapleItems, err = json.Marshal(staples); err != nil {
+			return template, err
+		}
+	}
+
+	if includeSettings {
+		templateSettings := make(map[string]string)
+		var settings models.StoreItemCategorySettings
+		query := db.Manager.Where("store_id = ?", storeID).First(&settings).Error
+		if err := query; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return template, err
+		}
+		if len(settings.Items) > 0 {
+			var settingsMap map[string]string
+			if err := json.Unmarshal(settings.Items, &settingsMap); err != nil {
+				return template, err
+			}
+			for itemName, categoryID := range settingsMap {
+				if categoryName, ok := categoryNamesByID[categoryID]; ok {
+					templateSettings[itemName] = categoryName
+				}
+			}
+		}
+		if template.ItemCategorySettings, err = json.Marshal(templateSettings); err != nil {
+			return template, err
+		}
+	}
+
+	return template, nil
+}
@@ -1,9 +1,14 @@
 package stores
 
 import (
+	"errors"
+	"strings"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/mailer"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/utils"
 	uuid "github.com/satori/go.uuid"
 )
 
@@ -18,10 +23,23 @@ func UpdateStoreForUser(userID uuid.UUID, args map[string]interface{}) (interfac
 	if args["name"] != nil {
 		store.Name = args["name"].(string)
 	}
+	if err := updateStoreLocation(store, args); err != nil {
+		return nil, err
+	}
+	if args["mergeDuplicateItems"] != nil {
+		mergeDuplicateItems := args["mergeDuplicateItems"].(bool)
+		store.MergeDuplicateItems = &mergeDuplicateItems
+	}
 	if err := db.Manager.Save(&store).Error; err != nil {
 		return nil, err
 	}
 
+	if oldName != store.Name {
+		entity := activity.Entity{Type: activity.EntityStore, ID: store.ID, Name: store.Name}
+		changes := map[string]activity.Change{"name": {Before: oldName, After: store.Name}}
+		activity.Record(db.Manager, store.ID, userID, models.ActivityStoreRenamed, entity, changes)
+	}
+
 	// Finally, send an email to the users of this store about this update (excluding the creator)
 	if oldName != args["name"] {
 		rows, err := db.Manager.Raw("SELECT u.email FROM store_users AS su INNER JOIN users AS u ON su.user_id = u.id WHERE su.store_id = ? AND su.creator = ? ORDER BY su.created_at DESC", store.ID, false).Rows()
@@ -41,3 +59,32 @@ func UpdateStoreForUser(userID uuid.UUID, args map[string]interface{}) (interfac
 
 	return store, nil
 }
+
+// updateStoreLocation sets the location attributes of a store from the args
+// provided. Latitude and longitude must be provided together
+func updateStoreLocation(store *models.Store, args map[string]interface{}) error {
+	if args["address"] != nil {
+		address := strings.TrimSpace(args["address"].(string))
+		store.Address = &address
+	}
+	if args["latitude"] != nil || args["longitude"] != nil {
+		if args["latitude"] == nil || args["longitude"] == nil {
+			return errors.New("latitude and longitude must be provided together")
+		}
+		lat := args["latitude"].(float64)
+		lng := args["longitude"].(float64)
+		if !utils.ValidCoordinates(lat, lng) {
+			return errors.New("latitude or longitude is out of range")
+		}
+		store.Latitude = &lat
+		store.Longitude = &lng
+	}
+	if args["geofenceRadius"] != nil {
+		radius := args["geofenceRadius"].(int)
+		if radius <= 0 {
+			return errors.New("geofence radius must be greater than zero")
+		}
+		store.GeofenceRadius = &radius
+	}
+	return nil
+}
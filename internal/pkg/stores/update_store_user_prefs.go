@@ -19,8 +19,62 @@ func UpdateStoreUserPrefs(storeUserID uuid.UUID, args map[string]interface{}) (s
 	if args["defaultStore"] != nil {
 		sup.DefaultStore = args["defaultStore"].(bool)
 	}
+	if args["suggestions"] != nil {
+		sup.Suggestions = args["suggestions"].(bool)
+	}
+	// Note: notifications toggles every kind of push notification at once,
+	// and is kept for older clients
 	if args["notifications"] != nil {
-		sup.Notifications = args["notifications"].(bool)
+		enabled := args["notifications"].(bool)
+		sup.PushItemsAdded = enabled
+		sup.PushMeals = enabled
+		sup.PushMemberJoined = enabled
+		sup.PushStoreChanges = enabled
+		sup.PushTripReminders = enabled
+		sup.PushBudgetAlerts = enabled
+		sup.PushItemAssigned = enabled
+	}
+	if args["pushItemsAdded"] != nil {
+		sup.PushItemsAdded = args["pushItemsAdded"].(bool)
+	}
+	if args["pushMeals"] != nil {
+		sup.PushMeals = args["pushMeals"].(bool)
+	}
+	if args["pushMemberJoined"] != nil {
+		sup.PushMemberJoined = args["pushMemberJoined"].(bool)
+	}
+	if args["pushStoreChanges"] != nil {
+		sup.PushStoreChanges = args["pushStoreChanges"].(bool)
+	}
+	if args["pushTripReminders"] != nil {
+		sup.PushTripReminders = args["pushTripReminders"].(bool)
+	}
+	if args["pushBudgetAlerts"] != nil {
+		sup.PushBudgetAlerts = args["pushBudgetAlerts"].(bool)
+	}
+	if args["pushItemAssigned"] != nil {
+		sup.PushItemAssigned = args["pushItemAssigned"].(bool)
+	}
+	if args["emailItemsAdded"] != nil {
+		sup.EmailItemsAdded = args["emailItemsAdded"].(bool)
+	}
+	if args["emailMeals"] != nil {
+		sup.EmailMeals = args["emailMeals"].(bool)
+	}
+	if args["emailMemberJoined"] != nil {
+		sup.EmailMemberJoined = args["emailMemberJoined"].(bool)
+	}
+	if args["emailStoreChanges"] != nil {
+		sup.EmailStoreChanges = args["emailStoreChanges"].(bool)
+	}
+	if args["emailTripReminders"] != nil {
+		sup.EmailTripReminders = args["emailTripReminders"].(bool)
+	}
+	if args["emailBudgetAlerts"] != nil {
+		sup.EmailBudgetAlerts = args["emailBudgetAlerts"].(bool)
+	}
+	if args["emailItemAssigned"] != nil {
+		sup.EmailItemAssigned = args["emailItemAssigned"].(bool)
 	}
 
 	if err := db.Manager.Save(&sup).Error; err != nil {
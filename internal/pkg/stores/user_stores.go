@@ -16,6 +16,7 @@ func RetrieveUserStores(user models.User) (stores []models.Store, err error) {
 		Joins("INNER JOIN store_user_preferences ON store_user_preferences.store_user_id = store_users.id").
 		Joins("LEFT OUTER JOIN grocery_trips ON grocery_trips.store_id = stores.id").
 		Where("store_users.user_id = ?", user.ID).
+		Where("stores.archived_at IS NULL").
 		Group("stores.id, store_user_preferences.default_store").
 		Order("store_user_preferences.default_store DESC, MAX(grocery_trips.updated_at) DESC").
 		Find(&stores).
@@ -35,6 +36,7 @@ func RetrieveInvitedUserStores(user models.User) (stores []models.Store, err err
 		Where("store_users.deleted_at IS NULL").
 		Where("store_users.email = ?", user.Email).
 		Where("store_users.active = ?", false).
+		Where("stores.archived_at IS NULL").
 		Group("stores.id").
 		Order("MAX(grocery_trips.updated_at) DESC").
 		Find(&stores).
@@ -3,6 +3,7 @@ package stores
 import (
 	"errors"
 
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/mailer"
@@ -60,6 +61,8 @@ func AddUserToStoreWithCode(user models.User, code string, appScheme string) (su
 		return su, err
 	}
 
+	activity.Record(db.Manager, store.ID, user.ID, models.ActivityMemberJoined, memberEntity(storeUser, user), nil)
+
 	// Send a notification to users in store informing them someone joined their store
 	go notifications.UserJoinedStore(user, store.ID, appScheme)
 
@@ -97,6 +100,8 @@ func AddUserToStore(user models.User, storeID interface{}) (su models.StoreUser,
 		return su, err
 	}
 
+	activity.Record(db.Manager, storeUser.StoreID, user.ID, models.ActivityMemberJoined, memberEntity(storeUser, user), nil)
+
 	return storeUser, nil
 }
 
@@ -150,6 +155,7 @@ func RemoveUserFromStore(user models.User, storeID interface{}) (interface{}, er
 		if err != nil {
 			return nil, err
 		}
+		activity.Record(db.Manager, store.ID, user.ID, models.ActivityMemberLeft, memberEntity(*storeUser, user), nil)
 	}
 
 	return storeUser, nil
@@ -187,3 +193,8 @@ func RetrieveStoreCreator(storeID uuid.UUID) (interface{}, error) {
 	}
 	return user, nil
 }
+
+// memberEntity identifies a store member in the store activity log
+func memberEntity(storeUser models.StoreUser, user models.User) activity.Entity {
+	return activity.Entity{Type: activity.EntityStoreUser, ID: storeUser.ID, Name: user.Name}
+}
@@ -0,0 +1,48 @@
+package suggestions
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
+	uuid "github.com/satori/go.uuid"
+)
+
+// DismissSuggestedItem hides a suggested item from the user until the item
+// is bought from the store again
+func DismissSuggestedItem(userID uuid.UUID, storeID interface{}, name string) (dismissal models.SuggestionDismissal, err error) {
+	store, err := stores.RetrieveStoreForUser(storeID, userID)
+	if err != nil {
+		return dismissal, errors.New("store not found")
+	}
+	name = normalise(name)
+	if name == "" {
+		return dismissal, errors.New("name can't be blank")
+	}
+
+	query := db.Manager.
+		Where(models.SuggestionDismissal{StoreID: store.ID, UserID: userID, Name: name}).
+		Assign(models.SuggestionDismissal{DismissedAt: time.Now()}).
+		FirstOrCreate(&dismissal).
+		Error
+	if err := query; err != nil {
+		return dismissal, err
+	}
+	return dismissal, nil
+}
+
+// dismissedNames returns when the user dismissed each suggested item they've
+// dismissed for a store, by normalised name
+func dismissedNames(storeID uuid.UUID, userID uuid.UUID) (dismissed map[string]time.Time, err error) {
+	var dismissals []models.SuggestionDismissal
+	if err := db.Manager.Where("store_id = ? AND user_id = ?", storeID, userID).Find(&dismissals).Error; err != nil {
+		return dismissed, err
+	}
+	dismissed = map[string]time.Time{}
+	for i := range dismissals {
+		dismissed[dismissals[i].Name] = dismissals[i].DismissedAt
+	}
+	return dismissed, nil
+}
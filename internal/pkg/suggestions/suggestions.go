@@ -0,0 +1,253 @@
+package suggestions
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	// MinPurchases is how many times an item must have been bought before
+	// it's suggested, so there's enough history to know how often it's bought
+	MinPurchases = 3
+	// HistoryPeriod is how far back purchases are considered
+	HistoryPeriod = 365 * 24 * time.Hour
+	// DueRatio is how much of an item's typical interval must have passed
+	// since it was last bought for it to be suggested
+	DueRatio = 0.8
+	// StaleRatio is how many of an item's typical intervals can pass since
+	// it was last bought before it's assumed to no longer be bought
+	StaleRatio = 3.0
+)
+
+// SuggestedItem is an item that's probably due to be bought again, based on
+// how often it's been bought from a store
+type SuggestedItem struct {
+	Name            string
+	PurchaseCount   int
+	IntervalDays    int
+	LastPurchasedAt time.Time
+	// Reason explains the suggestion, i.e. "bought every ~9 days, last
+	// bought 12 days ago"
+	Reason string
+
+	// overdue is how many typical intervals have passed since the item was
+	// last bought, for ordering suggestions
+	overdue float64
+}
+
+// purchase is a completed item in a completed trip
+type purchase struct {
+	Name          string
+	GroceryTripID uuid.UUID
+	PurchasedAt   time.Time
+}
+
+// history is the purchases of an item from a store, oldest first
+type history struct {
+	name  string
+	dates []time.Time
+}
+
+// RetrieveSuggestedItems retrieves the items the user probably needs to buy
+// from a store, most overdue first. Items already on an open trip, staple
+// items and suggestions the user has dismissed are left out
+func RetrieveSuggestedItems(userID uuid.UUID, storeID interface{}) (suggested []SuggestedItem, err error) {
+	store, err := stores.RetrieveStoreForUser(storeID, userID)
+	if err != nil {
+		return suggested, errors.New("store not found")
+	}
+	storeUserID, err := stores.RetrieveStoreUserID(store.ID, userID)
+	if err != nil {
+		return suggested, err
+	}
+	prefs, err := stores.RetrieveStoreUserPrefs(storeUserID)
+	if err != nil {
+		return suggested, err
+	}
+	suggested = []SuggestedItem{}
+	if !prefs.Suggestions {
+		return suggested, nil
+	}
+
+	now := time.Now()
+	var purchases []purchase
+	query := db.Manager.
+		Model(&models.Item{}).
+		Select("items.name, items.grocery_trip_id, items.updated_at AS purchased_at").
+		Joins("INNER JOIN grocery_trips ON grocery_trips.id = items.grocery_trip_id").
+		Where("grocery_trips.store_id = ? AND grocery_trips.completed = ? AND grocery_trips.deleted_at IS NULL", store.ID, true).
+		Where("items.completed = ? AND items.updated_at > ?", true, now.Add(-HistoryPeriod)).
+		Order("items.updated_at ASC").
+		Scan(&purchases).
+		Error
+	if err := query; err != nil {
+		return suggested, err
+	}
+	if len(purchases) == 0 {
+		return suggested, nil
+	}
+
+	excluded, err := excludedNames(store.ID)
+	if err != nil {
+		return suggested, err
+	}
+	dismissed, err := dismissedNames(store.ID, userID)
+	if err != nil {
+		return suggested, err
+	}
+
+	for _, h := range histories(purchases) {
+		name := normalise(h.name)
+		if excluded[name] {
+			continue
+		}
+		item, ok := h.suggest(now)
+		if !ok {
+			continue
+		}
+		if dismissedAt, ok := dismissed[name]; ok && dismissedAt.After(item.LastPurchasedAt) {
+			continue
+		}
+		suggested = append(suggested, item)
+	}
+
+	sort.SliceStable(suggested, func(i, j int) bool {
+		return suggested[i].overdue > suggested[j].overdue
+	})
+	return suggested, nil
+}
+
+// histories groups purchases (oldest first) by item name. An item bought
+// more than once in the same trip or day counts as a single purchase, and
+// the name of the item's latest purchase is used
+func histories(purchases []purchase) []*history {
+	var grouped []*history
+	byName := map[string]*history{}
+	lastTrip := map[string]uuid.UUID{}
+	for _, p := range purchases {
+		name := normalise(p.Name)
+		if name == "" {
+			continue
+		}
+		h, ok := byName[name]
+		if !ok {
+			h = &history{}
+			byName[name] = h
+			grouped = append(grouped, h)
+		}
+		h.name = strings.TrimSpace(p.Name)
+		if len(h.dates) > 0 {
+			last := h.dates[len(h.dates)-1]
+			if lastTrip[name] == p.GroceryTripID || p.PurchasedAt.Sub(last) < 12*time.Hour {
+				h.dates[len(h.dates)-1] = p.PurchasedAt
+				lastTrip[name] = p.GroceryTripID
+				continue
+			}
+		}
+		h.dates = append(h.dates, p.PurchasedAt)
+		lastTrip[name] = p.GroceryTripID
+	}
+	return grouped
+}
+
+// suggest returns the item as a suggestion if it's probably due to be bought
+// again: its typical (median) interval has nearly passed since it was last
+// bought, but not so long ago that it's probably no longer bought
+func (h *history) suggest(now time.Time) (item SuggestedItem, ok bool) {
+	if len(h.dates) < MinPurchases {
+		return item, false
+	}
+	intervals := make([]float64, 0, len(h.dates)-1)
+	for i := 1; i < len(h.dates); i++ {
+		intervals = append(intervals, h.dates[i].Sub(h.dates[i-1]).Hours()/24)
+	}
+	interval := median(intervals)
+	if interval < 1 {
+		return item, false
+	}
+
+	last := h.dates[len(h.dates)-1]
+	since := now.Sub(last).Hours() / 24
+	overdue := since / interval
+	if overdue < DueRatio || overdue > StaleRatio {
+		return item, false
+	}
+
+	intervalDays := int(math.Round(interval))
+	item = SuggestedItem{
+		Name:            h.name,
+		PurchaseCount:   len(h.dates),
+		IntervalDays:    intervalDays,
+		LastPurchasedAt: last,
+		Reason:          fmt.Sprintf("bought every ~%s, last bought %s", days(intervalDays), daysAgo(int(math.Round(since)))),
+		overdue:         overdue,
+	}
+	return item, true
+}
+
+// excludedNames returns the normalised names of the items that shouldn't be
+// suggested for a store: those on an open trip, and its staple items (which
+// are added to trips anyway)
+func excludedNames(storeID uuid.UUID) (excluded map[string]bool, err error) {
+	var tripItems []string
+	query := db.Manager.
+		Model(&models.Item{}).
+		Joins("INNER JOIN grocery_trips ON grocery_trips.id = items.grocery_trip_id").
+		Where("grocery_trips.store_id = ? AND grocery_trips.completed = ? AND grocery_trips.deleted_at IS NULL", storeID, false).
+		Pluck("items.name", &tripItems).
+		Error
+	if err := query; err != nil {
+		return excluded, err
+	}
+	var staples []string
+	if err := db.Manager.Model(&models.StoreStapleItem{}).Where("store_id = ?", storeID).Pluck("name", &staples).Error; err != nil {
+		return excluded, err
+	}
+
+	excluded = map[string]bool{}
+	for _, name := range append(tripItems, staples...) {
+		excluded[normalise(name)] = true
+	}
+	return excluded, nil
+}
+
+// median returns the median of values, which mustn't be empty
+func median(values []float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// days formats a number of days, i.e. "1 day" or "9 days"
+func days(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
+
+// daysAgo formats how many days ago something happened
+func daysAgo(n int) string {
+	if n == 0 {
+		return "today"
+	}
+	return days(n) + " ago"
+}
+
+// normalise normalises an item name for comparison
+func normalise(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,165 @@
+package suggestions
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type AnyTime struct{}
+
+// Match satisfies sqlmock.Argument interface
+func (a AnyTime) Match(v driver.Value) bool {
+	_, ok := v.(time.Time)
+	return ok
+}
+
+type Suite struct {
+	suite.Suite
+
+	DB   *gorm.DB
+	mock sqlmock.Sqlmock
+}
+
+func (s *Suite) SetupSuite() {
+	var (
+		dbMock *sql.DB
+		err    error
+	)
+
+	dbMock, s.mock, err = sqlmock.New()
+	require.NoError(s.T(), err)
+	s.DB, err = gorm.Open(postgres.New(postgres.Config{Conn: dbMock}), &gorm.Config{})
+	require.NoError(s.T(), err)
+
+	db.Manager = s.DB
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+// daysBefore returns the time the number of days before now
+func daysBefore(now time.Time, n int) time.Time {
+	return now.Add(-time.Duration(n) * 24 * time.Hour)
+}
+
+func (s *Suite) expectStoreUserPrefs(storeID uuid.UUID, userID uuid.UUID, suggestions bool) {
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(storeID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	storeUserID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT \"id\" FROM \"store_users\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(storeUserID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_user_preferences\"*").
+		WithArgs(storeUserID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_user_id", "suggestions"}).AddRow(uuid.NewV4(), storeUserID, suggestions))
+}
+
+func (s *Suite) TestRetrieveSuggestedItems_OptedOut() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.expectStoreUserPrefs(storeID, userID, false)
+
+	suggested, err := RetrieveSuggestedItems(userID, storeID)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), suggested, 0)
+}
+
+func (s *Suite) TestRetrieveSuggestedItems_Suggested() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.expectStoreUserPrefs(storeID, userID, true)
+
+	now := time.Now()
+	purchases := sqlmock.NewRows([]string{"name", "grocery_trip_id", "purchased_at"})
+	for _, n := range []int{30, 21, 12} {
+		tripID := uuid.NewV4()
+		purchases.AddRow("Milk", tripID, daysBefore(now, n))
+		purchases.AddRow("Eggs", tripID, daysBefore(now, n))
+		purchases.AddRow("Coffee", tripID, daysBefore(now, n))
+	}
+	s.mock.ExpectQuery("^SELECT items.name, items.grocery_trip_id, items.updated_at AS purchased_at FROM \"items\"*").
+		WithArgs(storeID, true, true, AnyTime{}).
+		WillReturnRows(purchases)
+	s.mock.ExpectQuery("^SELECT \"items\".\"name\" FROM \"items\"*").
+		WithArgs(storeID, false).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("eggs "))
+	s.mock.ExpectQuery("^SELECT \"name\" FROM \"store_staple_items\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"suggestion_dismissals\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "dismissed_at"}).AddRow("coffee", daysBefore(now, 1)))
+
+	suggested, err := RetrieveSuggestedItems(userID, storeID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), suggested, 1)
+	assert.Equal(s.T(), "Milk", suggested[0].Name)
+	assert.Equal(s.T(), 3, suggested[0].PurchaseCount)
+	assert.Equal(s.T(), "bought every ~9 days, last bought 12 days ago", suggested[0].Reason)
+}
+
+func (s *Suite) TestDismissSuggestedItem_BlankName() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(storeID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+
+	_, err := DismissSuggestedItem(userID, storeID, "  ")
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "name can't be blank", err.Error())
+}
+
+func (s *Suite) TestHistories_GroupsPurchases() {
+	now := time.Now()
+	tripID := uuid.NewV4()
+	purchases := []purchase{
+		{Name: "bananas", GroceryTripID: uuid.NewV4(), PurchasedAt: daysBefore(now, 14)},
+		{Name: "Bananas ", GroceryTripID: tripID, PurchasedAt: daysBefore(now, 7)},
+		{Name: "Bananas", GroceryTripID: tripID, PurchasedAt: daysBefore(now, 6)},
+	}
+	grouped := histories(purchases)
+	require.Len(s.T(), grouped, 1)
+	assert.Equal(s.T(), "Bananas", grouped[0].name)
+	assert.Len(s.T(), grouped[0].dates, 2)
+}
+
+func (s *Suite) TestSuggest() {
+	now := time.Now()
+	h := &history{name: "Bread", dates: []time.Time{daysBefore(now, 15), daysBefore(now, 8), daysBefore(now, 1)}}
+	_, ok := h.suggest(now)
+	assert.False(s.T(), ok, "not due yet")
+
+	h.dates = []time.Time{daysBefore(now, 60), daysBefore(now, 53), daysBefore(now, 46)}
+	_, ok = h.suggest(now)
+	assert.False(s.T(), ok, "probably no longer bought")
+
+	h.dates = []time.Time{daysBefore(now, 8), daysBefore(now, 7)}
+	_, ok = h.suggest(now)
+	assert.False(s.T(), ok, "not enough purchases")
+
+	h.dates = []time.Time{daysBefore(now, 22), daysBefore(now, 15), daysBefore(now, 8)}
+	item, ok := h.suggest(now)
+	require.True(s.T(), ok)
+	assert.Equal(s.T(), 7, item.IntervalDays)
+	assert.Equal(s.T(), "bought every ~7 days, last bought 8 days ago", item.Reason)
+}
@@ -2,14 +2,43 @@ package trips
 
 import (
 	_ "embed"
+	"errors"
+	"log"
+	"strings"
 
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/parser"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
 	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
 )
 
-// AddItem adds an item to a trip and handles things like permission checks
+// AddItem adds an item to a trip and handles things like permission checks.
+// If the item is already on the trip (and the store merges duplicate items)
+// it's merged into the existing item, which is returned with Merged set
 func AddItem(userID uuid.UUID, args map[string]interface{}) (addedItem *models.Item, err error) {
+	item, changes, err := addItem(db.Manager, userID, args)
+	if err != nil {
+		return addedItem, err
+	}
+	if item.UnitPrice != nil {
+		checkTripBudget(item.GroceryTripID)
+	}
+	action := models.ActivityItemAdded
+	if item.Merged {
+		action = models.ActivityItemMerged
+	}
+	activity.RecordForTrip(db.Manager, item.GroceryTripID, userID, action, itemEntity(item), changes)
+	return item, nil
+}
+
+// addItem adds an item to a trip without recording it in the store's activity
+// log or checking the trip's budget, so that it can be part of a larger
+// transaction (tx). When the item is merged into an existing item, the changes
+// made to it are returned as well
+func addItem(tx *gorm.DB, userID uuid.UUID, args map[string]interface{}) (addedItem *models.Item, changes map[string]activity.Change, err error) {
 	tripID := args["tripId"].(uuid.UUID)
 
 	itemCompleted := false
@@ -27,14 +56,127 @@ func AddItem(userID uuid.UUID, args map[string]interface{}) (addedItem *models.I
 		Position:      1,
 		Completed:     &itemCompleted,
 	}
+	if args["locale"] != nil {
+		item.Locale = args["locale"].(string)
+	}
+	if args["notes"] != nil {
+		notes := args["notes"].(string)
+		item.Notes = &notes
+	}
 
 	if args["stapleItemId"] != nil {
 		stapleItemID := args["stapleItemId"].(uuid.UUID)
 		item.StapleItemID = &stapleItemID
 	}
+	if args["barcode"] != nil {
+		barcode := args["barcode"].(string)
+		item.Barcode = &barcode
+	}
+	if args["defaultCategoryName"] != nil {
+		item.DefaultCategoryName = args["defaultCategoryName"].(string)
+	}
+	if args["pinnedStoreCategoryId"] != nil {
+		storeCategoryID := args["pinnedStoreCategoryId"].(uuid.UUID)
+		item.PinnedStoreCategoryID = &storeCategoryID
+	}
+	if args["mealId"] != nil {
+		mealID := args["mealId"].(uuid.UUID)
+		mealName := args["mealName"].(string)
+		item.MealID = &mealID
+		item.MealName = &mealName
+	}
+	if err := setItemAmount(item, args); err != nil {
+		return addedItem, nil, err
+	}
+	if err := setItemPrice(item, args); err != nil {
+		return addedItem, nil, err
+	}
 
-	if err := db.Manager.Create(&item).Error; err != nil {
-		return addedItem, err
+	item.ParseName()
+	existing, err := findMergeableItem(tx, item)
+	if err != nil {
+		return addedItem, nil, err
 	}
-	return item, nil
+	if existing != nil {
+		changes, err := mergeItem(tx, existing, item)
+		if err != nil {
+			return addedItem, nil, err
+		}
+		return existing, changes, nil
+	}
+
+	if err := tx.Create(&item).Error; err != nil {
+		return addedItem, nil, err
+	}
+	return item, nil, nil
+}
+
+// setItemAmount sets the decimal quantity and unit of an item from the
+// decimalQuantity and unit args, if provided. A quantity arg on its own (from
+// older app versions) replaces any measured amount on the item
+func setItemAmount(item *models.Item, args map[string]interface{}) error {
+	if args["quantity"] != nil && args["decimalQuantity"] == nil {
+		item.DecimalQuantity = nil
+		item.Unit = nil
+	}
+	if args["decimalQuantity"] != nil {
+		decimalQuantity := args["decimalQuantity"].(float64)
+		if decimalQuantity <= 0 {
+			return errors.New("quantity must be greater than zero")
+		}
+		item.DecimalQuantity = &decimalQuantity
+	}
+	if args["unit"] != nil {
+		unit := args["unit"].(string)
+		if strings.TrimSpace(unit) == "" {
+			item.Unit = nil
+		} else {
+			normalised, ok := parser.NormaliseUnit(unit)
+			if !ok {
+				return errors.New("unit must be one of lb, oz, kg, g, l or ml")
+			}
+			item.Unit = &normalised
+		}
+	}
+	if item.Unit != nil && item.DecimalQuantity == nil {
+		decimalQuantity := float64(item.Quantity)
+		item.DecimalQuantity = &decimalQuantity
+	}
+	if item.DecimalQuantity != nil {
+		item.Quantity = parser.WholeQuantity(*item.DecimalQuantity, item.Unit)
+	}
+	return nil
+}
+
+// setItemPrice sets the unit price and currency of an item from the
+// unitPrice and currency args, if provided
+func setItemPrice(item *models.Item, args map[string]interface{}) error {
+	if args["unitPrice"] != nil {
+		unitPrice := int64(args["unitPrice"].(int))
+		if unitPrice < 0 {
+			return errors.New("price can't be negative")
+		}
+		item.UnitPrice = &unitPrice
+	}
+	if args["currency"] != nil {
+		currency := strings.ToUpper(strings.TrimSpace(args["currency"].(string)))
+		if !models.ValidCurrency(currency) {
+			return errors.New("currency must be a three letter currency code")
+		}
+		item.Currency = &currency
+	}
+	return nil
+}
+
+// checkTripBudget checks whether a trip has gone over its store's budget,
+// logging (but otherwise ignoring) errors
+func checkTripBudget(tripID uuid.UUID) {
+	if err := stores.CheckTripBudget(tripID); err != nil {
+		log.Printf("[trips] budget check for trip %v: %v\n", tripID, err)
+	}
+}
+
+// itemEntity identifies an item in the store activity log
+func itemEntity(item *models.Item) activity.Entity {
+	return activity.Entity{Type: activity.EntityItem, ID: item.ID, Name: item.Name}
 }
@@ -0,0 +1,70 @@
+package trips
+
+import (
+	"errors"
+	"log"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/catalogue"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/utils"
+	uuid "github.com/satori/go.uuid"
+)
+
+// AddItemByBarcode adds the product with a UPC/EAN barcode to a trip. Codes
+// that aren't in the catalogue (and haven't been named for the store) are
+// added as a placeholder with NeedsName set, and the name the user gives the
+// item is remembered for the store's household
+func AddItemByBarcode(userID uuid.UUID, args map[string]interface{}) (addedItem *models.Item, err error) {
+	code, err := catalogue.NormaliseCode(args["code"].(string))
+	if err != nil {
+		return addedItem, err
+	}
+	tripID := args["tripId"].(uuid.UUID)
+	var store models.Store
+	query := db.Manager.
+		Select("stores.id, stores.household_id").
+		Joins("INNER JOIN grocery_trips ON grocery_trips.store_id = stores.id").
+		Where("grocery_trips.id = ?", tripID).
+		First(&store).
+		Error
+	if err := query; err != nil {
+		return addedItem, errors.New("trip does not exist")
+	}
+	lookup, err := catalogue.LookupCode(code, store)
+	if err != nil {
+		return addedItem, err
+	}
+
+	itemArgs := map[string]interface{}{
+		"tripId":  tripID,
+		"name":    utils.TruncateString(lookup.Name, 100),
+		"barcode": code,
+		"locale":  args["locale"],
+	}
+	if lookup.Notes != "" {
+		itemArgs["notes"] = lookup.Notes
+	}
+	if lookup.CategoryName != "" {
+		itemArgs["defaultCategoryName"] = lookup.CategoryName
+	}
+	item, err := AddItem(userID, itemArgs)
+	if err != nil {
+		return addedItem, err
+	}
+	item.NeedsName = !lookup.Known
+	return item, nil
+}
+
+// rememberProductName remembers the name a user gave an item scanned with a
+// barcode, logging (but otherwise ignoring) errors
+func rememberProductName(item *models.Item, userID uuid.UUID) {
+	var trip models.GroceryTrip
+	if err := db.Manager.Select("store_id").Where("id = ?", item.GroceryTripID).First(&trip).Error; err != nil {
+		log.Printf("[trips] remember name for barcode %v: %v\n", *item.Barcode, err)
+		return
+	}
+	if err := catalogue.RememberProductName(*item.Barcode, trip.StoreID, userID, item.Name); err != nil {
+		log.Printf("[trips] remember name for barcode %v: %v\n", *item.Barcode, err)
+	}
+}
@@ -12,7 +12,8 @@ import (
 )
 
 // AddItemsToStore adds an array of items to a store for a user. It creates
-// the store for the user if it doesn't already exist.
+// the store for the user if it doesn't already exist. The items are attributed
+// to a meal when the mealId and mealName args are provided
 func AddItemsToStore(userID uuid.UUID, args map[string]interface{}) (addedItems []*models.Item, err error) {
 	var store models.Store
 	storeName, val := args["storeName"]
@@ -40,12 +41,19 @@ func AddItemsToStore(userID uuid.UUID, args map[string]interface{}) (addedItems
 	itemNames := args["items"].([]interface{})
 	for i := range itemNames {
 		itemName := itemNames[i].(string)
-		args := map[string]interface{}{
+		itemArgs := map[string]interface{}{
 			"tripId":   trip.ID,
 			"name":     itemName,
 			"quantity": 1,
 		}
-		item, err := AddItem(userID, args)
+		if args["locale"] != nil {
+			itemArgs["locale"] = args["locale"]
+		}
+		if args["mealId"] != nil {
+			itemArgs["mealId"] = args["mealId"]
+			itemArgs["mealName"] = args["mealName"]
+		}
+		item, err := AddItem(userID, itemArgs)
 		if err != nil {
 			errorStrings = append(errorStrings, err.Error())
 		}
@@ -86,6 +94,7 @@ func FindDefaultStore(userID uuid.UUID) (store models.Store, err error) {
 		Joins("INNER JOIN store_user_preferences ON store_user_preferences.store_user_id = store_users.id").
 		Where("store_users.user_id = ?", userID).
 		Where("store_user_preferences.default_store = ?", true).
+		Where("stores.archived_at IS NULL").
 		Last(&store).
 		Error
 	if err := query; err != nil {
@@ -0,0 +1,70 @@
+package trips
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+)
+
+// AssignItem assigns an item to a member of its store, or unassigns it when
+// no userId is provided. Both the user making the assignment and the assignee
+// must be active members of the store
+func AssignItem(userID uuid.UUID, args map[string]interface{}) (item models.Item, err error) {
+	query := db.Manager.
+		Select("items.*").
+		Joins("INNER JOIN grocery_trips ON grocery_trips.id = items.grocery_trip_id").
+		Joins("INNER JOIN store_users ON store_users.store_id = grocery_trips.store_id").
+		Where("items.id = ? AND store_users.user_id = ? AND store_users.active = ?", args["itemId"], userID, true).
+		First(&item).
+		Error
+	if err := query; err != nil {
+		return item, errors.New("item not found")
+	}
+	var trip models.GroceryTrip
+	if err := db.Manager.Select("store_id").Where("id = ?", item.GroceryTripID).First(&trip).Error; err != nil {
+		return item, err
+	}
+	storeID := trip.StoreID
+
+	var assigneeID *uuid.UUID
+	if args["userId"] != nil {
+		id, err := uuid.FromString(args["userId"].(string))
+		if err != nil {
+			return item, errors.New("userId arg not a UUID")
+		}
+		var count int64
+		memberQuery := db.Manager.
+			Model(&models.StoreUser{}).
+			Where("store_id = ? AND user_id = ? AND active = ?", storeID, id, true).
+			Count(&count).
+			Error
+		if err := memberQuery; err != nil {
+			return item, err
+		}
+		if count == 0 {
+			return item, errors.New("assignee is not a member of this store")
+		}
+		assigneeID = &id
+	}
+
+	before := activity.ItemAttributes(item)
+	item.AssigneeID = assigneeID
+	// UpdateColumns to avoid the item hooks, which would recategorise the item
+	updates := map[string]interface{}{
+		"assignee_id": assigneeID,
+		"updated_at":  time.Now(),
+	}
+	if err := db.Manager.Model(&item).UpdateColumns(updates).Error; err != nil {
+		return item, err
+	}
+
+	changes := activity.Diff(before, activity.ItemAttributes(item))
+	if len(changes) > 0 {
+		activity.Record(db.Manager, storeID, userID, models.ActivityItemAssigned, itemEntity(&item), changes)
+	}
+	return item, nil
+}
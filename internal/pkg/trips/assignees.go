@@ -0,0 +1,60 @@
+package trips
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// RetrieveAssigneeCompletion retrieves how many of the items assigned to each
+// store member in a trip have been completed. For completed trips, this is
+// what was recorded when the trip was completed
+func RetrieveAssigneeCompletion(trip models.GroceryTrip) (completion []models.AssigneeCompletion, err error) {
+	if trip.Completed {
+		query := db.Manager.
+			Where("grocery_trip_id = ?", trip.ID).
+			Order("assigned DESC").
+			Find(&completion).
+			Error
+		if err := query; err != nil {
+			return completion, err
+		}
+		return completion, nil
+	}
+	return countAssigneeCompletion(db.Manager, trip.ID)
+}
+
+// recordAssigneeCompletion records how many of the items assigned to each
+// store member were completed, for a trip that's being completed. This must
+// happen before its remaining items are marked as completed
+func recordAssigneeCompletion(trip models.GroceryTrip) error {
+	completion, err := countAssigneeCompletion(db.Manager, trip.ID)
+	if err != nil {
+		return err
+	}
+	if len(completion) == 0 {
+		return nil
+	}
+	for i := range completion {
+		completion[i].GroceryTripID = trip.ID
+	}
+	return db.Manager.Create(&completion).Error
+}
+
+// countAssigneeCompletion counts the assigned and completed items in a trip
+// for each assignee
+func countAssigneeCompletion(tx *gorm.DB, tripID uuid.UUID) (completion []models.AssigneeCompletion, err error) {
+	query := tx.
+		Model(&models.Item{}).
+		Select("assignee_id AS user_id, COUNT(*) AS assigned, COUNT(*) FILTER (WHERE completed) AS completed").
+		Where("grocery_trip_id = ? AND assignee_id IS NOT NULL", tripID).
+		Group("assignee_id").
+		Order("assigned DESC").
+		Scan(&completion).
+		Error
+	if err := query; err != nil {
+		return completion, err
+	}
+	return completion, nil
+}
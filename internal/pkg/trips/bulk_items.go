@@ -0,0 +1,452 @@
+package trips
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// MaxBulkItems is the most items that can be changed by a bulk operation
+const MaxBulkItems = 200
+
+// bulkItems is the items changed by a bulk operation, along with their trips
+// in the order they were first seen
+type bulkItems struct {
+	ids     []uuid.UUID
+	items   []*models.Item
+	tripIDs []uuid.UUID
+	trips   map[uuid.UUID]models.GroceryTrip
+}
+
+// CompleteItems marks items as completed (or not completed) at once. Completed
+// items are moved to the bottom of their categories, and uncompleted items to
+// the top
+func CompleteItems(userID uuid.UUID, args map[string]interface{}) (items []*models.Item, err error) {
+	bulk, err := retrieveBulkItems(userID, args["itemIds"])
+	if err != nil {
+		return items, err
+	}
+	completed := true
+	if args["completed"] != nil {
+		completed = args["completed"].(bool)
+	}
+
+	action := models.ActivityItemsUncompleted
+	if completed {
+		action = models.ActivityItemsCompleted
+	}
+	err = db.Manager.Transaction(func(tx *gorm.DB) error {
+		// UpdateColumns to avoid the item hooks, which would recategorise the
+		// items
+		updates := map[string]interface{}{
+			"completed":  completed,
+			"updated_at": time.Now(),
+		}
+		if err := tx.Model(&models.Item{}).Where("id IN ?", bulk.ids).UpdateColumns(updates).Error; err != nil {
+			return err
+		}
+		if err := rankItems(tx, bulk.items, !completed); err != nil {
+			return err
+		}
+		if err := touchTrips(tx, bulk.tripIDs); err != nil {
+			return err
+		}
+		bulk.record(tx, userID, action, nil)
+		return nil
+	})
+	if err != nil {
+		return items, err
+	}
+
+	items, err = reloadItems(bulk.ids)
+	if err != nil {
+		return items, err
+	}
+	if completed {
+		if err := recordStaplePurchases(items); err != nil {
+			return items, err
+		}
+	}
+	return items, nil
+}
+
+// DeleteItems deletes items at once, and cleans up the trip categories left
+// without items
+func DeleteItems(userID uuid.UUID, args map[string]interface{}) (items []*models.Item, err error) {
+	bulk, err := retrieveBulkItems(userID, args["itemIds"])
+	if err != nil {
+		return items, err
+	}
+
+	err = db.Manager.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id IN ?", bulk.ids).Delete(&models.Item{}).Error; err != nil {
+			return err
+		}
+		if err := deleteEmptyTripCategories(tx, bulk.tripIDs); err != nil {
+			return err
+		}
+		if err := touchTrips(tx, bulk.tripIDs); err != nil {
+			return err
+		}
+		bulk.record(tx, userID, models.ActivityItemsDeleted, nil)
+		return nil
+	})
+	if err != nil {
+		return items, err
+	}
+
+	deletedAt := time.Now()
+	for i := range bulk.items {
+		bulk.items[i].DeletedAt = gorm.DeletedAt{Time: deletedAt, Valid: true}
+	}
+	return bulk.items, nil
+}
+
+// MoveItems moves items to another trip in the same store at once. The items
+// keep their categories, and are moved to the top of them. Items can't be
+// moved to a completed trip
+func MoveItems(userID uuid.UUID, args map[string]interface{}) (items []*models.Item, err error) {
+	bulk, err := retrieveBulkItems(userID, args["itemIds"])
+	if err != nil {
+		return items, err
+	}
+	toTrip, err := retrieveTripForUser(userID, args["toTripId"])
+	if err != nil {
+		return items, err
+	}
+	if toTrip.Completed {
+		return items, errors.New("items can't be moved to a completed trip")
+	}
+	for _, tripID := range bulk.tripIDs {
+		if bulk.trips[tripID].StoreID != toTrip.StoreID {
+			return items, errors.New("items can only be moved to a trip in the same store")
+		}
+	}
+
+	err = db.Manager.Transaction(func(tx *gorm.DB) error {
+		categories, err := storeCategoriesOf(tx, bulk.items)
+		if err != nil {
+			return err
+		}
+		// Move the items in each store category to the same category in the
+		// trip they're moved to, creating it if needed
+		itemIDs := map[uuid.UUID][]uuid.UUID{}
+		categoryIDs := map[uuid.UUID]uuid.UUID{}
+		var storeCategoryIDs []uuid.UUID
+		for _, item := range bulk.items {
+			if item.GroceryTripID == toTrip.ID {
+				continue
+			}
+			storeCategoryID := categories[*item.CategoryID]
+			if _, ok := itemIDs[storeCategoryID]; !ok {
+				storeCategoryIDs = append(storeCategoryIDs, storeCategoryID)
+			}
+			itemIDs[storeCategoryID] = append(itemIDs[storeCategoryID], item.ID)
+		}
+		for _, storeCategoryID := range storeCategoryIDs {
+			groceryTripCategory := models.GroceryTripCategory{
+				GroceryTripID:   toTrip.ID,
+				StoreCategoryID: storeCategoryID,
+			}
+			if err := tx.Where(groceryTripCategory).FirstOrCreate(&groceryTripCategory).Error; err != nil {
+				return err
+			}
+			updates := map[string]interface{}{
+				"grocery_trip_id": toTrip.ID,
+				"category_id":     groceryTripCategory.ID,
+				"updated_at":      time.Now(),
+			}
+			if err := tx.Model(&models.Item{}).Where("id IN ?", itemIDs[storeCategoryID]).UpdateColumns(updates).Error; err != nil {
+				return err
+			}
+			categoryIDs[storeCategoryID] = groceryTripCategory.ID
+		}
+		for _, item := range bulk.items {
+			if item.GroceryTripID != toTrip.ID {
+				categoryID := categoryIDs[categories[*item.CategoryID]]
+				item.CategoryID = &categoryID
+			}
+		}
+
+		if err := deleteEmptyTripCategories(tx, bulk.tripIDs); err != nil {
+			return err
+		}
+		if err := rankItems(tx, bulk.items, true); err != nil {
+			return err
+		}
+		if err := touchTrips(tx, append(bulk.tripIDs, toTrip.ID)); err != nil {
+			return err
+		}
+		changes := map[string]activity.Change{
+			"tripId": {After: toTrip.ID.String()},
+		}
+		bulk.record(tx, userID, models.ActivityItemsMoved, changes)
+		return nil
+	})
+	if err != nil {
+		return items, err
+	}
+
+	items, err = reloadItems(bulk.ids)
+	if err != nil {
+		return items, err
+	}
+	return items, nil
+}
+
+// SetItemsCategory moves items to a category of their store at once, at the
+// top of it
+func SetItemsCategory(userID uuid.UUID, args map[string]interface{}) (items []*models.Item, err error) {
+	bulk, err := retrieveBulkItems(userID, args["itemIds"])
+	if err != nil {
+		return items, err
+	}
+	var storeCategory models.StoreCategory
+	if err := db.Manager.Where("id = ?", args["storeCategoryId"]).First(&storeCategory).Error; err != nil {
+		return items, errors.New("category not found")
+	}
+	for _, tripID := range bulk.tripIDs {
+		if bulk.trips[tripID].StoreID != storeCategory.StoreID {
+			return items, errors.New("category not found")
+		}
+	}
+
+	err = db.Manager.Transaction(func(tx *gorm.DB) error {
+		itemIDs := map[uuid.UUID][]uuid.UUID{}
+		categoryIDs := map[uuid.UUID]uuid.UUID{}
+		for _, item := range bulk.items {
+			itemIDs[item.GroceryTripID] = append(itemIDs[item.GroceryTripID], item.ID)
+		}
+		for _, tripID := range bulk.tripIDs {
+			groceryTripCategory := models.GroceryTripCategory{
+				GroceryTripID:   tripID,
+				StoreCategoryID: storeCategory.ID,
+			}
+			if err := tx.Where(groceryTripCategory).FirstOrCreate(&groceryTripCategory).Error; err != nil {
+				return err
+			}
+			updates := map[string]interface{}{
+				"category_id":         groceryTripCategory.ID,
+				"category_source":     models.CategorySourceUser,
+				"category_confidence": 1,
+				"updated_at":          time.Now(),
+			}
+			if err := tx.Model(&models.Item{}).Where("id IN ?", itemIDs[tripID]).UpdateColumns(updates).Error; err != nil {
+				return err
+			}
+			categoryIDs[tripID] = groceryTripCategory.ID
+		}
+		for _, item := range bulk.items {
+			categoryID := categoryIDs[item.GroceryTripID]
+			item.CategoryID = &categoryID
+		}
+		if err := deleteEmptyTripCategories(tx, bulk.tripIDs); err != nil {
+			return err
+		}
+		if err := rankItems(tx, bulk.items, true); err != nil {
+			return err
+		}
+		if err := touchTrips(tx, bulk.tripIDs); err != nil {
+			return err
+		}
+		changes := map[string]activity.Change{
+			"category": {After: storeCategory.Name},
+		}
+		bulk.record(tx, userID, models.ActivityItemsRecategorised, changes)
+		return nil
+	})
+	if err != nil {
+		return items, err
+	}
+
+	items, err = reloadItems(bulk.ids)
+	if err != nil {
+		return items, err
+	}
+	return items, nil
+}
+
+// retrieveBulkItems retrieves the items for a bulk operation, and their trips.
+// The user must be an active member of the store of every item
+func retrieveBulkItems(userID uuid.UUID, itemIDs interface{}) (bulk bulkItems, err error) {
+	values, _ := itemIDs.([]interface{})
+	if len(values) == 0 {
+		return bulk, errors.New("no items provided")
+	}
+	if len(values) > MaxBulkItems {
+		return bulk, fmt.Errorf("at most %d items can be changed at once", MaxBulkItems)
+	}
+	seen := map[uuid.UUID]bool{}
+	for _, value := range values {
+		id, err := uuid.FromString(fmt.Sprint(value))
+		if err != nil {
+			return bulk, errors.New("item not found")
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		bulk.ids = append(bulk.ids, id)
+	}
+
+	query := db.Manager.
+		Select("items.*").
+		Joins("INNER JOIN grocery_trips ON grocery_trips.id = items.grocery_trip_id").
+		Joins("INNER JOIN store_users ON store_users.store_id = grocery_trips.store_id").
+		Where("items.id IN ? AND store_users.user_id = ? AND store_users.active = ?", bulk.ids, userID, true).
+		Where("grocery_trips.deleted_at IS NULL").
+		Order(models.ItemRankOrder).
+		Find(&bulk.items).
+		Error
+	if err := query; err != nil {
+		return bulk, err
+	}
+	if len(bulk.items) != len(bulk.ids) {
+		return bulk, errors.New("item not found")
+	}
+
+	for _, item := range bulk.items {
+		if _, ok := bulk.trips[item.GroceryTripID]; ok {
+			continue
+		}
+		if bulk.trips == nil {
+			bulk.trips = map[uuid.UUID]models.GroceryTrip{}
+		}
+		bulk.trips[item.GroceryTripID] = models.GroceryTrip{}
+		bulk.tripIDs = append(bulk.tripIDs, item.GroceryTripID)
+	}
+	var trips []models.GroceryTrip
+	if err := db.Manager.Select("id, store_id, name").Where("id IN ?", bulk.tripIDs).Find(&trips).Error; err != nil {
+		return bulk, err
+	}
+	for _, trip := range trips {
+		bulk.trips[trip.ID] = trip
+	}
+	return bulk, nil
+}
+
+// record records a single activity entry for each trip changed by a bulk
+// operation, listing the names of its items that were changed
+func (bulk bulkItems) record(tx *gorm.DB, userID uuid.UUID, action string, changes map[string]activity.Change) {
+	names := map[uuid.UUID][]string{}
+	for _, item := range bulk.items {
+		names[item.GroceryTripID] = append(names[item.GroceryTripID], item.Name)
+	}
+	for _, tripID := range bulk.tripIDs {
+		trip := bulk.trips[tripID]
+		tripChanges := map[string]activity.Change{
+			"items": {After: strings.Join(names[tripID], ", ")},
+		}
+		for attribute, change := range changes {
+			tripChanges[attribute] = change
+		}
+		entity := activity.Entity{Type: activity.EntityTrip, ID: trip.ID, Name: trip.Name}
+		activity.Record(tx, trip.StoreID, userID, action, entity, tripChanges)
+	}
+}
+
+// storeCategoriesOf maps the trip category of each item to its store category
+func storeCategoriesOf(tx *gorm.DB, items []*models.Item) (categories map[uuid.UUID]uuid.UUID, err error) {
+	categories = map[uuid.UUID]uuid.UUID{}
+	var categoryIDs []uuid.UUID
+	for _, item := range items {
+		if item.CategoryID != nil {
+			categoryIDs = append(categoryIDs, *item.CategoryID)
+		}
+	}
+	var groceryTripCategories []models.GroceryTripCategory
+	query := tx.
+		Select("id, store_category_id").
+		Where("id IN ?", categoryIDs).
+		Find(&groceryTripCategories).
+		Error
+	if err := query; err != nil {
+		return categories, err
+	}
+	for _, category := range groceryTripCategories {
+		categories[category.ID] = category.StoreCategoryID
+	}
+	return categories, nil
+}
+
+// rankItems moves items to the top (or bottom) of their categories, keeping
+// their order. Only the items themselves are changed. Each category is locked
+// first so that a concurrent reorder can't rank an item between the same
+// neighbours
+func rankItems(tx *gorm.DB, items []*models.Item, top bool) error {
+	itemIDs := map[uuid.UUID][]uuid.UUID{}
+	var categoryIDs []uuid.UUID
+	for _, item := range items {
+		if item.CategoryID == nil {
+			continue
+		}
+		categoryID := *item.CategoryID
+		if _, ok := itemIDs[categoryID]; !ok {
+			categoryIDs = append(categoryIDs, categoryID)
+		}
+		itemIDs[categoryID] = append(itemIDs[categoryID], item.ID)
+	}
+	position := 0
+	if top {
+		position = 1
+	}
+	for _, categoryID := range categoryIDs {
+		if err := models.LockCategory(tx, categoryID); err != nil {
+			return err
+		}
+		ranks, err := models.RankItems(tx, categoryID, itemIDs[categoryID], position, len(itemIDs[categoryID]))
+		if err != nil {
+			return err
+		}
+		if err := models.SetItemRanks(tx, itemIDs[categoryID], ranks); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteEmptyTripCategories deletes the categories of the trips that have
+// been left without items
+func deleteEmptyTripCategories(tx *gorm.DB, tripIDs []uuid.UUID) error {
+	return tx.
+		Where("grocery_trip_id IN ?", tripIDs).
+		Where("NOT EXISTS (SELECT 1 FROM items WHERE items.category_id = grocery_trip_categories.id AND items.deleted_at IS NULL)").
+		Delete(&models.GroceryTripCategory{}).
+		Error
+}
+
+// touchTrips updates the updated_at timestamp of trips, so that their members
+// see a single change however many items were changed. This is the change
+// notification for a bulk operation: apps pick up changes to a trip by its
+// updated_at (the item hooks touch the trip in the same way), and no push
+// notification is sent when items are completed, deleted, moved or
+// recategorised one at a time either. Each trip changed is touched once,
+// including the trip the items are moved to
+func touchTrips(tx *gorm.DB, tripIDs []uuid.UUID) error {
+	return tx.
+		Model(&models.GroceryTrip{}).
+		Where("id IN ?", tripIDs).
+		UpdateColumn("updated_at", time.Now()).
+		Error
+}
+
+// reloadItems retrieves items after a bulk operation, in trip order
+func reloadItems(itemIDs []uuid.UUID) (items []*models.Item, err error) {
+	query := db.Manager.
+		Where("id IN ?", itemIDs).
+		Order("grocery_trip_id, category_id").
+		Order(models.ItemRankOrder).
+		Find(&items).
+		Error
+	if err := query; err != nil {
+		return items, err
+	}
+	return items, nil
+}
@@ -4,52 +4,66 @@ import (
 	"errors"
 	"time"
 
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
 )
 
 // DeleteItem deletes an item from a trip and handles trip category cleanup
 // (i.e. if this is the last item in a trip category, it deletes the trip category)
-func DeleteItem(itemID interface{}) (deletedItem models.Item, err error) {
+func DeleteItem(userID uuid.UUID, itemID interface{}) (deletedItem models.Item, err error) {
 	item := models.Item{}
 	if err := db.Manager.Where("id = ?", itemID).First(&item).Error; err != nil {
 		return deletedItem, errors.New("item not found")
 	}
 
-	categoryID := item.CategoryID
-	if err := db.Manager.Delete(&item).Error; err != nil {
+	if err := deleteItem(db.Manager, item); err != nil {
 		return deletedItem, err
 	}
 
+	activity.RecordForTrip(db.Manager, item.GroceryTripID, userID, models.ActivityItemDeleted, itemEntity(&item), nil)
+
+	return item, nil
+}
+
+// deleteItem deletes an item, touches its trip and deletes its trip category
+// if it was the last item in it
+func deleteItem(tx *gorm.DB, item models.Item) error {
+	categoryID := item.CategoryID
+	if err := tx.Delete(&item).Error; err != nil {
+		return err
+	}
+
 	// Touch the GroceryTrip record to update its updated_at timestamp
-	updateTripQuery := db.Manager.
+	updateTripQuery := tx.
 		Model(&models.GroceryTrip{}).
 		Where("id = ?", item.GroceryTripID).
 		Update("updated_at", time.Now()).
 		Error
 	if err := updateTripQuery; err != nil {
-		return deletedItem, err
+		return err
 	}
 
 	// If this was the last item in this trip category, delete the trip category too
 	var remainingItemsCount int64
-	categoryItemsCountQuery := db.Manager.
+	categoryItemsCountQuery := tx.
 		Model(&models.Item{}).
 		Where("category_id = ?", categoryID).
 		Count(&remainingItemsCount).
 		Error
 	if err := categoryItemsCountQuery; err != nil {
-		return deletedItem, err
+		return err
 	}
 	if remainingItemsCount == 0 {
-		deleteCategoryQuery := db.Manager.
+		deleteCategoryQuery := tx.
 			Where("id = ?", categoryID).
 			Delete(&models.GroceryTripCategory{}).
 			Error
 		if err := deleteCategoryQuery; err != nil {
-			return deletedItem, err
+			return err
 		}
 	}
-
-	return item, nil
+	return nil
 }
@@ -0,0 +1,212 @@
+package trips
+
+import (
+	"math/rand"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/migration"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// orderingStore is a store in a real database for the item ordering tests
+type orderingStore struct {
+	conn       *gorm.DB
+	user       models.User
+	trip       models.GroceryTrip
+	categories map[string]models.StoreCategory
+}
+
+// openOrderingStore connects to the database at TEST_POSTGRES_DSN and creates
+// a store in it, skipping the test if it isn't set. db.Manager is pointed at
+// the database until the test ends
+func openOrderingStore(t *testing.T) orderingStore {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	conn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: db.SetLogger()})
+	require.NoError(t, err)
+	require.NoError(t, migration.AutoMigrateService(conn))
+	manager := db.Manager
+	db.Manager = conn
+	t.Cleanup(func() { db.Manager = manager })
+
+	st := orderingStore{conn: conn, categories: map[string]models.StoreCategory{}}
+	st.user = models.User{Email: uuid.NewV4().String() + "@example.com", Password: "password"}
+	require.NoError(t, conn.Create(&st.user).Error)
+	store := models.Store{UserID: st.user.ID, Name: "Ordering"}
+	require.NoError(t, conn.Create(&store).Error)
+	trip, err := RetrieveCurrentStoreTrip(store.ID)
+	require.NoError(t, err)
+	st.trip = trip
+	var storeCategories []models.StoreCategory
+	require.NoError(t, conn.Where("store_id = ?", store.ID).Find(&storeCategories).Error)
+	for _, storeCategory := range storeCategories {
+		st.categories[storeCategory.Name] = storeCategory
+	}
+	return st
+}
+
+// addItem adds an item to the top of a category of the store's trip
+func (st orderingStore) addItem(t *testing.T, name string, categoryName string) *models.Item {
+	args := map[string]interface{}{
+		"tripId":                st.trip.ID,
+		"name":                  name,
+		"pinnedStoreCategoryId": st.categories[categoryName].ID,
+	}
+	item, err := AddItem(st.user.ID, args)
+	require.NoError(t, err)
+	return item
+}
+
+// itemIDs returns the IDs of the items in a category, in order
+func (st orderingStore) itemIDs(t *testing.T, categoryID uuid.UUID) (itemIDs []uuid.UUID) {
+	require.NoError(t, st.conn.Model(&models.Item{}).Where("category_id = ?", categoryID).Order(models.ItemRankOrder).Pluck("id", &itemIDs).Error)
+	return itemIDs
+}
+
+// TestItemOrdering_ReordersAndMoves reorders items within a category, moves
+// them to another category and completes them, checking the order of the
+// items after each change. This needs a real database, so it only runs when
+// TEST_POSTGRES_DSN is set
+func TestItemOrdering_ReordersAndMoves(t *testing.T) {
+	st := openOrderingStore(t)
+
+	// New items are added to the top of their category
+	a := st.addItem(t, "Apples", "Produce")
+	b := st.addItem(t, "Bananas", "Produce")
+	c := st.addItem(t, "Carrots", "Produce")
+	d := st.addItem(t, "Dates", "Produce")
+	e := st.addItem(t, "Eggplant", "Produce")
+	z := st.addItem(t, "Bagels", "Bakery")
+	produceID, bakeryID := *a.CategoryID, *z.CategoryID
+	assert.Equal(t, []uuid.UUID{e.ID, d.ID, c.ID, b.ID, a.ID}, st.itemIDs(t, produceID))
+
+	_, err := ReorderItem(st.user.ID, a.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{a.ID, e.ID, d.ID, c.ID, b.ID}, st.itemIDs(t, produceID))
+
+	_, err = ReorderItem(st.user.ID, e.ID, 4)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{a.ID, d.ID, c.ID, e.ID, b.ID}, st.itemIDs(t, produceID))
+
+	// A position past the bottom of the category moves the item to the bottom
+	_, err = ReorderItem(st.user.ID, d.ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{a.ID, c.ID, e.ID, b.ID, d.ID}, st.itemIDs(t, produceID))
+
+	// Items moved to another category go to the top of it, in the order they
+	// were in
+	args := map[string]interface{}{
+		"itemIds":         []interface{}{b.ID.String(), c.ID.String()},
+		"storeCategoryId": st.categories["Bakery"].ID,
+	}
+	_, err = SetItemsCategory(st.user.ID, args)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{a.ID, e.ID, d.ID}, st.itemIDs(t, produceID))
+	assert.Equal(t, []uuid.UUID{c.ID, b.ID, z.ID}, st.itemIDs(t, bakeryID))
+
+	_, err = ReorderItem(st.user.ID, z.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{z.ID, c.ID, b.ID}, st.itemIDs(t, bakeryID))
+
+	// Completed items go to the bottom of their category
+	_, err = CompleteItems(st.user.ID, map[string]interface{}{"itemIds": []interface{}{a.ID.String()}})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{e.ID, d.ID, a.ID}, st.itemIDs(t, produceID))
+}
+
+// TestItemOrdering_Concurrent adds, reorders and deletes items in a category
+// all at once, and checks the category ends up with the items that are left,
+// each at its own position. This needs a real database, so it only runs when
+// TEST_POSTGRES_DSN is set
+func TestItemOrdering_Concurrent(t *testing.T) {
+	st := openOrderingStore(t)
+
+	itemName := func(i int) string {
+		return "Item " + string(rune('a'+i/26)) + string(rune('a'+i%26))
+	}
+	var seeded []*models.Item
+	for i := 0; i < 20; i++ {
+		seeded = append(seeded, st.addItem(t, itemName(i), "Produce"))
+	}
+	categoryID := *seeded[0].CategoryID
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	added := make(chan uuid.UUID, 20)
+	run := func(f func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := f(); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	for i := 20; i < 40; i++ {
+		args := map[string]interface{}{
+			"tripId":                st.trip.ID,
+			"name":                  itemName(i),
+			"pinnedStoreCategoryId": st.categories["Produce"].ID,
+		}
+		run(func() error {
+			item, err := AddItem(st.user.ID, args)
+			if err == nil {
+				added <- item.ID
+			}
+			return err
+		})
+	}
+	for _, item := range seeded[10:] {
+		itemID, position := item.ID, rand.Intn(30)+1
+		run(func() error {
+			_, err := ReorderItem(st.user.ID, itemID, position)
+			return err
+		})
+	}
+	for _, item := range seeded[:10] {
+		itemID := item.ID
+		run(func() error {
+			_, err := DeleteItem(st.user.ID, itemID)
+			return err
+		})
+	}
+	wg.Wait()
+	close(errs)
+	close(added)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	var expected []uuid.UUID
+	for _, item := range seeded[10:] {
+		expected = append(expected, item.ID)
+	}
+	for itemID := range added {
+		expected = append(expected, itemID)
+	}
+	itemIDs := st.itemIDs(t, categoryID)
+	assert.ElementsMatch(t, expected, itemIDs)
+	for i, itemID := range itemIDs {
+		item := models.Item{}
+		require.NoError(t, st.conn.Where("id = ?", itemID).First(&item).Error)
+		position, err := item.PositionInCategory(st.conn)
+		require.NoError(t, err)
+		assert.Equal(t, i+1, position)
+	}
+
+	// The order still takes reorders once everything has settled
+	last := itemIDs[len(itemIDs)-1]
+	_, err := ReorderItem(st.user.ID, last, 1)
+	require.NoError(t, err)
+	assert.Equal(t, append([]uuid.UUID{last}, itemIDs[:len(itemIDs)-1]...), st.itemIDs(t, categoryID))
+}
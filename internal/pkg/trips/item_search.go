@@ -3,6 +3,7 @@ package trips
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
@@ -10,32 +11,183 @@ import (
 	"gorm.io/gorm"
 )
 
-// SearchForItemByName finds an item within the stores the user belongs to
-func SearchForItemByName(name string, userID uuid.UUID) (item *models.Item, err error) {
-	var trips []models.GroceryTrip
-	tripsQuery := db.Manager.
-		Select("grocery_trips.id").
-		Joins("INNER JOIN store_users ON grocery_trips.store_id = store_users.store_id").
-		Where("store_users.user_id = ?", userID).
-		Find(&trips).
+// SearchDefaultLimit and SearchMaxLimit bound the number of items returned
+// by SearchItems
+const (
+	SearchDefaultLimit = 20
+	SearchMaxLimit     = 100
+)
+
+// ItemSearchResults is a page of item search results, grouped by store and
+// then trip, in the order of their best match
+type ItemSearchResults struct {
+	// Total is the number of matching items across all pages
+	Total  int64
+	Stores []ItemSearchStore
+}
+
+// ItemSearchStore is the matching items in a store, by trip
+type ItemSearchStore struct {
+	Store models.Store
+	Trips []ItemSearchTrip
+}
+
+// ItemSearchTrip is the matching items in a trip
+type ItemSearchTrip struct {
+	Trip models.GroceryTrip
+	Hits []ItemSearchHit
+}
+
+// ItemSearchHit is an item matching a search, and how similar its name is
+// to the search term (from 0 to 1)
+type ItemSearchHit struct {
+	Item  *models.Item
+	Score float64
+}
+
+// itemSearchMatch is the ID and similarity score of a matching item
+type itemSearchMatch struct {
+	ID    uuid.UUID
+	Score float64
+}
+
+// SearchItems searches the items in the stores the user belongs to (other than
+// archived stores), including completed items and past trips. Items are
+// matched by trigram similarity, so typos are tolerated ("brocoli" finds
+// "Broccoli"), as well as by substring, and are ranked by similarity and then
+// most recent first. The storeId arg limits the search to one store, and limit
+// and offset paginate the results
+func SearchItems(userID uuid.UUID, args map[string]interface{}) (results ItemSearchResults, err error) {
+	term := strings.TrimSpace(args["query"].(string))
+	if term == "" {
+		return results, errors.New("search term can't be blank")
+	}
+	limit := SearchDefaultLimit
+	if args["limit"] != nil {
+		limit = args["limit"].(int)
+	}
+	if limit <= 0 {
+		limit = SearchDefaultLimit
+	}
+	if limit > SearchMaxLimit {
+		limit = SearchMaxLimit
+	}
+	offset := 0
+	if args["offset"] != nil && args["offset"].(int) > 0 {
+		offset = args["offset"].(int)
+	}
+
+	search := func() *gorm.DB {
+		// Note: <% is pg_trgm's word similarity operator, which (like ILIKE)
+		// can use the trigram index on items.name
+		query := db.Manager.
+			Model(&models.Item{}).
+			Joins("INNER JOIN grocery_trips ON grocery_trips.id = items.grocery_trip_id").
+			Joins("INNER JOIN stores ON stores.id = grocery_trips.store_id").
+			Joins("INNER JOIN store_users ON store_users.store_id = grocery_trips.store_id").
+			Where("store_users.user_id = ? AND store_users.active = ?", userID, true).
+			Where("grocery_trips.deleted_at IS NULL AND stores.archived_at IS NULL").
+			Where("(? <% items.name OR items.name ILIKE ?)", term, fmt.Sprintf("%%%s%%", escapeLike(term)))
+		if args["storeId"] != nil {
+			query = query.Where("grocery_trips.store_id = ?", args["storeId"])
+		}
+		return query
+	}
+	if err := search().Count(&results.Total).Error; err != nil {
+		return results, err
+	}
+	if results.Total == 0 {
+		return results, nil
+	}
+
+	var matches []itemSearchMatch
+	matchesQuery := search().
+		Select("items.id, word_similarity(?, items.name) AS score", term).
+		Order("score DESC, items.created_at DESC, items.id").
+		Limit(limit).
+		Offset(offset).
+		Scan(&matches).
 		Error
-	if err := tripsQuery; err != nil {
-		return item, errors.New("could not find trip IDs for user")
+	if err := matchesQuery; err != nil {
+		return results, err
+	}
+	if len(matches) == 0 {
+		return results, nil
+	}
+	results.Stores, err = groupItemSearchMatches(matches)
+	if err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// groupItemSearchMatches loads the matching items, along with their trips and
+// stores, and groups them by store and trip in the order of their best match
+func groupItemSearchMatches(matches []itemSearchMatch) (groups []ItemSearchStore, err error) {
+	var itemIDs []uuid.UUID
+	for _, match := range matches {
+		itemIDs = append(itemIDs, match.ID)
+	}
+	var items []*models.Item
+	if err := db.Manager.Where("id IN ?", itemIDs).Find(&items).Error; err != nil {
+		return groups, err
 	}
+	itemsByID := map[uuid.UUID]*models.Item{}
 	var tripIDs []uuid.UUID
-	for i := range trips {
-		tripIDs = append(tripIDs, trips[i].ID)
+	for _, item := range items {
+		itemsByID[item.ID] = item
+		tripIDs = append(tripIDs, item.GroceryTripID)
 	}
 
-	foundItem := &models.Item{}
-	nameArg := fmt.Sprintf("%%%s%%", name)
-	searchQuery := db.Manager.
-		Model(&models.Item{}).
-		Where("name ILIKE ? AND grocery_trip_id IN ?", nameArg, tripIDs).
-		First(&foundItem).
-		Error
-	if err := searchQuery; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return item, errors.New("no item matches the search term")
+	var trips []models.GroceryTrip
+	if err := db.Manager.Where("id IN ?", tripIDs).Find(&trips).Error; err != nil {
+		return groups, err
+	}
+	tripsByID := map[uuid.UUID]models.GroceryTrip{}
+	var storeIDs []uuid.UUID
+	for _, trip := range trips {
+		tripsByID[trip.ID] = trip
+		storeIDs = append(storeIDs, trip.StoreID)
+	}
+	var stores []models.Store
+	if err := db.Manager.Where("id IN ?", storeIDs).Find(&stores).Error; err != nil {
+		return groups, err
+	}
+	storesByID := map[uuid.UUID]models.Store{}
+	for _, store := range stores {
+		storesByID[store.ID] = store
 	}
-	return foundItem, nil
+
+	storeIndex := map[uuid.UUID]int{}
+	tripIndex := map[uuid.UUID]int{}
+	for _, match := range matches {
+		item, ok := itemsByID[match.ID]
+		if !ok {
+			continue
+		}
+		trip, ok := tripsByID[item.GroceryTripID]
+		if !ok {
+			continue
+		}
+		s, ok := storeIndex[trip.StoreID]
+		if !ok {
+			s = len(groups)
+			storeIndex[trip.StoreID] = s
+			groups = append(groups, ItemSearchStore{Store: storesByID[trip.StoreID]})
+		}
+		t, ok := tripIndex[trip.ID]
+		if !ok {
+			t = len(groups[s].Trips)
+			tripIndex[trip.ID] = t
+			groups[s].Trips = append(groups[s].Trips, ItemSearchTrip{Trip: trip})
+		}
+		hit := ItemSearchHit{Item: item, Score: match.Score}
+		groups[s].Trips[t].Hits = append(groups[s].Trips[t].Hits, hit)
+	}
+	return groups, nil
+}
+
+// escapeLike escapes the wildcards in a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
 }
@@ -6,31 +6,56 @@ import (
 	uuid "github.com/satori/go.uuid"
 )
 
-// RetrieveItems finds all items in a grocery trip by tripID
+// RetrieveItems finds all items in a grocery trip by tripID, grouped by category
 func RetrieveItems(tripID uuid.UUID) (interface{}, error) {
 	var items []models.Item
 	query := db.Manager.
 		Where("grocery_trip_id = ?", tripID).
-		Order("position ASC").
+		Order("category_id").
+		Order(models.ItemRankOrder).
 		Find(&items).
 		Error
 	if err := query; err != nil {
 		return nil, err
 	}
+	position := 0
+	for i := range items {
+		if i == 0 || !sameCategory(items[i-1], items[i]) {
+			position = 0
+		}
+		position++
+		items[i].Position = position
+	}
 	return items, nil
 }
 
-// RetrieveItemsInCategory finds all items in a grocery trip by category
-func RetrieveItemsInCategory(tripID uuid.UUID, categoryID uuid.UUID) (interface{}, error) {
+// RetrieveItemsInCategory finds all items in a grocery trip by category,
+// optionally only those assigned to the store member with assigneeID
+func RetrieveItemsInCategory(tripID uuid.UUID, categoryID uuid.UUID, assigneeID *uuid.UUID) (interface{}, error) {
 	var items []models.Item
 	query := db.Manager.
 		Where("grocery_trip_id = ?", tripID).
-		Where("category_id = ?", categoryID).
-		Order("position ASC").
-		Find(&items).
-		Error
-	if err := query; err != nil {
+		Where("category_id = ?", categoryID)
+	if assigneeID != nil {
+		query = query.Where("assignee_id = ?", assigneeID)
+	}
+	if err := query.Order(models.ItemRankOrder).Find(&items).Error; err != nil {
 		return nil, err
 	}
+	// The positions of the items are only known when the whole category has
+	// been retrieved
+	if assigneeID == nil {
+		for i := range items {
+			items[i].Position = i + 1
+		}
+	}
 	return items, nil
 }
+
+// sameCategory returns whether two items are in the same category
+func sameCategory(a models.Item, b models.Item) bool {
+	if a.CategoryID == nil || b.CategoryID == nil {
+		return a.CategoryID == b.CategoryID
+	}
+	return *a.CategoryID == *b.CategoryID
+}
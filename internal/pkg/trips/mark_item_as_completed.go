@@ -2,7 +2,9 @@ package trips
 
 import (
 	"errors"
+	"time"
 
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
 	uuid "github.com/satori/go.uuid"
@@ -19,5 +21,30 @@ func MarkItemAsCompleted(name string, userID uuid.UUID) (updatedItems []*models.
 	if err := updateQuery; err != nil {
 		return updatedItems, errors.New("could not update items")
 	}
+	for i := range updatedItems {
+		activity.RecordForTrip(db.Manager, updatedItems[i].GroceryTripID, userID, models.ActivityItemCompleted, itemEntity(updatedItems[i]), nil)
+	}
+	if err := recordStaplePurchases(updatedItems); err != nil {
+		return updatedItems, err
+	}
 	return updatedItems, nil
 }
+
+// recordStaplePurchases records that the staples of items that were just
+// completed were purchased
+func recordStaplePurchases(items []*models.Item) error {
+	var stapleItemIDs []uuid.UUID
+	for i := range items {
+		if items[i].StapleItemID != nil {
+			stapleItemIDs = append(stapleItemIDs, *items[i].StapleItemID)
+		}
+	}
+	if len(stapleItemIDs) == 0 {
+		return nil
+	}
+	return db.Manager.
+		Model(&models.StoreStapleItem{}).
+		Where("id IN (?)", stapleItemIDs).
+		UpdateColumn("last_purchased_at", time.Now()).
+		Error
+}
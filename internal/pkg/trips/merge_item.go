@@ -0,0 +1,135 @@
+package trips
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/parser"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// findMergeableItem finds an item in the same trip with the same name as the
+// item being added that it can be merged into. It returns nil when the store
+// doesn't merge duplicate items, the user doesn't belong to the store (which
+// the item hooks will reject), or there's nothing to merge into
+func findMergeableItem(tx *gorm.DB, item *models.Item) (*models.Item, error) {
+	var existing models.Item
+	query := tx.
+		Select("items.*").
+		Joins("INNER JOIN grocery_trips ON grocery_trips.id = items.grocery_trip_id").
+		Joins("INNER JOIN stores ON stores.id = grocery_trips.store_id").
+		Joins("INNER JOIN store_users ON store_users.store_id = stores.id AND store_users.user_id = ?", item.UserID).
+		Where("items.grocery_trip_id = ? AND items.completed = ?", item.GroceryTripID, false).
+		Where("LOWER(TRIM(items.name)) = ?", strings.ToLower(strings.TrimSpace(item.Name))).
+		Where("stores.merge_duplicate_items = ?", true).
+		Order("items.created_at ASC").
+		First(&existing).
+		Error
+	if errors.Is(query, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err := query; err != nil {
+		return nil, err
+	}
+	// Amounts in different units (i.e. 500 g and 2 lbs) can't be added up
+	if unitName(existing.Unit) != unitName(item.Unit) {
+		return nil, nil
+	}
+	return &existing, nil
+}
+
+// mergeItem adds the quantity of an item being added to an existing item,
+// and records who added it (and for which meal) in its item additions. The
+// existing item is locked and read again first, so that the same item being
+// added more than once at a time adds up rather than the last add winning. It
+// returns the changes made to the existing item
+func mergeItem(tx *gorm.DB, existing *models.Item, item *models.Item) (changes map[string]activity.Change, err error) {
+	err = tx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", existing.ID).First(existing).Error; err != nil {
+			return err
+		}
+		before := activity.ItemAttributes(*existing)
+
+		var count int64
+		if err := tx.Model(&models.ItemAddition{}).Where("item_id = ?", existing.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		var additions []models.ItemAddition
+		if count == 0 {
+			// This is the first merge, so record the original addition too
+			additions = append(additions, itemAddition(existing, existing))
+		}
+		additions = append(additions, itemAddition(existing, item))
+
+		if existing.DecimalQuantity == nil && item.DecimalQuantity == nil {
+			existing.Quantity += item.Quantity
+		} else {
+			amount := itemAmount(existing) + itemAmount(item)
+			existing.DecimalQuantity = &amount
+			existing.Quantity = parser.WholeQuantity(amount, existing.Unit)
+		}
+		if existing.UnitPrice == nil && item.UnitPrice != nil {
+			existing.UnitPrice = item.UnitPrice
+			existing.Currency = item.Currency
+		}
+
+		if err := tx.Create(&additions).Error; err != nil {
+			return err
+		}
+		// UpdateColumns to only change the amount and price, without the item hooks
+		updates := map[string]interface{}{
+			"quantity":         existing.Quantity,
+			"decimal_quantity": existing.DecimalQuantity,
+			"unit_price":       existing.UnitPrice,
+			"currency":         existing.Currency,
+			"updated_at":       time.Now(),
+		}
+		if err := tx.Model(existing).UpdateColumns(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.GroceryTrip{}).Where("id = ?", existing.GroceryTripID).UpdateColumn("updated_at", time.Now()).Error; err != nil {
+			return err
+		}
+		changes = activity.Diff(before, activity.ItemAttributes(*existing))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	existing.Merged = true
+	return changes, nil
+}
+
+// itemAddition builds a record of item being added, for the existing item
+// it's being merged into
+func itemAddition(existing *models.Item, item *models.Item) models.ItemAddition {
+	return models.ItemAddition{
+		ItemID:          existing.ID,
+		UserID:          item.UserID,
+		Quantity:        item.Quantity,
+		DecimalQuantity: item.DecimalQuantity,
+		Unit:            item.Unit,
+		MealID:          item.MealID,
+		MealName:        item.MealName,
+	}
+}
+
+// itemAmount returns the amount of an item, preferring its decimal quantity
+func itemAmount(item *models.Item) float64 {
+	if item.DecimalQuantity != nil {
+		return *item.DecimalQuantity
+	}
+	return float64(item.Quantity)
+}
+
+// unitName returns the unit of an item, or a blank string if it has none
+func unitName(unit *string) string {
+	if unit == nil {
+		return ""
+	}
+	return *unit
+}
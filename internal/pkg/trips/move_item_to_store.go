@@ -0,0 +1,130 @@
+package trips
+
+import (
+	"errors"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// MoveItemToStore moves an item to the current trip in another store. The item
+// is added to that trip as if it had been added there (so it's categorised by
+// that store's category settings, and merged into the same item if it's
+// already on the trip), then deleted from its own trip
+func MoveItemToStore(userID uuid.UUID, args map[string]interface{}) (*models.Item, error) {
+	return itemToStore(userID, args, true)
+}
+
+// CopyItemToStore copies an item to the current trip in another store, leaving
+// the item in its own trip as it is
+func CopyItemToStore(userID uuid.UUID, args map[string]interface{}) (*models.Item, error) {
+	return itemToStore(userID, args, false)
+}
+
+// itemToStore adds an item to the current trip in another store, deleting the
+// original item when moving it. The user must be a member of both stores
+func itemToStore(userID uuid.UUID, args map[string]interface{}, move bool) (addedItem *models.Item, err error) {
+	item := models.Item{}
+	query := db.Manager.
+		Select("items.*").
+		Joins("INNER JOIN grocery_trips ON grocery_trips.id = items.grocery_trip_id").
+		Joins("INNER JOIN store_users ON store_users.store_id = grocery_trips.store_id").
+		Where("items.id = ? AND store_users.user_id = ? AND store_users.active = ?", args["itemId"], userID, true).
+		First(&item).
+		Error
+	if err := query; err != nil {
+		return addedItem, errors.New("item not found")
+	}
+	var store models.Store
+	query = db.Manager.
+		Select("stores.*").
+		Joins("INNER JOIN grocery_trips ON grocery_trips.store_id = stores.id").
+		Where("grocery_trips.id = ?", item.GroceryTripID).
+		First(&store).
+		Error
+	if err := query; err != nil {
+		return addedItem, err
+	}
+
+	var targetStore models.Store
+	query = db.Manager.
+		Select("stores.*").
+		Joins("INNER JOIN store_users ON store_users.store_id = stores.id").
+		Where("stores.id = ? AND store_users.user_id = ? AND store_users.active = ?", args["storeId"], userID, true).
+		Where("stores.archived_at IS NULL").
+		First(&targetStore).
+		Error
+	if err := query; err != nil {
+		return addedItem, errors.New("store not found")
+	}
+	if targetStore.ID == store.ID {
+		return addedItem, errors.New("item is already in this store")
+	}
+	trip, err := RetrieveCurrentStoreTrip(targetStore.ID)
+	if err != nil {
+		return addedItem, errors.New("could not find trip associated with this store")
+	}
+
+	// The item is added and deleted together, so that a move that fails
+	// part way through leaves the item where it was
+	err = db.Manager.Transaction(func(tx *gorm.DB) (err error) {
+		var changes map[string]activity.Change
+		addedItem, changes, err = addItem(tx, userID, itemCopyArgs(item, trip.ID))
+		if err != nil {
+			return err
+		}
+		action := models.ActivityItemAdded
+		if addedItem.Merged {
+			action = models.ActivityItemMerged
+		}
+		activity.Record(tx, targetStore.ID, userID, action, itemEntity(addedItem), changes)
+		if !move {
+			return nil
+		}
+
+		if err := deleteItem(tx, item); err != nil {
+			return err
+		}
+		changes = map[string]activity.Change{
+			"store": {Before: store.Name, After: targetStore.Name},
+		}
+		activity.Record(tx, store.ID, userID, models.ActivityItemMoved, itemEntity(&item), changes)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return addedItem, nil
+}
+
+// itemCopyArgs returns the addItem args for a copy of an item in another trip.
+// The item's price, assignee and staple item belong to its own store, so they
+// aren't copied
+func itemCopyArgs(item models.Item, tripID uuid.UUID) map[string]interface{} {
+	args := map[string]interface{}{
+		"tripId":   tripID,
+		"name":     item.Name,
+		"quantity": item.Quantity,
+	}
+	if item.DecimalQuantity != nil {
+		args["decimalQuantity"] = *item.DecimalQuantity
+	}
+	if item.Unit != nil {
+		args["unit"] = *item.Unit
+	}
+	if item.Notes != nil {
+		args["notes"] = *item.Notes
+	}
+	if item.Barcode != nil {
+		args["barcode"] = *item.Barcode
+	}
+	if item.MealID != nil && item.MealName != nil {
+		args["mealId"] = *item.MealID
+		args["mealName"] = *item.MealName
+	}
+	return args
+}
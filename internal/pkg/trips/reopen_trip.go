@@ -0,0 +1,239 @@
+package trips
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// ReopenTrip reopens a completed trip, undoing what completing it did: the
+// items copied to the next trip are moved back, the items that were marked as
+// completed are restored to their original state, and the next trip is
+// removed if it hasn't been touched since it was created
+func ReopenTrip(userID uuid.UUID, tripID interface{}) (trip models.GroceryTrip, err error) {
+	trip, err = retrieveTripForUser(userID, tripID)
+	if err != nil {
+		return trip, err
+	}
+	if !trip.Completed {
+		return trip, errors.New("trip is not completed")
+	}
+
+	var rollover models.TripRollover
+	query := db.Manager.
+		Where("grocery_trip_id = ?", trip.ID).
+		Order("created_at DESC").
+		First(&rollover).
+		Error
+	if err := query; err != nil {
+		return trip, errors.New("this trip can no longer be reopened")
+	}
+	completedItemIDs, err := rollover.CompletedItems()
+	if err != nil {
+		return trip, err
+	}
+	copiedItemIDs, err := rollover.CopiedItems()
+	if err != nil {
+		return trip, err
+	}
+	stapleLastAddedAt, err := rollover.StaplesLastAddedAt()
+	if err != nil {
+		return trip, err
+	}
+
+	var nextTrip models.GroceryTrip
+	query = db.Manager.Where("id = ?", rollover.NextTripID).First(&nextTrip).Error
+	if err := query; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return trip, err
+	}
+	if nextTrip.Completed {
+		return trip, errors.New("the next trip has already been completed")
+	}
+	untouched := false
+	if nextTrip.ID != uuid.Nil {
+		if untouched, err = nextTripUntouched(nextTrip, rollover, copiedItemIDs); err != nil {
+			return trip, err
+		}
+	}
+
+	err = db.Manager.Transaction(func(tx *gorm.DB) error {
+		if len(completedItemIDs) > 0 {
+			restoreQuery := tx.
+				Model(&models.Item{}).
+				Where("id IN ? AND grocery_trip_id = ?", completedItemIDs, trip.ID).
+				UpdateColumn("completed", false).
+				Error
+			if err := restoreQuery; err != nil {
+				return err
+			}
+		}
+		if nextTrip.ID != uuid.Nil {
+			if err := moveCopiedItemsBack(tx, nextTrip, copiedItemIDs); err != nil {
+				return err
+			}
+		}
+		if untouched {
+			if err := removeNextTrip(tx, nextTrip, stapleLastAddedAt); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&trip).Update("completed", false).Error; err != nil {
+			return err
+		}
+		// The trip's assignee completion is counted live again until it's
+		// completed again
+		if err := tx.Where("grocery_trip_id = ?", trip.ID).Delete(&models.AssigneeCompletion{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&rollover).Error; err != nil {
+			return err
+		}
+		entity := activity.Entity{Type: activity.EntityTrip, ID: trip.ID, Name: trip.Name}
+		activity.Record(tx, trip.StoreID, userID, models.ActivityTripReopened, entity, nil)
+		return nil
+	})
+	if err != nil {
+		return trip, err
+	}
+	trip.Completed = false
+
+	return trip, nil
+}
+
+// nextTripUntouched reports whether the next trip created by completing a trip
+// is as it was created: it hasn't been changed since, and its items are the
+// copied and staple items added to it, unchanged
+func nextTripUntouched(nextTrip models.GroceryTrip, rollover models.TripRollover, copiedItemIDs map[uuid.UUID]uuid.UUID) (bool, error) {
+	if nextTrip.UpdatedAt.After(rollover.CreatedAt) {
+		return false, nil
+	}
+
+	// Any item that was changed, deleted or completed since, or that wasn't
+	// added by completing the trip, means the trip has been touched
+	query := db.Manager.
+		Unscoped().
+		Model(&models.Item{}).
+		Where("grocery_trip_id = ?", nextTrip.ID)
+	if len(copiedItemIDs) > 0 {
+		var ids []uuid.UUID
+		for id := range copiedItemIDs {
+			ids = append(ids, id)
+		}
+		query = query.Where("updated_at > ? OR deleted_at IS NOT NULL OR completed = ? OR (staple_item_id IS NULL AND id NOT IN ?)", rollover.CreatedAt, true, ids)
+	} else {
+		query = query.Where("updated_at > ? OR deleted_at IS NOT NULL OR completed = ? OR staple_item_id IS NULL", rollover.CreatedAt, true)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
+
+// moveCopiedItemsBack moves the items copied to the next trip back to the trip
+// they were copied from, keeping any changes made to them in the next trip
+func moveCopiedItemsBack(tx *gorm.DB, nextTrip models.GroceryTrip, copiedItemIDs map[uuid.UUID]uuid.UUID) error {
+	if len(copiedItemIDs) == 0 {
+		return nil
+	}
+	var ids []uuid.UUID
+	for id := range copiedItemIDs {
+		ids = append(ids, id)
+	}
+	var copies []models.Item
+	if err := tx.Where("id IN ? AND grocery_trip_id = ?", ids, nextTrip.ID).Find(&copies).Error; err != nil {
+		return err
+	}
+	if len(copies) == 0 {
+		return nil
+	}
+
+	var copyIDs []uuid.UUID
+	for i := range copies {
+		item := copies[i]
+		// UpdateColumns to avoid the item hooks, which would recategorise the item
+		updates := map[string]interface{}{
+			"name":             item.Name,
+			"quantity":         item.Quantity,
+			"decimal_quantity": item.DecimalQuantity,
+			"unit":             item.Unit,
+			"notes":            item.Notes,
+			"completed":        item.Completed,
+			"assignee_id":      item.AssigneeID,
+			"updated_at":       time.Now(),
+		}
+		if err := tx.Model(&models.Item{}).Where("id = ?", copiedItemIDs[item.ID]).UpdateColumns(updates).Error; err != nil {
+			return err
+		}
+		copyIDs = append(copyIDs, item.ID)
+	}
+	if err := tx.Where("id IN ?", copyIDs).Delete(&models.Item{}).Error; err != nil {
+		return err
+	}
+
+	// Clean up the trip categories in the next trip left without items
+	return deleteEmptyTripCategories(tx, []uuid.UUID{nextTrip.ID})
+}
+
+// removeNextTrip deletes the next trip created by completing a trip, and sets
+// the staple items added to it back to when they had last been added before
+func removeNextTrip(tx *gorm.DB, nextTrip models.GroceryTrip, stapleLastAddedAt map[uuid.UUID]*time.Time) error {
+	if err := tx.Where("grocery_trip_id = ?", nextTrip.ID).Delete(&models.Item{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("grocery_trip_id = ?", nextTrip.ID).Delete(&models.GroceryTripCategory{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Delete(&nextTrip).Error; err != nil {
+		return err
+	}
+	for stapleItemID, lastAddedAt := range stapleLastAddedAt {
+		query := tx.
+			Model(&models.StoreStapleItem{}).
+			Where("id = ?", stapleItemID).
+			UpdateColumn("last_added_at", lastAddedAt).
+			Error
+		if err := query; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordRollover records what completing a trip did, so that it can be undone
+// by ReopenTrip
+func recordRollover(
+	trip models.GroceryTrip,
+	newTrip models.GroceryTrip,
+	completedItemIDs []uuid.UUID,
+	copiedItemIDs map[uuid.UUID]uuid.UUID,
+	stapleLastAddedAt map[uuid.UUID]*time.Time,
+) error {
+	completed, err := json.Marshal(completedItemIDs)
+	if err != nil {
+		return err
+	}
+	copied, err := json.Marshal(copiedItemIDs)
+	if err != nil {
+		return err
+	}
+	staples, err := json.Marshal(stapleLastAddedAt)
+	if err != nil {
+		return err
+	}
+	rollover := models.TripRollover{
+		GroceryTripID:     trip.ID,
+		NextTripID:        newTrip.ID,
+		CompletedItemIDs:  completed,
+		CopiedItemIDs:     copied,
+		StapleLastAddedAt: staples,
+	}
+	return db.Manager.Create(&rollover).Error
+}
@@ -1,24 +1,76 @@
 package trips
 
 import (
+	"errors"
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
 )
 
-// ReorderItem handles the reordering of an item by taking the
-// item ID and the new position. It returns the reordered trip object.
-func ReorderItem(itemID interface{}, position int) (*models.GroceryTrip, error) {
+// ReorderItem handles the reordering of an item by taking the item ID and its
+// new position (from 1) in its category. Only the item itself is changed: it's
+// given a rank between the items either side of its new position. It returns
+// the reordered trip object.
+func ReorderItem(userID uuid.UUID, itemID interface{}, position int) (*models.GroceryTrip, error) {
 	trip := &models.GroceryTrip{}
 	item := &models.Item{}
 	if err := db.Manager.Where("id = ?", itemID).First(&item).Error; err != nil {
 		return trip, err
 	}
-	item.Position = position
-	if err := db.Manager.Save(&item).Error; err != nil {
-		return trip, err
+	if item.CategoryID == nil {
+		return trip, errors.New("item is not in a category")
 	}
-	if err := db.Manager.Where("id = ?", item.GroceryTripID).Find(&trip).Error; err != nil {
+	if position < 1 {
+		position = 1
+	}
+	err := db.Manager.Transaction(func(tx *gorm.DB) error {
+		if err := models.LockCategory(tx, *item.CategoryID); err != nil {
+			return err
+		}
+		oldPosition, err := item.PositionInCategory(tx)
+		if err != nil {
+			return err
+		}
+		if err := setItemPosition(tx, item, position); err != nil {
+			return err
+		}
+		updates := map[string]interface{}{
+			"rank":       item.Rank,
+			"updated_at": time.Now(),
+		}
+		if err := tx.Model(&item).UpdateColumns(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", item.GroceryTripID).Find(&trip).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&trip).UpdateColumn("updated_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		changes := map[string]activity.Change{"position": {Before: oldPosition, After: position}}
+		activity.Record(tx, trip.StoreID, userID, models.ActivityItemReordered, itemEntity(item), changes)
+		return nil
+	})
+	if err != nil {
 		return trip, err
 	}
 	return trip, nil
 }
+
+// setItemPosition ranks an item at a position (from 1) among the other items
+// in its category. It must be called in a transaction that has locked the
+// category (see models.LockCategory), and that goes on to save the item's rank
+func setItemPosition(tx *gorm.DB, item *models.Item, position int) error {
+	ranks, err := models.RankItems(tx, *item.CategoryID, []uuid.UUID{item.ID}, position, 1)
+	if err != nil {
+		return err
+	}
+	item.Rank = ranks[0]
+	item.Position = position
+	return nil
+}
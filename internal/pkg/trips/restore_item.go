@@ -0,0 +1,121 @@
+package trips
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// RestoreItem restores a deleted item, as long as it was deleted within the
+// last models.ItemRestorePeriod. Its trip category is restored too if it was
+// deleted along with the item
+func RestoreItem(userID uuid.UUID, itemID interface{}) (item *models.Item, err error) {
+	item = &models.Item{}
+	query := db.Manager.
+		Unscoped().
+		Select("items.*").
+		Joins("INNER JOIN grocery_trips ON grocery_trips.id = items.grocery_trip_id").
+		Joins("INNER JOIN store_users ON store_users.store_id = grocery_trips.store_id").
+		Where("items.id = ? AND store_users.user_id = ? AND store_users.active = ?", itemID, userID, true).
+		Where("grocery_trips.deleted_at IS NULL").
+		First(item).
+		Error
+	if err := query; err != nil {
+		return item, errors.New("item not found")
+	}
+	if !item.DeletedAt.Valid {
+		return item, errors.New("this item has not been deleted")
+	}
+	if time.Since(item.DeletedAt.Time) > models.ItemRestorePeriod {
+		return item, errors.New("this item can no longer be restored")
+	}
+
+	err = db.Manager.Transaction(func(tx *gorm.DB) error {
+		categoryID, err := restoreItemCategory(tx, item)
+		if err != nil {
+			return err
+		}
+		item.CategoryID = &categoryID
+
+		// UpdateColumns to avoid the item hooks, which would recategorise the item
+		updates := map[string]interface{}{
+			"category_id": categoryID,
+			"deleted_at":  nil,
+		}
+		if err := tx.Unscoped().Model(item).UpdateColumns(updates).Error; err != nil {
+			return err
+		}
+
+		// Touch the GroceryTrip record to update its updated_at timestamp
+		touchQuery := tx.
+			Model(&models.GroceryTrip{}).
+			Where("id = ?", item.GroceryTripID).
+			Update("updated_at", time.Now()).
+			Error
+		if err := touchQuery; err != nil {
+			return err
+		}
+		activity.RecordForTrip(tx, item.GroceryTripID, userID, models.ActivityItemRestored, itemEntity(item), nil)
+		return nil
+	})
+	if err != nil {
+		return item, err
+	}
+	item.DeletedAt = gorm.DeletedAt{}
+
+	return item, nil
+}
+
+// restoreItemCategory returns the trip category to restore a deleted item to.
+// This is the item's own category, which is restored if it was deleted, unless
+// the trip has since been given another category for the same store category
+func restoreItemCategory(tx *gorm.DB, item *models.Item) (categoryID uuid.UUID, err error) {
+	var category models.GroceryTripCategory
+	if err := tx.Unscoped().Where("id = ?", item.CategoryID).First(&category).Error; err != nil {
+		return categoryID, err
+	}
+	if !category.DeletedAt.Valid {
+		return category.ID, nil
+	}
+
+	var existing models.GroceryTripCategory
+	query := tx.
+		Where("grocery_trip_id = ? AND store_category_id = ?", category.GroceryTripID, category.StoreCategoryID).
+		First(&existing).
+		Error
+	if err := query; err == nil {
+		return existing.ID, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return categoryID, err
+	}
+
+	if err := tx.Unscoped().Model(&category).UpdateColumn("deleted_at", nil).Error; err != nil {
+		return categoryID, err
+	}
+	return category.ID, nil
+}
+
+// RetrieveRecentlyDeletedItems retrieves the items deleted from a trip that
+// can still be restored, most recently deleted first
+func RetrieveRecentlyDeletedItems(userID uuid.UUID, tripID interface{}) (items []*models.Item, err error) {
+	trip, err := retrieveTripForUser(userID, tripID)
+	if err != nil {
+		return items, err
+	}
+	query := db.Manager.
+		Unscoped().
+		Where("grocery_trip_id = ?", trip.ID).
+		Where("deleted_at > ?", time.Now().Add(-models.ItemRestorePeriod)).
+		Order("deleted_at DESC").
+		Find(&items).
+		Error
+	if err := query; err != nil {
+		return items, err
+	}
+	return items, nil
+}
@@ -0,0 +1,233 @@
+package trips
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/notifications"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// RetrieveStoreSchedule retrieves the shopping schedule for a store, or nil if
+// the store doesn't have one
+func RetrieveStoreSchedule(storeID uuid.UUID) (*models.StoreSchedule, error) {
+	var schedule models.StoreSchedule
+	query := db.Manager.Where("store_id = ?", storeID).First(&schedule).Error
+	if errors.Is(query, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err := query; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// SetStoreSchedule creates or updates the weekly shopping schedule for a store
+func SetStoreSchedule(storeID interface{}, userID uuid.UUID, args map[string]interface{}) (schedule models.StoreSchedule, err error) {
+	var storeUser models.StoreUser
+	memberQuery := db.Manager.
+		Where("store_id = ? AND user_id = ? AND active = ?", storeID, userID, true).
+		First(&storeUser).
+		Error
+	if err := memberQuery; err != nil {
+		return schedule, errors.New("user is not active in this store")
+	}
+
+	query := db.Manager.Where("store_id = ?", storeUser.StoreID).First(&schedule).Error
+	if err := query; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return schedule, err
+	}
+	schedule.StoreID = storeUser.StoreID
+
+	weekday := args["weekday"].(int)
+	if weekday < int(time.Sunday) || weekday > int(time.Saturday) {
+		return schedule, errors.New("weekday must be between 0 (Sunday) and 6 (Saturday)")
+	}
+	timezone := args["timezone"].(string)
+	if _, err := time.LoadLocation(timezone); err != nil || len(timezone) == 0 {
+		return schedule, errors.New("invalid timezone")
+	}
+	schedule.Weekday = weekday
+	schedule.Timezone = timezone
+
+	if args["copyRemainingItems"] != nil {
+		copyRemainingItems := args["copyRemainingItems"].(bool)
+		schedule.CopyRemainingItems = &copyRemainingItems
+	}
+	if schedule.CopyRemainingItems == nil {
+		copyRemainingItems := true
+		schedule.CopyRemainingItems = &copyRemainingItems
+	}
+
+	if err := db.Manager.Save(&schedule).Error; err != nil {
+		return schedule, err
+	}
+	return schedule, nil
+}
+
+// DeleteStoreSchedule removes the shopping schedule for a store. Trips that
+// were already planned keep their shopping dates
+func DeleteStoreSchedule(storeID interface{}, userID uuid.UUID) (schedule models.StoreSchedule, err error) {
+	query := db.Manager.
+		Select("store_schedules.*").
+		Joins("INNER JOIN store_users ON store_users.store_id = store_schedules.store_id").
+		Where("store_schedules.store_id = ?", storeID).
+		Where("store_users.user_id = ? AND store_users.active = ?", userID, true).
+		First(&schedule).
+		Error
+	if err := query; err != nil {
+		return schedule, errors.New("couldn't retrieve store schedule")
+	}
+	if err := db.Manager.Delete(&schedule).Error; err != nil {
+		return schedule, err
+	}
+	return schedule, nil
+}
+
+// RunStoreSchedules plans and rolls over the trips of every store with a
+// shopping schedule. A failure for one store doesn't stop the others
+func RunStoreSchedules() error {
+	var schedules []models.StoreSchedule
+	query := db.Manager.
+		Select("store_schedules.*").
+		Joins("INNER JOIN stores ON stores.id = store_schedules.store_id").
+		Where("stores.archived_at IS NULL AND stores.deleted_at IS NULL").
+		Find(&schedules).
+		Error
+	if err := query; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range schedules {
+		if err := runStoreSchedule(schedules[i], now); err != nil {
+			log.Printf("[trips] schedule for store %v: %v\n", schedules[i].StoreID, err)
+		}
+	}
+	return nil
+}
+
+// runStoreSchedule makes sure that the store's current trip is planned for a
+// scheduled shopping day. Once that day has passed, the trip is completed (and
+// its remaining items rolled over, if the schedule says to) and a new trip is
+// planned for the next shopping day. If the store has no current trip, one is
+// created
+func runStoreSchedule(schedule models.StoreSchedule, now time.Time) error {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return err
+	}
+	today := now.In(loc).Format(models.ShoppingDateLayout)
+	next, err := schedule.NextShoppingDate(now)
+	if err != nil {
+		return err
+	}
+	nextDate := next.Format(models.ShoppingDateLayout)
+
+	trip, err := RetrieveCurrentStoreTrip(schedule.StoreID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		trip = models.GroceryTrip{
+			StoreID:      schedule.StoreID,
+			Name:         next.Format(models.TripNameLayout),
+			ShoppingDate: &nextDate,
+		}
+		if err := db.Manager.Create(&trip).Error; err != nil {
+			return err
+		}
+		return AddStapleItemsToNewTrip(trip)
+	}
+	if err != nil {
+		return err
+	}
+
+	if trip.ShoppingDate == nil {
+		return db.Manager.Model(&trip).UpdateColumn("shopping_date", nextDate).Error
+	}
+	if *trip.ShoppingDate >= today {
+		return nil
+	}
+
+	// The shopping day has passed. If nothing was ever added to the trip
+	// there's nothing to complete, so just plan it for the next shopping day
+	var count int64
+	if err := db.Manager.Model(&models.Item{}).Where("grocery_trip_id = ?", trip.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		updates := map[string]interface{}{"shopping_date": nextDate, "reminder_sent_at": nil}
+		return db.Manager.Model(&trip).UpdateColumns(updates).Error
+	}
+
+	args := map[string]interface{}{
+		"tripId":              trip.ID,
+		"completed":           true,
+		"copyRemainingItems":  *schedule.CopyRemainingItems,
+		"newTripName":         next.Format(models.TripNameLayout),
+		"newTripShoppingDate": nextDate,
+	}
+	// No user completed the trip, so its activity entry has no user (the
+	// storeActivity query shows it as done by the system)
+	_, err = UpdateTrip(uuid.Nil, args)
+	return err
+}
+
+// SendShoppingReminders reminds store members about trips planned for the
+// next day, once it's evening in the store's timezone (or UTC for stores
+// without a schedule). Each trip is only reminded about once: it's claimed by
+// setting reminder_sent_at before the reminder is sent, and only the caller
+// whose update claimed it sends the reminder. Archived and deleted stores
+// aren't reminded about
+func SendShoppingReminders() error {
+	now := time.Now()
+	// Local dates are at most a day ahead of UTC, so this covers every trip
+	// that could be planned for tomorrow somewhere
+	horizon := now.UTC().AddDate(0, 0, 2).Format(models.ShoppingDateLayout)
+
+	var trips []models.GroceryTrip
+	query := db.Manager.
+		Select("grocery_trips.*").
+		Joins("INNER JOIN stores ON stores.id = grocery_trips.store_id").
+		Where("stores.archived_at IS NULL AND stores.deleted_at IS NULL").
+		Where("grocery_trips.completed = ? AND grocery_trips.reminder_sent_at IS NULL", false).
+		Where("grocery_trips.shopping_date IS NOT NULL AND grocery_trips.shopping_date <= ?", horizon).
+		Find(&trips).
+		Error
+	if err := query; err != nil {
+		return err
+	}
+
+	for i := range trips {
+		loc := time.UTC
+		schedule, err := RetrieveStoreSchedule(trips[i].StoreID)
+		if err != nil {
+			return err
+		}
+		if schedule != nil {
+			if loc, err = time.LoadLocation(schedule.Timezone); err != nil {
+				return err
+			}
+		}
+
+		local := now.In(loc)
+		tomorrow := local.AddDate(0, 0, 1).Format(models.ShoppingDateLayout)
+		if *trips[i].ShoppingDate != tomorrow || local.Hour() < models.ShoppingReminderHour {
+			continue
+		}
+
+		claim := db.Manager.
+			Model(&trips[i]).
+			Where("reminder_sent_at IS NULL").
+			UpdateColumn("reminder_sent_at", now)
+		if err := claim.Error; err != nil {
+			return err
+		}
+		if claim.RowsAffected == 1 {
+			notifications.ShoppingReminder(trips[i])
+		}
+	}
+	return nil
+}
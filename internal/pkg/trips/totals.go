@@ -0,0 +1,45 @@
+package trips
+
+import (
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/stores"
+)
+
+// TripTotal is the total price of the items in a trip in one currency, in the
+// minor unit of the currency (i.e. cents). Estimated includes every item with
+// a price, and Actual only those that have been picked up
+type TripTotal struct {
+	Currency  string
+	Estimated int64
+	Actual    int64
+}
+
+// RetrieveTripTotals calculates the totals of a trip for each currency its
+// items are priced in. Items without a price aren't included
+func RetrieveTripTotals(trip models.GroceryTrip) (totals []TripTotal, err error) {
+	currency, err := stores.StoreCurrency(trip.StoreID)
+	if err != nil {
+		return totals, err
+	}
+
+	query := db.Manager.
+		Model(&models.Item{}).
+		Select(
+			"COALESCE(items.currency, ?) AS currency, "+
+				"SUM("+models.ItemPriceSQL+") AS estimated, "+
+				"SUM(CASE WHEN items.completed THEN "+models.ItemPriceSQL+" ELSE 0 END) AS actual",
+			currency,
+		).
+		Where("items.grocery_trip_id = ? AND items.unit_price IS NOT NULL", trip.ID).
+		// Note: 1 refers to the currency in the select, since grouping by the
+		// name "currency" would use the items.currency column instead
+		Group("1").
+		Order("currency ASC").
+		Scan(&totals).
+		Error
+	if err := query; err != nil {
+		return totals, err
+	}
+	return totals, nil
+}
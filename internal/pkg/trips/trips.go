@@ -78,3 +78,18 @@ func RetrieveTrip(tripID interface{}) (models.GroceryTrip, error) {
 	}
 	return trip, nil
 }
+
+// retrieveTripForUser retrieves a grocery trip by ID if the user is an active
+// member of its store
+func retrieveTripForUser(userID uuid.UUID, tripID interface{}) (trip models.GroceryTrip, err error) {
+	query := db.Manager.
+		Select("grocery_trips.*").
+		Joins("INNER JOIN store_users ON store_users.store_id = grocery_trips.store_id").
+		Where("grocery_trips.id = ? AND store_users.user_id = ? AND store_users.active = ?", tripID, userID, true).
+		First(&trip).
+		Error
+	if err := query; err != nil {
+		return trip, errors.New("trip not found")
+	}
+	return trip, nil
+}
@@ -153,7 +153,7 @@ func (s *Suite) TestUpdateTrip_TripNotFound() {
 		WillReturnRows(s.mock.NewRows([]string{}))
 
 	args := map[string]interface{}{"tripId": tripID}
-	_, e := UpdateTrip(args)
+	_, e := UpdateTrip(uuid.NewV4(), args)
 	require.Error(s.T(), e)
 }
 
@@ -170,7 +170,7 @@ func (s *Suite) TestUpdateTrip_TripNotFound() {
 // 	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
 // 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-// 	_, err := UpdateTrip(args)
+// 	_, err := UpdateTrip(uuid.NewV4(), args)
 // 	require.NoError(s.T(), err)
 // 	assert.Equal(s.T(), "My Second Trip", trip.(models.GroceryTrip).Name)
 // }
@@ -192,6 +192,18 @@ func (s *Suite) TestUpdateTrip_DupeTripName() {
 	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
+	// recordAssigneeCompletion
+	s.mock.ExpectQuery("^SELECT assignee_id AS user_id(.+) FROM \"items\"*").
+		WithArgs(tripID).
+		WillReturnRows(s.mock.NewRows([]string{}))
+
+	// activity.Record
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(storeID, sqlmock.AnyArg(), "trip_completed", "trip", tripID, sqlmock.AnyArg(), nil, AnyTime{}).
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
 	s.mock.ExpectBegin()
 	// Test case where a trip already exists in this store with this name
 	// and assert that it affixes a count after the name
@@ -205,9 +217,12 @@ func (s *Suite) TestUpdateTrip_DupeTripName() {
 		WillReturnRows(s.mock.NewRows([]string{"count"}).AddRow(1))
 	finalTripName := fmt.Sprintf("%s (%d)", tripName, 2)
 	s.mock.ExpectQuery("^INSERT INTO \"grocery_trips\" (.+)$").
-		WithArgs(storeID, finalTripName, false, false, AnyTime{}, AnyTime{}, nil).
+		WithArgs(storeID, finalTripName, false, false, nil, nil, AnyTime{}, AnyTime{}, nil).
 		WillReturnRows(s.mock.NewRows([]string{"store_id"}).AddRow(storeID))
 
+	s.mock.ExpectQuery("^SELECT \"id\" FROM \"items\"*").
+		WithArgs(tripID, false).
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
 	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	s.mock.ExpectCommit()
@@ -220,7 +235,13 @@ func (s *Suite) TestUpdateTrip_DupeTripName() {
 		WithArgs(storeID).
 		WillReturnRows(s.mock.NewRows([]string{}))
 
-	trip, err := UpdateTrip(args)
+	// recordRollover
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^INSERT INTO \"trip_rollovers\" (.+)$").
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	trip, err := UpdateTrip(uuid.NewV4(), args)
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), true, trip.(models.GroceryTrip).Completed)
 	assert.Equal(s.T(), false, trip.(models.GroceryTrip).CopyRemainingItems)
@@ -243,7 +264,26 @@ func (s *Suite) TestUpdateTrip_MarkCompleted() {
 	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
+	// recordAssigneeCompletion
+	assigneeID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT assignee_id AS user_id(.+) FROM \"items\"*").
+		WithArgs(tripID).
+		WillReturnRows(s.mock.NewRows([]string{"user_id", "assigned", "completed"}).AddRow(assigneeID, 3, 2))
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^INSERT INTO \"assignee_completions\" (.+)$").
+		WithArgs(tripID, assigneeID, 3, 2, AnyTime{}).
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	// activity.Record
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(storeID, sqlmock.AnyArg(), "trip_completed", "trip", tripID, sqlmock.AnyArg(), nil, AnyTime{}).
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
 	s.mock.ExpectBegin()
+	newTripID := uuid.NewV4()
 	currentTime := time.Now()
 	tripName := currentTime.Format("Jan 2, 2006")
 	likeTripName := fmt.Sprintf("%%%s%%", tripName)
@@ -251,10 +291,15 @@ func (s *Suite) TestUpdateTrip_MarkCompleted() {
 		WithArgs(likeTripName, storeID).
 		WillReturnRows(s.mock.NewRows([]string{"count"}).AddRow(0))
 	s.mock.ExpectQuery("^INSERT INTO \"grocery_trips\" (.+)$").
-		WithArgs(storeID, tripName, false, false, AnyTime{}, AnyTime{}, nil).
-		WillReturnRows(s.mock.NewRows([]string{"store_id"}).AddRow(storeID))
+		WithArgs(storeID, tripName, false, false, nil, nil, AnyTime{}, AnyTime{}, nil).
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(newTripID))
 
+	remainingItemID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT \"id\" FROM \"items\"*").
+		WithArgs(tripID, false).
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(remainingItemID))
 	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
+		WithArgs(true, remainingItemID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	s.mock.ExpectCommit()
 
@@ -266,12 +311,81 @@ func (s *Suite) TestUpdateTrip_MarkCompleted() {
 		WithArgs(storeID).
 		WillReturnRows(s.mock.NewRows([]string{}))
 
-	trip, err := UpdateTrip(args)
+	// recordRollover
+	completedItemIDs := fmt.Sprintf("[\"%s\"]", remainingItemID)
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^INSERT INTO \"trip_rollovers\" (.+)$").
+		WithArgs(tripID, newTripID, completedItemIDs, "{}", "{}", AnyTime{}).
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	trip, err := UpdateTrip(uuid.NewV4(), args)
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), true, trip.(models.GroceryTrip).Completed)
 	assert.Equal(s.T(), false, trip.(models.GroceryTrip).CopyRemainingItems)
 }
 
+func (s *Suite) TestUpdateTrip_RenameCompletedTrip() {
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(s.mock.
+			NewRows([]string{"id", "store_id", "name", "completed"}).
+			AddRow(tripID, storeID, "My First Trip", true))
+	// The trip was already rolled over when it was completed, so renaming it
+	// only saves the trip
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	args := map[string]interface{}{
+		"tripId":    tripID,
+		"name":      "Big Shop",
+		"completed": true,
+	}
+	trip, err := UpdateTrip(uuid.NewV4(), args)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "Big Shop", trip.(models.GroceryTrip).Name)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestUpdateTrip_RolloverFails() {
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(s.mock.
+			NewRows([]string{"id", "store_id", "name"}).
+			AddRow(tripID, storeID, "My First Trip"))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectQuery("^SELECT assignee_id AS user_id(.+) FROM \"items\"*").
+		WithArgs(tripID).
+		WillReturnRows(s.mock.NewRows([]string{}))
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	// The next trip can't be created, so no staple items are added to it and
+	// no rollover is recorded
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT count*").
+		WillReturnRows(s.mock.NewRows([]string{"count"}).AddRow(0))
+	s.mock.ExpectQuery("^INSERT INTO \"grocery_trips\" (.+)$").
+		WillReturnError(fmt.Errorf("connection reset"))
+	s.mock.ExpectRollback()
+
+	args := map[string]interface{}{
+		"tripId":    tripID,
+		"completed": true,
+	}
+	_, err := UpdateTrip(uuid.NewV4(), args)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "connection reset", err.Error())
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
 func (s *Suite) TestUpdateTrip_MarkCompletedAndCopyRemainingItems() {
 	tripID := uuid.NewV4()
 	storeID := uuid.NewV4()
@@ -282,6 +396,18 @@ func (s *Suite) TestUpdateTrip_MarkCompletedAndCopyRemainingItems() {
 	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
+	// recordAssigneeCompletion
+	s.mock.ExpectQuery("^SELECT assignee_id AS user_id(.+) FROM \"items\"*").
+		WithArgs(tripID).
+		WillReturnRows(s.mock.NewRows([]string{}))
+
+	// activity.Record
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(storeID, sqlmock.AnyArg(), "trip_completed", "trip", tripID, sqlmock.AnyArg(), nil, AnyTime{}).
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
 	s.mock.ExpectBegin()
 	newTripID := uuid.NewV4()
 	currentTime := time.Now()
@@ -291,24 +417,51 @@ func (s *Suite) TestUpdateTrip_MarkCompletedAndCopyRemainingItems() {
 		WithArgs(likeTripName, storeID).
 		WillReturnRows(s.mock.NewRows([]string{"count"}).AddRow(0))
 	s.mock.ExpectQuery("^INSERT INTO \"grocery_trips\" (.+)$").
-		WithArgs(storeID, tripName, false, false, AnyTime{}, AnyTime{}, nil).
+		WithArgs(storeID, tripName, false, false, nil, nil, AnyTime{}, AnyTime{}, nil).
 		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(newTripID))
 
 	// Test creating a category for each remaining item
 	remainingItemID := uuid.NewV4()
 	itemCategoryID := uuid.NewV4()
+	userID := uuid.NewV4()
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
 		WithArgs(tripID, false).
-		WillReturnRows(s.mock.NewRows([]string{"id", "category_id"}).AddRow(remainingItemID, itemCategoryID))
+		WillReturnRows(s.mock.
+			NewRows([]string{"id", "grocery_trip_id", "category_id", "category_source", "user_id", "name", "quantity", "rank"}).
+			AddRow(remainingItemID, tripID, itemCategoryID, "user", userID, "Apples", 1, "m"))
 	storeCategoryID := uuid.NewV4()
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_categories\"*").
 		WithArgs(itemCategoryID).
 		WillReturnRows(s.mock.NewRows([]string{"id", "name"}).AddRow(storeCategoryID, "Produce"))
+	newCategoryID := uuid.NewV4()
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trip_categories\"*").
 		WithArgs(newTripID, storeCategoryID).
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(newCategoryID))
+
+	// The copy keeps the category the user chose and its rank
+	s.mock.ExpectQuery("^SELECT \"store_id\" FROM \"grocery_trips\"*").
+		WithArgs(newTripID).
+		WillReturnRows(s.mock.NewRows([]string{"store_id"}).AddRow(storeID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(storeID, userID).
 		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	copyID := uuid.NewV4()
+	s.mock.ExpectQuery("^INSERT INTO \"items\" (.+)$").
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(copyID))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET \"updated_at\"(.+)$").
+		WithArgs(AnyTime{}, newTripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// MarkItemsInOldTripAsCompleted
+	s.mock.ExpectQuery("^SELECT \"id\" FROM \"items\"*").
+		WithArgs(tripID, false).
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(remainingItemID))
+	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
+		WithArgs(true, remainingItemID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
 
-		// AddStapleItemsToNewTrip
+	// AddStapleItemsToNewTrip
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\"*").
 		WithArgs(storeID).
 		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(storeID))
@@ -316,23 +469,71 @@ func (s *Suite) TestUpdateTrip_MarkCompletedAndCopyRemainingItems() {
 		WithArgs(storeID).
 		WillReturnRows(s.mock.NewRows([]string{}))
 
-	s.mock.ExpectQuery("^INSERT INTO \"items\" (.+)$").
-		WithArgs(newTripID, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, sqlmock.AnyArg(), 1, false, 1, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), AnyTime{}, AnyTime{}, nil).
+	// recordRollover
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^INSERT INTO \"trip_rollovers\" (.+)$").
 		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
-	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
 
 	args := map[string]interface{}{
 		"tripId":             tripID,
 		"completed":          true,
 		"copyRemainingItems": true,
 	}
-	s.mock.ExpectCommit()
-
-	trip, err := UpdateTrip(args)
+	trip, err := UpdateTrip(uuid.NewV4(), args)
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), true, trip.(models.GroceryTrip).Completed)
 	assert.Equal(s.T(), true, trip.(models.GroceryTrip).CopyRemainingItems)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+// Staple items
+
+func (s *Suite) TestAddStapleItemsToNewTrip_OnlyDueStaples() {
+	storeID := uuid.NewV4()
+	shoppingDate := "2026-10-24"
+	trip := models.GroceryTrip{ID: uuid.NewV4(), StoreID: storeID, ShoppingDate: &shoppingDate}
+	lastAdded := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	lastPurchased := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(storeID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_staple_items\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "cadence", "interval", "weekdays", "last_added_at", "last_purchased_at"}).
+			AddRow(uuid.NewV4(), storeID, "Coffee", "trips", 2, 0, lastAdded, nil).
+			AddRow(uuid.NewV4(), storeID, "Paper towels", "days", 21, 0, lastAdded, lastPurchased))
+	// Coffee was added one trip ago (this new trip), so isn't due until the next one
+	s.mock.ExpectQuery("^SELECT count*").
+		WithArgs(storeID, lastAdded).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	err := AddStapleItemsToNewTrip(trip)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestStapleItemDueOn() {
+	lastAdded := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC) // Saturday
+	everyTrip := models.StoreStapleItem{Cadence: models.StapleCadenceEveryTrip, LastAddedAt: &lastAdded}
+	assert.True(s.T(), everyTrip.DueOn(lastAdded, 0))
+
+	everyOtherTrip := models.StoreStapleItem{Cadence: models.StapleCadenceTrips, Interval: 2, LastAddedAt: &lastAdded}
+	assert.False(s.T(), everyOtherTrip.DueOn(lastAdded, 1))
+	assert.True(s.T(), everyOtherTrip.DueOn(lastAdded, 2))
+
+	everyWeek := models.StoreStapleItem{Cadence: models.StapleCadenceDays, Interval: 7, LastAddedAt: &lastAdded}
+	assert.False(s.T(), everyWeek.DueOn(time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC), 0))
+	assert.True(s.T(), everyWeek.DueOn(time.Date(2026, 10, 24, 0, 0, 0, 0, time.UTC), 0))
+
+	// Mondays and Thursdays
+	weekdays := models.StoreStapleItem{Cadence: models.StapleCadenceWeekdays, Weekdays: 1<<time.Monday | 1<<time.Thursday, LastAddedAt: &lastAdded}
+	assert.Equal(s.T(), time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), *weekdays.NextDueDate())
+	assert.False(s.T(), weekdays.DueOn(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC), 0))
+	assert.True(s.T(), weekdays.DueOn(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC), 0))
+
+	neverAdded := models.StoreStapleItem{Cadence: models.StapleCadenceDays, Interval: 30}
+	assert.True(s.T(), neverAdded.DueOn(lastAdded, 0))
 }
 
 // Items
@@ -382,7 +583,7 @@ func (s *Suite) TestRetrieveItemsInCategory_NoItems() {
 		WithArgs(tripID, categoryID).
 		WillReturnRows(sqlmock.NewRows([]string{}))
 
-	items, err := RetrieveItemsInCategory(tripID, categoryID)
+	items, err := RetrieveItemsInCategory(tripID, categoryID, nil)
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), 0, len(items.([]models.Item)))
 }
@@ -407,7 +608,7 @@ func (s *Suite) TestRetrieveItemsInCategory_HasItems() {
 		WithArgs(tripID, categoryID).
 		WillReturnRows(itemRows)
 
-	items, err := RetrieveItemsInCategory(tripID, categoryID)
+	items, err := RetrieveItemsInCategory(tripID, categoryID, nil)
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), 2, len(items.([]models.Item)))
 	assert.Equal(s.T(), tripID, items.([]models.Item)[0].GroceryTripID)
@@ -415,13 +616,49 @@ func (s *Suite) TestRetrieveItemsInCategory_HasItems() {
 	assert.Equal(s.T(), "Bananas", items.([]models.Item)[1].Name)
 }
 
+func (s *Suite) TestRetrieveItemsInCategory_AssignedTo() {
+	tripID := uuid.NewV4()
+	categoryID := uuid.NewV4()
+	assigneeID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\" WHERE (.+) AND assignee_id = (.+)").
+		WithArgs(tripID, categoryID, assigneeID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "assignee_id"}).AddRow(uuid.NewV4(), assigneeID))
+
+	items, err := RetrieveItemsInCategory(tripID, categoryID, &assigneeID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, len(items.([]models.Item)))
+	assert.Equal(s.T(), assigneeID, *items.([]models.Item)[0].AssigneeID)
+}
+
 // Add items
 
+func (s *Suite) TestAddItemByBarcode_InvalidCode() {
+	args := map[string]interface{}{"tripId": uuid.NewV4(), "code": "4006381333932"}
+	_, err := AddItemByBarcode(uuid.NewV4(), args)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "barcode isn't valid", err.Error())
+}
+
+func (s *Suite) TestAddItemByBarcode_TripDoesntExist() {
+	tripID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT stores.id, stores.household_id FROM \"stores\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	args := map[string]interface{}{"tripId": tripID, "code": "036000291452"}
+	_, err := AddItemByBarcode(uuid.NewV4(), args)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "trip does not exist", err.Error())
+}
+
 func (s *Suite) TestAddItem_TripDoesntExist() {
 	tripID := uuid.NewV4()
 	userID := uuid.NewV4()
 	args := map[string]interface{}{"tripId": tripID, "name": "Test"}
 
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(userID, tripID, false, "test", true).
+		WillReturnRows(sqlmock.NewRows([]string{}))
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
 		WithArgs(tripID).
 		WillReturnRows(s.mock.NewRows([]string{}))
@@ -436,6 +673,9 @@ func (s *Suite) TestAddItem_UserDoesntBelongInStore() {
 	tripID := uuid.NewV4()
 	storeID := uuid.NewV4()
 	trip := &models.GroceryTrip{ID: tripID, StoreID: storeID}
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(userID, tripID, false, "test", true).
+		WillReturnRows(sqlmock.NewRows([]string{}))
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
 		WithArgs(tripID).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(trip.ID, trip.StoreID))
@@ -460,6 +700,9 @@ func (s *Suite) TestAddItem_NoQuantityArg() {
 		"name":   itemName,
 	}
 
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(userID, tripID, false, "kleenex", true).
+		WillReturnRows(sqlmock.NewRows([]string{}))
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
 		WithArgs(tripID).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(trip.ID, trip.StoreID))
@@ -470,12 +713,30 @@ func (s *Suite) TestAddItem_NoQuantityArg() {
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_item_category_settings\"*").
 		WithArgs(trip.StoreID, strings.ToLower(itemName)).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	categoryID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT grocery_trip_categories.id FROM \"grocery_trip_categories\"*").
+		WithArgs(trip.ID, "Cleaning").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(categoryID))
+	// The category is empty, so the item is ranked in the middle
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\"*").
+		WithArgs(categoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}))
 
 	itemID := uuid.NewV4()
 	s.mock.ExpectQuery("^INSERT INTO \"items\" (.+)$").
-		WithArgs(trip.ID, sqlmock.AnyArg(), userID, nil, itemName, 1, false, 1, nil, nil, nil, AnyTime{}, AnyTime{}, nil).
+		WithArgs(trip.ID, categoryID, "classifier", 1.0, userID, nil, nil, itemName, 1, nil, nil, false, "i", nil, nil, nil, nil, nil, nil, AnyTime{}, AnyTime{}, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
 
+	// activity.RecordForTrip
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(trip.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"store_id"}).AddRow(trip.StoreID))
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(trip.StoreID, userID, "item_added", "item", itemID, itemName, nil, AnyTime{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
 	item, err := AddItem(userID, args)
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), itemID, item.ID)
@@ -494,6 +755,9 @@ func (s *Suite) TestAddItem_InlineQuantityInItemName() {
 		"categoryName": "Produce",
 	}
 
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(userID, tripID, false, "apples", true).
+		WillReturnRows(sqlmock.NewRows([]string{}))
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
 		WithArgs(tripID).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(trip.ID, trip.StoreID))
@@ -504,10 +768,17 @@ func (s *Suite) TestAddItem_InlineQuantityInItemName() {
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_item_category_settings\"*").
 		WithArgs(trip.StoreID, "apples").
 		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	categoryID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT grocery_trip_categories.id FROM \"grocery_trip_categories\"*").
+		WithArgs(trip.ID, "Produce").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(categoryID))
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\"*").
+		WithArgs(categoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow("i"))
 
 	itemID := uuid.NewV4()
 	s.mock.ExpectQuery("^INSERT INTO \"items\" (.+)$").
-		WithArgs(trip.ID, sqlmock.AnyArg(), userID, nil, "Apples", 6, false, 1, nil, nil, nil, AnyTime{}, AnyTime{}, nil).
+		WithArgs(trip.ID, categoryID, "classifier", 1.0, userID, nil, nil, "Apples", 6, nil, nil, false, "9", nil, nil, nil, nil, nil, nil, AnyTime{}, AnyTime{}, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
 
 	item, err := AddItem(userID, args)
@@ -517,6 +788,185 @@ func (s *Suite) TestAddItem_InlineQuantityInItemName() {
 	assert.Equal(s.T(), 6, item.Quantity)
 }
 
+func (s *Suite) TestAddItem_AmountInItemName() {
+	userID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	trip := &models.GroceryTrip{ID: tripID, StoreID: storeID}
+	args := map[string]interface{}{
+		"tripId":       tripID,
+		"name":         "2 lbs Apples",
+		"categoryName": "Produce",
+	}
+
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(userID, tripID, false, "apples", true).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(trip.ID, trip.StoreID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(trip.StoreID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "user_id"}).AddRow(uuid.NewV4(), trip.StoreID, userID))
+
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_item_category_settings\"*").
+		WithArgs(trip.StoreID, "apples").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	categoryID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT grocery_trip_categories.id FROM \"grocery_trip_categories\"*").
+		WithArgs(trip.ID, "Produce").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(categoryID))
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\"*").
+		WithArgs(categoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow("i"))
+
+	itemID := uuid.NewV4()
+	s.mock.ExpectQuery("^INSERT INTO \"items\" (.+)$").
+		WithArgs(trip.ID, categoryID, "classifier", 1.0, userID, nil, nil, "Apples", 1, 2.0, "lb", false, "9", nil, nil, nil, nil, nil, nil, AnyTime{}, AnyTime{}, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
+
+	item, err := AddItem(userID, args)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), itemID, item.ID)
+	assert.Equal(s.T(), "Apples", item.Name)
+	assert.Equal(s.T(), 1, item.Quantity)
+	assert.Equal(s.T(), 2.0, *item.DecimalQuantity)
+	assert.Equal(s.T(), "lb", *item.Unit)
+}
+
+func (s *Suite) TestAddItem_ClassifiedByModifier() {
+	userID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	itemName := "Frozen green peas"
+	args := map[string]interface{}{"tripId": tripID, "name": itemName}
+
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(userID, tripID, false, "frozen green peas", true).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(tripID, storeID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "user_id"}).AddRow(uuid.NewV4(), storeID, userID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_item_category_settings\"*").
+		WithArgs(storeID, "frozen green peas").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	// Peas are Canned Goods on their own, but these are frozen
+	categoryID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT grocery_trip_categories.id FROM \"grocery_trip_categories\"*").
+		WithArgs(tripID, "Frozen Foods").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(categoryID))
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\"*").
+		WithArgs(categoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}))
+
+	itemID := uuid.NewV4()
+	s.mock.ExpectQuery("^INSERT INTO \"items\" (.+)$").
+		WithArgs(tripID, categoryID, "classifier", 0.75, userID, nil, nil, itemName, 1, nil, nil, false, "i", nil, nil, nil, nil, nil, nil, AnyTime{}, AnyTime{}, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
+
+	item, err := AddItem(userID, args)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), categoryID, *item.CategoryID)
+	assert.Equal(s.T(), models.CategorySourceClassifier, *item.CategorySource)
+	assert.Equal(s.T(), 0.75, *item.CategoryConfidence)
+}
+
+func (s *Suite) TestAddItem_CategorySavedForStore() {
+	userID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	storeCategoryID := uuid.NewV4()
+	itemName := "Peas"
+	args := map[string]interface{}{"tripId": tripID, "name": itemName}
+
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(userID, tripID, false, "peas", true).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(tripID, storeID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "user_id"}).AddRow(uuid.NewV4(), storeID, userID))
+	settings := fmt.Sprintf(`{"peas": "%s"}`, storeCategoryID)
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_item_category_settings\"*").
+		WithArgs(storeID, "peas").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "items"}).AddRow(uuid.NewV4(), storeID, settings))
+	s.mock.ExpectQuery("^SELECT store_categories.name FROM \"store_categories\"*").
+		WithArgs(storeCategoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Produce"))
+	categoryID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT grocery_trip_categories.id FROM \"grocery_trip_categories\"*").
+		WithArgs(tripID, "Produce").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(categoryID))
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\"*").
+		WithArgs(categoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}))
+
+	itemID := uuid.NewV4()
+	s.mock.ExpectQuery("^INSERT INTO \"items\" (.+)$").
+		WithArgs(tripID, categoryID, "storeSetting", 1.0, userID, nil, nil, itemName, 1, nil, nil, false, "i", nil, nil, nil, nil, nil, nil, AnyTime{}, AnyTime{}, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
+
+	item, err := AddItem(userID, args)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), models.CategorySourceStoreSetting, *item.CategorySource)
+	assert.Equal(s.T(), 1.0, *item.CategoryConfidence)
+}
+
+func (s *Suite) TestAddItem_MergedIntoExistingItem() {
+	userID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	args := map[string]interface{}{
+		"tripId": tripID,
+		"name":   "Onion x 2",
+	}
+
+	itemID := uuid.NewV4()
+	otherUserID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(userID, tripID, false, "onion", true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "grocery_trip_id", "user_id", "name", "quantity", "completed"}).AddRow(itemID, tripID, otherUserID, "Onion", 1, false))
+	s.mock.ExpectBegin()
+	// The item is locked and read again, and has been added to since it was found
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\" (.+) FOR UPDATE$").
+		WithArgs(itemID, itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "grocery_trip_id", "user_id", "name", "quantity", "completed"}).AddRow(itemID, tripID, otherUserID, "Onion", 2, false))
+	s.mock.ExpectQuery("^SELECT count(.+) FROM \"item_additions\"*").
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	s.mock.ExpectQuery("^INSERT INTO \"item_additions\" (.+)$").
+		WithArgs(itemID, otherUserID, 2, nil, nil, nil, nil, AnyTime{}, itemID, userID, 2, nil, nil, nil, nil, AnyTime{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()).AddRow(uuid.NewV4()))
+	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
+		WithArgs(nil, nil, 4, nil, AnyTime{}, itemID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	// activity.RecordForTrip
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"store_id"}).AddRow(storeID))
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(storeID, userID, "item_merged", "item", itemID, "Onion", sqlmock.AnyArg(), AnyTime{}).
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	item, err := AddItem(userID, args)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), s.mock.ExpectationsWereMet())
+	assert.Equal(s.T(), itemID, item.ID)
+	assert.True(s.T(), item.Merged)
+	assert.Equal(s.T(), 4, item.Quantity)
+}
+
 // Add items to store
 
 func (s *Suite) TestAddItemsToStore_CannotFindCurrentTrip() {
@@ -559,22 +1009,22 @@ func (s *Suite) TestFindOrCreateStore_StoreCreated() {
 	storeID := uuid.NewV4()
 	s.mock.ExpectBegin()
 	s.mock.ExpectQuery("^INSERT INTO \"stores\" (.+)$").
-		WithArgs(storeName, sqlmock.AnyArg(), AnyTime{}, AnyTime{}, nil, userID).
+		WithArgs(storeName, sqlmock.AnyArg(), nil, nil, nil, nil, nil, true, nil, AnyTime{}, AnyTime{}, nil, userID).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(storeID, userID))
 
 	storeUserID := uuid.NewV4()
 	s.mock.ExpectQuery("^INSERT INTO \"store_users\" (.+)$").
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "", true, true, AnyTime{}, AnyTime{}, nil).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "", true, true, nil, AnyTime{}, AnyTime{}, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(storeUserID))
 
 	s.mock.ExpectQuery("^INSERT INTO \"store_user_preferences\" (.+)$").
-		WithArgs(storeUserID, false, true, AnyTime{}, AnyTime{}, nil).
+		WithArgs(storeUserID, false, true, true, true, true, true, true, true, true, false, false, false, false, false, false, false, AnyTime{}, AnyTime{}, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
 
 	categories := fetchCategories()
 	for i := range categories {
 		s.mock.ExpectQuery("^INSERT INTO \"store_categories\" (.+)$").
-			WithArgs(sqlmock.AnyArg(), categories[i], AnyTime{}, AnyTime{}, nil).
+			WithArgs(sqlmock.AnyArg(), categories[i], i+1, AnyTime{}, AnyTime{}, nil).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
 	}
 
@@ -585,7 +1035,7 @@ func (s *Suite) TestFindOrCreateStore_StoreCreated() {
 		WithArgs(likeTripName, sqlmock.AnyArg()).
 		WillReturnRows(s.mock.NewRows([]string{"count"}).AddRow(0))
 	s.mock.ExpectQuery("^INSERT INTO \"grocery_trips\" (.+)$").
-		WithArgs(sqlmock.AnyArg(), tripName, false, false, AnyTime{}, AnyTime{}, nil).
+		WithArgs(sqlmock.AnyArg(), tripName, false, false, nil, nil, AnyTime{}, AnyTime{}, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
 	s.mock.ExpectCommit()
 
@@ -601,6 +1051,7 @@ func (s *Suite) TestUpdateItem_NoUpdates() {
 	tripID := uuid.NewV4()
 	storeID := uuid.NewV4()
 	userID := uuid.NewV4()
+	categoryID := uuid.NewV4()
 
 	trip := &models.GroceryTrip{ID: tripID, StoreID: storeID}
 
@@ -610,6 +1061,7 @@ func (s *Suite) TestUpdateItem_NoUpdates() {
 			NewRows([]string{
 				"id",
 				"grocery_trip_id",
+				"category_id",
 				"user_id",
 				"name",
 				"quantity",
@@ -618,29 +1070,27 @@ func (s *Suite) TestUpdateItem_NoUpdates() {
 				"created_at",
 				"updated_at",
 			}).
-			AddRow(itemID, trip.ID, userID, "Apples", 5, false, nil, time.Now(), time.Now()))
+			AddRow(itemID, trip.ID, categoryID, userID, "Apples", 5, false, nil, time.Now(), time.Now()))
 
+	s.mock.ExpectBegin()
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
 		WithArgs(trip.ID).
 		WillReturnRows(s.mock.NewRows([]string{"id", "store_id"}).AddRow(trip.ID, trip.StoreID))
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
 		WithArgs(trip.StoreID, userID).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "user_id"}).AddRow(uuid.NewV4(), trip.StoreID, userID))
-	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_item_category_settings\"*").
-		WithArgs(trip.StoreID, "apples").
-		WillReturnRows(sqlmock.NewRows([]string{"id"}))
-
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
 		WithArgs(itemID).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
+		WillReturnRows(sqlmock.NewRows([]string{"name", "category_id", "rank"}).AddRow("Apples", categoryID, ""))
 
 	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
 
 	args := map[string]interface{}{"itemId": itemID}
-	item, err := UpdateItem(args)
+	item, err := UpdateItem(uuid.NewV4(), args)
 	require.NoError(s.T(), err)
 	// Assert no changes
 	assert.Equal(s.T(), itemID, item.(*models.Item).ID)
@@ -655,6 +1105,7 @@ func (s *Suite) TestUpdateItem_UpdateSingleColumn() {
 	tripID := uuid.NewV4()
 	storeID := uuid.NewV4()
 	userID := uuid.NewV4()
+	categoryID := uuid.NewV4()
 
 	trip := &models.GroceryTrip{ID: tripID, StoreID: storeID}
 
@@ -664,6 +1115,7 @@ func (s *Suite) TestUpdateItem_UpdateSingleColumn() {
 			NewRows([]string{
 				"id",
 				"grocery_trip_id",
+				"category_id",
 				"user_id",
 				"name",
 				"quantity",
@@ -672,31 +1124,29 @@ func (s *Suite) TestUpdateItem_UpdateSingleColumn() {
 				"created_at",
 				"updated_at",
 			}).
-			AddRow(itemID, trip.ID, userID, "Apples", 5, false, nil, time.Now(), time.Now()))
+			AddRow(itemID, trip.ID, categoryID, userID, "Apples", 5, false, nil, time.Now(), time.Now()))
 
+	s.mock.ExpectBegin()
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
 		WithArgs(trip.ID).
 		WillReturnRows(s.mock.NewRows([]string{"id", "store_id"}).AddRow(trip.ID, trip.StoreID))
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
 		WithArgs(trip.StoreID, userID).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "user_id"}).AddRow(uuid.NewV4(), trip.StoreID, userID))
-	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_item_category_settings\"*").
-		WithArgs(trip.StoreID, "apples").
-		WillReturnRows(sqlmock.NewRows([]string{"id"}))
-
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
 		WithArgs(itemID).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
+		WillReturnRows(sqlmock.NewRows([]string{"name", "category_id", "rank"}).AddRow("Apples", categoryID, ""))
 
 	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
 
 	completed := true
 	args := map[string]interface{}{"itemId": itemID, "completed": completed}
 
-	item, err := UpdateItem(args)
+	item, err := UpdateItem(uuid.NewV4(), args)
 	require.NoError(s.T(), err)
 	// Assert only completed state changed
 	assert.Equal(s.T(), itemID, item.(*models.Item).ID)
@@ -712,6 +1162,7 @@ func (s *Suite) TestUpdateItem_UpdateMultiColumn() {
 	tripID := uuid.NewV4()
 	storeID := uuid.NewV4()
 	userID := uuid.NewV4()
+	categoryID := uuid.NewV4()
 
 	trip := &models.GroceryTrip{ID: tripID, StoreID: storeID}
 
@@ -721,6 +1172,7 @@ func (s *Suite) TestUpdateItem_UpdateMultiColumn() {
 			NewRows([]string{
 				"id",
 				"grocery_trip_id",
+				"category_id",
 				"user_id",
 				"name",
 				"quantity",
@@ -729,26 +1181,32 @@ func (s *Suite) TestUpdateItem_UpdateMultiColumn() {
 				"created_at",
 				"updated_at",
 			}).
-			AddRow(itemID, trip.ID, userID, "Apples", 5, false, nil, time.Now(), time.Now()))
+			AddRow(itemID, trip.ID, categoryID, userID, "Apples", 5, false, nil, time.Now(), time.Now()))
 
+	s.mock.ExpectBegin()
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
 		WithArgs(trip.ID).
 		WillReturnRows(s.mock.NewRows([]string{"id", "store_id"}).AddRow(trip.ID, trip.StoreID))
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
 		WithArgs(trip.StoreID, userID).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "user_id"}).AddRow(uuid.NewV4(), trip.StoreID, userID))
-	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_item_category_settings\"*").
-		WithArgs(trip.StoreID, "bananas").
-		WillReturnRows(sqlmock.NewRows([]string{"id"}))
 
+	// The item is renamed, so its category is decided again
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
 		WithArgs(itemID).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
+		WillReturnRows(sqlmock.NewRows([]string{"name", "category_id", "rank"}).AddRow("Apples", categoryID, ""))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_item_category_settings\"*").
+		WithArgs(trip.StoreID, "bananas").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trip_categories\"*").
+		WithArgs(trip.ID, "Produce").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(categoryID))
 
 	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
 
 	completed := true
 	args := map[string]interface{}{
@@ -758,7 +1216,7 @@ func (s *Suite) TestUpdateItem_UpdateMultiColumn() {
 		"name":      "Bananas",
 	}
 
-	item, err := UpdateItem(args)
+	item, err := UpdateItem(uuid.NewV4(), args)
 	require.NoError(s.T(), err)
 	// Assert only quantity and completed states changed
 	assert.Equal(s.T(), itemID, item.(*models.Item).ID)
@@ -769,47 +1227,377 @@ func (s *Suite) TestUpdateItem_UpdateMultiColumn() {
 	assert.Equal(s.T(), &completed, item.(*models.Item).Completed)
 }
 
-// Item reordering
-
-func (s *Suite) TestReorderItem_ReorderItemPosition() {
+func (s *Suite) TestUpdateItem_NameNotParsedAgain() {
 	itemID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
 	userID := uuid.NewV4()
+	categoryID := uuid.NewV4()
+
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "category_id", "user_id", "name", "quantity", "completed"}).
+			AddRow(itemID, tripID, categoryID, userID, "7 Up", 1, false))
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(s.mock.NewRows([]string{"id", "store_id"}).AddRow(tripID, storeID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "user_id"}).AddRow(uuid.NewV4(), storeID, userID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "category_id", "rank"}).AddRow("7 Up", categoryID, ""))
+	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	args := map[string]interface{}{"itemId": itemID, "name": "7 Up", "completed": true}
+	item, err := UpdateItem(uuid.NewV4(), args)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "7 Up", item.(*models.Item).Name)
+	assert.Equal(s.T(), 1, item.(*models.Item).Quantity)
+}
+
+func (s *Suite) TestUpdateItem_UserCategoryKept() {
+	itemID := uuid.NewV4()
 	tripID := uuid.NewV4()
 	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	categoryID := uuid.NewV4()
+	userCategoryID := uuid.NewV4()
+	storeCategoryID := uuid.NewV4()
+	itemColumns := []string{"id", "grocery_trip_id", "category_id", "category_source", "user_id", "name", "rank"}
 
-	trip := &models.GroceryTrip{ID: tripID, StoreID: storeID}
+	// The user moves the item to another category
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.NewRows(itemColumns).AddRow(itemID, tripID, categoryID, "classifier", userID, "Apples", "i"))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trip_categories\"*").
+		WithArgs(tripID, storeCategoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(userCategoryID))
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(s.mock.NewRows([]string{"id", "store_id"}).AddRow(tripID, storeID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "user_id"}).AddRow(uuid.NewV4(), storeID, userID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "category_id", "rank"}).AddRow("Apples", categoryID, "i"))
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\"*").
+		WithArgs(userCategoryID, itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}))
+	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
 
+	args := map[string]interface{}{"itemId": itemID, "storeCategoryId": storeCategoryID.String()}
+	item, err := UpdateItem(userID, args)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), userCategoryID, *item.(*models.Item).CategoryID)
+	assert.Equal(s.T(), models.CategorySourceUser, *item.(*models.Item).CategorySource)
+
+	// Saving it again, even with a new name, keeps the user's category
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.NewRows(itemColumns).AddRow(itemID, tripID, userCategoryID, "user", userID, "Apples", "h"))
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(s.mock.NewRows([]string{"id", "store_id"}).AddRow(tripID, storeID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "user_id"}).AddRow(uuid.NewV4(), storeID, userID))
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
 		WithArgs(itemID).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "grocery_trip_id", "user_id", "name"}).AddRow(itemID, tripID, userID, "Apples"))
+		WillReturnRows(sqlmock.NewRows([]string{"name", "category_id", "rank"}).AddRow("Apples", userCategoryID, "h"))
+	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	args = map[string]interface{}{"itemId": itemID, "name": "Green apples", "completed": true}
+	item, err = UpdateItem(userID, args)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), userCategoryID, *item.(*models.Item).CategoryID)
+	assert.Equal(s.T(), models.CategorySourceUser, *item.(*models.Item).CategorySource)
+	assert.Equal(s.T(), "Green apples", item.(*models.Item).Name)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestUpdateItem_PinnedCategoryKept() {
+	itemID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	categoryID := uuid.NewV4()
 
+	// The item was added from a staple item pinned to a category
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "category_id", "category_source", "user_id", "name", "rank"}).
+			AddRow(itemID, tripID, categoryID, "pinned", userID, "Paper towels", "i"))
+	s.mock.ExpectBegin()
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
-		WithArgs(trip.ID).
-		WillReturnRows(s.mock.NewRows([]string{"id", "store_id"}).AddRow(trip.ID, trip.StoreID))
+		WithArgs(tripID).
+		WillReturnRows(s.mock.NewRows([]string{"id", "store_id"}).AddRow(tripID, storeID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "user_id"}).AddRow(uuid.NewV4(), storeID, userID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "category_id", "rank"}).AddRow("Paper towels", categoryID, "i"))
+	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	args := map[string]interface{}{"itemId": itemID, "name": "Bounty paper towels"}
+	item, err := UpdateItem(userID, args)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), categoryID, *item.(*models.Item).CategoryID)
+	assert.Equal(s.T(), models.CategorySourcePinned, *item.(*models.Item).CategorySource)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestUpdateItem_CatalogueCategoryKept() {
+	itemID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	categoryID := uuid.NewV4()
 
+	// The item was scanned, and put in its product's category
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "category_id", "category_source", "user_id", "name", "rank", "barcode"}).
+			AddRow(itemID, tripID, categoryID, "catalogue", userID, "Cheerios", "i", "0016000275287"))
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(s.mock.NewRows([]string{"id", "store_id"}).AddRow(tripID, storeID))
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
-		WithArgs(trip.StoreID, userID).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "user_id"}).AddRow(uuid.NewV4(), trip.StoreID, userID))
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "user_id"}).AddRow(uuid.NewV4(), storeID, userID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "category_id", "rank"}).AddRow("Cheerios", categoryID, "i"))
+	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	args := map[string]interface{}{"itemId": itemID, "completed": true}
+	item, err := UpdateItem(userID, args)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), categoryID, *item.(*models.Item).CategoryID)
+	assert.Equal(s.T(), models.CategorySourceCatalogue, *item.(*models.Item).CategorySource)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+// Item reordering
+
+func (s *Suite) TestReorderItem_ReorderItemPosition() {
+	itemID := uuid.NewV4()
+	userID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	categoryID := uuid.NewV4()
 
-	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_item_category_settings\"*").
-		WithArgs(trip.StoreID, strings.ToLower("apples")).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}))
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
 		WithArgs(itemID).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "category_id", "user_id", "name", "rank"}).
+			AddRow(itemID, tripID, categoryID, userID, "Apples", "m"))
+
+	// The category is locked until the item's new rank is saved, so that its
+	// neighbours can't change in between
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT \"id\" FROM \"items\" WHERE \\(category_id = \\$1\\) (.+) FOR UPDATE$").
+		WithArgs(categoryID).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
+	s.mock.ExpectQuery("^SELECT count*").
+		WithArgs(categoryID, "m", itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	// The items either side of the fourth position, leaving out the item
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\" WHERE \\(category_id = \\$1\\) AND id NOT IN \\(\\$2\\) (.+) LIMIT 2 OFFSET 2$").
+		WithArgs(categoryID, itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow("p").AddRow("q"))
+	// Only the item itself is updated
+	s.mock.ExpectExec("^UPDATE \"items\" SET \"rank\"=\\$1,\"updated_at\"=\\$2 (.+)$").
+		WithArgs("pi", AnyTime{}, itemID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// The trip is touched and the activity recorded in the same transaction
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(tripID, storeID))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
+		WithArgs(AnyTime{}, tripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^SAVEPOINT sp(.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(storeID, userID, "item_reordered", "item", itemID, "Apples", `{"position":{"before":1,"after":4}}`, AnyTime{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	trip, err := ReorderItem(userID, itemID, 4)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), tripID, trip.ID)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestReorderItem_ActivityFailureKeepsReorder() {
+	itemID := uuid.NewV4()
+	userID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	categoryID := uuid.NewV4()
 
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "category_id", "user_id", "name", "rank"}).
+			AddRow(itemID, tripID, categoryID, userID, "Apples", "m"))
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT \"id\" FROM \"items\" (.+) FOR UPDATE$").
+		WithArgs(categoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
+	s.mock.ExpectQuery("^SELECT count*").
+		WithArgs(categoryID, "m", itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\"*").
+		WithArgs(categoryID, itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow("p").AddRow("q"))
 	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
+		WithArgs("pi", AnyTime{}, itemID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(tripID, storeID))
 	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	// The activity entry can't be recorded, so only its savepoint is rolled
+	// back and the reorder is still committed
+	s.mock.ExpectExec("^SAVEPOINT sp(.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WillReturnError(fmt.Errorf("connection reset"))
+	s.mock.ExpectExec("^ROLLBACK TO SAVEPOINT sp(.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	trip, err := ReorderItem(userID, itemID, 4)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), tripID, trip.ID)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestReorderItem_RebalancesCategoryWhenRanksCollide() {
+	itemID := uuid.NewV4()
+	userID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	categoryID := uuid.NewV4()
+	otherIDs := []uuid.UUID{uuid.NewV4(), uuid.NewV4()}
 
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "category_id", "user_id", "name", "rank"}).
+			AddRow(itemID, tripID, categoryID, userID, "Apples", "a"))
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT \"id\" FROM \"items\" (.+) FOR UPDATE$").
+		WithArgs(categoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID).AddRow(otherIDs[0]).AddRow(otherIDs[1]))
+	s.mock.ExpectQuery("^SELECT count*").
+		WithArgs(categoryID, "a", itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	// Two items were given the same rank by concurrent adds, so there's no
+	// room between them
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\"*").
+		WithArgs(categoryID, itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow("p").AddRow("p"))
+	s.mock.ExpectQuery("^SELECT \"id\" FROM \"items\" (.+) FOR UPDATE$").
+		WithArgs(categoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID).AddRow(otherIDs[0]).AddRow(otherIDs[1]))
+	s.mock.ExpectExec("^UPDATE items SET rank = ranked.rank FROM \\(VALUES (.+)\\) AS ranked\\(id, rank\\)*").
+		WithArgs(itemID, "9", otherIDs[0], "i", otherIDs[1], "r").
+		WillReturnResult(sqlmock.NewResult(3, 3))
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\"*").
+		WithArgs(categoryID, itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow("i").AddRow("r"))
+	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
+		WithArgs("n", AnyTime{}, itemID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
 		WithArgs(tripID).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(tripID))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(tripID, storeID))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^SAVEPOINT sp(.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
 
-	trip, err := ReorderItem(itemID, 4)
+	_, err := ReorderItem(userID, itemID, 3)
 	require.NoError(s.T(), err)
-	assert.Equal(s.T(), tripID, trip.ID)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestRankItems_LocksEachCategoryBeforeSettingRanks() {
+	categoryID := uuid.NewV4()
+	otherCategoryID := uuid.NewV4()
+	itemIDs := []uuid.UUID{uuid.NewV4(), uuid.NewV4()}
+	otherItemID := uuid.NewV4()
+	items := []*models.Item{
+		{ID: itemIDs[0], CategoryID: &categoryID},
+		{ID: otherItemID, CategoryID: &otherCategoryID},
+		{ID: itemIDs[1], CategoryID: &categoryID},
+	}
+
+	// Each category is locked, then its items are ranked after the last of
+	// the others and saved, before the next category is locked
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT \"id\" FROM \"items\" WHERE \\(category_id = \\$1\\) (.+) FOR UPDATE$").
+		WithArgs(categoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemIDs[0]).AddRow(itemIDs[1]))
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\" (.+) ORDER BY items.rank DESC, items.id DESC LIMIT 1$").
+		WithArgs(categoryID, itemIDs[0], itemIDs[1]).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow("m"))
+	s.mock.ExpectExec("^UPDATE items SET rank = ranked.rank FROM \\(VALUES (.+)\\) AS ranked\\(id, rank\\)*").
+		WithArgs(itemIDs[0], "q", itemIDs[1], "t").
+		WillReturnResult(sqlmock.NewResult(2, 2))
+	s.mock.ExpectQuery("^SELECT \"id\" FROM \"items\" WHERE \\(category_id = \\$1\\) (.+) FOR UPDATE$").
+		WithArgs(otherCategoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(otherItemID))
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\" (.+) ORDER BY items.rank DESC, items.id DESC LIMIT 1$").
+		WithArgs(otherCategoryID, otherItemID).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}))
+	s.mock.ExpectExec("^UPDATE items SET rank = ranked.rank FROM \\(VALUES (.+)\\) AS ranked\\(id, rank\\)*").
+		WithArgs(otherItemID, "i").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := db.Manager.Transaction(func(tx *gorm.DB) error {
+		return rankItems(tx, items, false)
+	})
+	require.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
 }
 
 // Mark item as completed
@@ -840,6 +1628,77 @@ func (s *Suite) TestMarkItemAsCompleted_Updated() {
 	require.NoError(s.T(), err)
 }
 
+// Assign item
+
+func (s *Suite) TestAssignItem_ItemNotFound() {
+	itemID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	args := map[string]interface{}{"itemId": itemID, "userId": uuid.NewV4().String()}
+	_, e := AssignItem(userID, args)
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "item not found", e.Error())
+}
+
+func (s *Suite) TestAssignItem_AssigneeNotStoreMember() {
+	itemID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	assigneeID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "grocery_trip_id"}).AddRow(itemID, tripID))
+	s.mock.ExpectQuery("^SELECT \"store_id\" FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"store_id"}).AddRow(storeID))
+	s.mock.ExpectQuery("^SELECT count*").
+		WithArgs(storeID, assigneeID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	args := map[string]interface{}{"itemId": itemID, "userId": assigneeID.String()}
+	_, e := AssignItem(userID, args)
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "assignee is not a member of this store", e.Error())
+}
+
+func (s *Suite) TestAssignItem_Assigned() {
+	itemID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	assigneeID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "grocery_trip_id", "name"}).AddRow(itemID, tripID, "Ham"))
+	s.mock.ExpectQuery("^SELECT \"store_id\" FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"store_id"}).AddRow(storeID))
+	s.mock.ExpectQuery("^SELECT count*").
+		WithArgs(storeID, assigneeID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
+		WithArgs(assigneeID, AnyTime{}, itemID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	// activity.Record
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(storeID, userID, "item_assigned", "item", itemID, "Ham", sqlmock.AnyArg(), AnyTime{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	args := map[string]interface{}{"itemId": itemID, "userId": assigneeID.String()}
+	item, err := AssignItem(userID, args)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), assigneeID, *item.AssigneeID)
+}
+
 // Delete item
 
 func (s *Suite) TestDeleteItem_ItemNotFound() {
@@ -848,7 +1707,7 @@ func (s *Suite) TestDeleteItem_ItemNotFound() {
 		WithArgs(itemID).
 		WillReturnRows(sqlmock.NewRows([]string{}))
 
-	_, e := DeleteItem(itemID)
+	_, e := DeleteItem(uuid.NewV4(), itemID)
 	require.Error(s.T(), e)
 	assert.Equal(s.T(), "item not found", e.Error())
 }
@@ -872,7 +1731,7 @@ func (s *Suite) TestDeleteItem_SuccessMoreInCategory() {
 		WithArgs(categoryID).
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
-	item, err := DeleteItem(itemID)
+	item, err := DeleteItem(uuid.NewV4(), itemID)
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), itemID, item.ID)
 }
@@ -901,61 +1760,882 @@ func (s *Suite) TestDeleteItem_SuccessLastInCategory() {
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	s.mock.ExpectCommit()
 
-	item, err := DeleteItem(itemID)
+	item, err := DeleteItem(uuid.NewV4(), itemID)
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), itemID, item.ID)
 }
 
-// Item search
-
-func (s *Suite) TestSearchForItemByName_NoTripHistory() {
+func (s *Suite) TestRestoreItem_NotDeleted() {
+	itemID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted_at"}).AddRow(itemID, nil))
+
+	_, err := RestoreItem(userID, itemID)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "this item has not been deleted", err.Error())
+}
+
+func (s *Suite) TestRestoreItem_RestorePeriodPassed() {
+	itemID := uuid.NewV4()
+	userID := uuid.NewV4()
+	deletedAt := time.Now().Add(-models.ItemRestorePeriod - time.Hour)
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted_at"}).AddRow(itemID, deletedAt))
+
+	_, err := RestoreItem(userID, itemID)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "this item can no longer be restored", err.Error())
+}
+
+func (s *Suite) TestRestoreItem_RestoredWithCategory() {
+	itemID := uuid.NewV4()
+	userID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	categoryID := uuid.NewV4()
+	storeCategoryID := uuid.NewV4()
+	deletedAt := time.Now().Add(-time.Hour)
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "category_id", "name", "deleted_at"}).
+			AddRow(itemID, tripID, categoryID, "Apples", deletedAt))
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trip_categories\" WHERE id = (.+)$").
+		WithArgs(categoryID).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "store_category_id", "deleted_at"}).
+			AddRow(categoryID, tripID, storeCategoryID, deletedAt))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trip_categories\" WHERE \\(grocery_trip_id = (.+)$").
+		WithArgs(tripID, storeCategoryID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+	s.mock.ExpectExec("^UPDATE \"grocery_trip_categories\" SET \"deleted_at\"=(.+)$").
+		WithArgs(nil, categoryID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
+		WithArgs(categoryID, nil, itemID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	item, err := RestoreItem(userID, itemID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), itemID, item.ID)
+	assert.Equal(s.T(), categoryID, *item.CategoryID)
+	assert.False(s.T(), item.DeletedAt.Valid)
+}
+
+func (s *Suite) TestRetrieveRecentlyDeletedItems_Found() {
+	userID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT grocery_trips.\\* FROM \"grocery_trips\"*").
+		WithArgs(tripID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(tripID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\" WHERE grocery_trip_id = (.+) AND deleted_at > (.+) ORDER BY deleted_at DESC$").
+		WithArgs(tripID, AnyTime{}).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "name", "deleted_at"}).
+			AddRow(uuid.NewV4(), "Apples", time.Now().Add(-time.Minute)).
+			AddRow(uuid.NewV4(), "Bananas", time.Now().Add(-time.Hour)))
+
+	items, err := RetrieveRecentlyDeletedItems(userID, tripID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), items, 2)
+	assert.Equal(s.T(), "Apples", items[0].Name)
+}
+
+// Reopening trips
+
+func (s *Suite) TestReopenTrip_NotCompleted() {
+	userID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT grocery_trips.\\* FROM \"grocery_trips\"*").
+		WithArgs(tripID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "completed"}).AddRow(tripID, false))
+
+	_, err := ReopenTrip(userID, tripID)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "trip is not completed", err.Error())
+}
+
+func (s *Suite) TestReopenTrip_NoRollover() {
 	userID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT grocery_trips.\\* FROM \"grocery_trips\"*").
+		WithArgs(tripID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "completed"}).AddRow(tripID, true))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"trip_rollovers\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	_, err := ReopenTrip(userID, tripID)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "this trip can no longer be reopened", err.Error())
+}
+
+func (s *Suite) TestReopenTrip_NextTripCompleted() {
+	userID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	nextTripID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT grocery_trips.\\* FROM \"grocery_trips\"*").
+		WithArgs(tripID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "completed"}).AddRow(tripID, true))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"trip_rollovers\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "grocery_trip_id", "next_trip_id"}).AddRow(uuid.NewV4(), tripID, nextTripID))
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
-		WithArgs(userID).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		WithArgs(nextTripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "completed"}).AddRow(nextTripID, true))
 
-	_, e := SearchForItemByName("zap", userID)
-	require.Error(s.T(), e)
-	assert.Equal(s.T(), "no item matches the search term", e.Error())
+	_, err := ReopenTrip(userID, tripID)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "the next trip has already been completed", err.Error())
 }
 
-func (s *Suite) TestSearchForItemByName_ItemNotFound() {
+func (s *Suite) TestReopenTrip_UntouchedNextTripRemoved() {
 	userID := uuid.NewV4()
+	storeID := uuid.NewV4()
 	tripID := uuid.NewV4()
+	nextTripID := uuid.NewV4()
+	rolloverID := uuid.NewV4()
+	completedItemID := uuid.NewV4()
+	originalItemID := uuid.NewV4()
+	copiedItemID := uuid.NewV4()
+	stapleItemID := uuid.NewV4()
+	rolledOverAt := time.Now().Add(-time.Hour)
+	s.mock.ExpectQuery("^SELECT grocery_trips.\\* FROM \"grocery_trips\"*").
+		WithArgs(tripID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "completed"}).AddRow(tripID, storeID, "Trip 1", true))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"trip_rollovers\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "next_trip_id", "completed_item_ids", "copied_item_ids", "staple_last_added_at", "created_at"}).
+			AddRow(
+				rolloverID,
+				tripID,
+				nextTripID,
+				fmt.Sprintf("[\"%s\",\"%s\"]", completedItemID, originalItemID),
+				fmt.Sprintf("{\"%s\":\"%s\"}", copiedItemID, originalItemID),
+				fmt.Sprintf("{\"%s\":null}", stapleItemID),
+				rolledOverAt,
+			))
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
-		WithArgs(userID).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(tripID))
+		WithArgs(nextTripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "completed", "updated_at"}).AddRow(nextTripID, storeID, false, rolledOverAt))
+	s.mock.ExpectQuery("^SELECT count(.+) FROM \"items\"*").
+		WithArgs(nextTripID, rolledOverAt, true, copiedItemID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 
-	name := "zonk"
-	nameArg := fmt.Sprintf("%%%s%%", name)
+	s.mock.ExpectBegin()
+	// Restore the items marked as completed
+	s.mock.ExpectExec("^UPDATE \"items\" SET \"completed\"=(.+)$").
+		WithArgs(false, completedItemID, originalItemID, tripID).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	// Move the copied item back
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
-		WithArgs(nameArg, tripID).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+		WithArgs(copiedItemID, nextTripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "grocery_trip_id", "name", "quantity", "completed"}).AddRow(copiedItemID, nextTripID, "Apples", 3, false))
+	s.mock.ExpectExec("^UPDATE \"items\" SET (.+) WHERE id = (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"items\" SET \"deleted_at\"=(.+)$").
+		WithArgs(AnyTime{}, copiedItemID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trip_categories\" SET \"deleted_at\"=(.+)$").
+		WithArgs(AnyTime{}, nextTripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// Remove the next trip and reset the staple item
+	s.mock.ExpectExec("^UPDATE \"items\" SET \"deleted_at\"=(.+)$").
+		WithArgs(AnyTime{}, nextTripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trip_categories\" SET \"deleted_at\"=(.+)$").
+		WithArgs(AnyTime{}, nextTripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET \"deleted_at\"=(.+)$").
+		WithArgs(AnyTime{}, nextTripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"store_staple_items\" SET \"last_added_at\"=(.+)$").
+		WithArgs(nil, stapleItemID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// Reopen the trip
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET \"completed\"=(.+)$").
+		WithArgs(false, AnyTime{}, tripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^DELETE FROM \"assignee_completions\"*").
+		WithArgs(tripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^DELETE FROM \"trip_rollovers\"*").
+		WithArgs(rolloverID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// activity.Record, in a savepoint of the same transaction
+	s.mock.ExpectExec("^SAVEPOINT sp(.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(storeID, userID, "trip_reopened", "trip", tripID, "Trip 1", nil, AnyTime{}).
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
 
-	result, err := SearchForItemByName(name, userID)
+	trip, err := ReopenTrip(userID, tripID)
 	require.NoError(s.T(), err)
-	assert.Equal(s.T(), "", result.Name)
+	assert.False(s.T(), trip.Completed)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+// Move or copy item to store
+
+func (s *Suite) TestMoveItemToStore_TargetStoreNotMember() {
+	userID := uuid.NewV4()
+	itemID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	targetStoreID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "grocery_trip_id", "name"}).AddRow(itemID, tripID, "Frozen peas"))
+	s.mock.ExpectQuery("^SELECT stores.\\* FROM \"stores\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(storeID, "Metro"))
+	s.mock.ExpectQuery("^SELECT stores.\\* FROM \"stores\"*").
+		WithArgs(targetStoreID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	args := map[string]interface{}{"itemId": itemID, "storeId": targetStoreID}
+	_, err := MoveItemToStore(userID, args)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "store not found", err.Error())
 }
 
-func (s *Suite) TestSearchForItemByName_Found() {
+func (s *Suite) TestMoveItemToStore_TargetStoreArchived() {
 	userID := uuid.NewV4()
+	itemID := uuid.NewV4()
 	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	targetStoreID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "grocery_trip_id", "name"}).AddRow(itemID, tripID, "Frozen peas"))
+	s.mock.ExpectQuery("^SELECT stores.\\* FROM \"stores\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(storeID, "Metro"))
+	// The user is still a member of the target store, but it has been archived
+	s.mock.ExpectQuery("^SELECT stores.\\* FROM \"stores\" (.+) AND \\(stores.archived_at IS NULL\\)*").
+		WithArgs(targetStoreID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	args := map[string]interface{}{"itemId": itemID, "storeId": targetStoreID}
+	_, err := MoveItemToStore(userID, args)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "store not found", err.Error())
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestCopyItemToStore_SameStore() {
+	userID := uuid.NewV4()
+	itemID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "grocery_trip_id", "name"}).AddRow(itemID, tripID, "Frozen peas"))
+	s.mock.ExpectQuery("^SELECT stores.\\* FROM \"stores\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(storeID, "Metro"))
+	s.mock.ExpectQuery("^SELECT stores.\\* FROM \"stores\"*").
+		WithArgs(storeID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(storeID, "Metro"))
+
+	args := map[string]interface{}{"itemId": itemID, "storeId": storeID}
+	_, err := CopyItemToStore(userID, args)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "item is already in this store", err.Error())
+}
+
+func (s *Suite) TestMoveItemToStore_Moved() {
+	userID := uuid.NewV4()
+	itemID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	categoryID := uuid.NewV4()
+	targetStoreID := uuid.NewV4()
+	targetTripID := uuid.NewV4()
+	mealID := uuid.NewV4()
+	itemName := "Frozen peas"
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "category_id", "name", "quantity", "notes", "meal_id", "meal_name"}).
+			AddRow(itemID, tripID, categoryID, itemName, 2, "the small bag", mealID, "Shepherd's pie"))
+	s.mock.ExpectQuery("^SELECT stores.\\* FROM \"stores\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(storeID, "Metro"))
+	s.mock.ExpectQuery("^SELECT stores.\\* FROM \"stores\"*").
+		WithArgs(targetStoreID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(targetStoreID, "Costco"))
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
-		WithArgs(userID).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(tripID))
+		WithArgs(targetStoreID, false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(targetTripID, targetStoreID))
 
-	name := "app"
-	nameArg := fmt.Sprintf("%%%s%%", name)
-	rows := sqlmock.
-		NewRows([]string{"id", "name"}).
-		AddRow(uuid.NewV4(), "Apples")
+	// addItem, categorised with the target store's settings, deleteItem and
+	// their activity in the same transaction
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(userID, targetTripID, false, "frozen peas", true).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(targetTripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(targetTripID, targetStoreID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(targetStoreID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "user_id"}).AddRow(uuid.NewV4(), targetStoreID, userID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_item_category_settings\"*").
+		WithArgs(targetStoreID, "frozen peas").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	targetCategoryID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT grocery_trip_categories.id FROM \"grocery_trip_categories\"*").
+		WithArgs(targetTripID, "Frozen Foods").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(targetCategoryID))
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\"*").
+		WithArgs(targetCategoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow("i"))
+	copyID := uuid.NewV4()
+	s.mock.ExpectQuery("^INSERT INTO \"items\" (.+)$").
+		WithArgs(targetTripID, targetCategoryID, "classifier", 1.0, userID, nil, nil, itemName, 2, nil, nil, false, "9", "the small bag", mealID, "Shepherd's pie", nil, nil, nil, AnyTime{}, AnyTime{}, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(copyID))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
+		WithArgs(AnyTime{}, targetTripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^SAVEPOINT sp(.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(targetStoreID, userID, "item_added", "item", copyID, itemName, nil, AnyTime{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectExec("^UPDATE \"items\" SET \"deleted_at\"(.+)$").
+		WithArgs(AnyTime{}, itemID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
+		WithArgs(AnyTime{}, tripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectQuery("^SELECT count*").
+		WithArgs(categoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	s.mock.ExpectExec("^SAVEPOINT sp(.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(storeID, userID, "item_moved", "item", itemID, itemName, `{"store":{"before":"Metro","after":"Costco"}}`, AnyTime{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	args := map[string]interface{}{"itemId": itemID, "storeId": targetStoreID}
+	item, err := MoveItemToStore(userID, args)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), copyID, item.ID)
+	assert.Equal(s.T(), targetTripID, item.GroceryTripID)
+	assert.Equal(s.T(), targetCategoryID, *item.CategoryID)
+	assert.Equal(s.T(), 2, item.Quantity)
+	assert.Equal(s.T(), "the small bag", *item.Notes)
+	assert.Equal(s.T(), mealID, *item.MealID)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestMoveItemToStore_DeleteFailsRollsBack() {
+	userID := uuid.NewV4()
+	itemID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	categoryID := uuid.NewV4()
+	targetStoreID := uuid.NewV4()
+	targetTripID := uuid.NewV4()
+	targetCategoryID := uuid.NewV4()
+	itemName := "Frozen peas"
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "category_id", "name", "quantity"}).
+			AddRow(itemID, tripID, categoryID, itemName, 1))
+	s.mock.ExpectQuery("^SELECT stores.\\* FROM \"stores\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(storeID, "Metro"))
+	s.mock.ExpectQuery("^SELECT stores.\\* FROM \"stores\"*").
+		WithArgs(targetStoreID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(targetStoreID, "Costco"))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(targetStoreID, false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(targetTripID, targetStoreID))
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(userID, targetTripID, false, "frozen peas", true).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(targetTripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(targetTripID, targetStoreID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(targetStoreID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "user_id"}).AddRow(uuid.NewV4(), targetStoreID, userID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_item_category_settings\"*").
+		WithArgs(targetStoreID, "frozen peas").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.mock.ExpectQuery("^SELECT grocery_trip_categories.id FROM \"grocery_trip_categories\"*").
+		WithArgs(targetTripID, "Frozen Foods").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(targetCategoryID))
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\"*").
+		WithArgs(targetCategoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}))
+	s.mock.ExpectQuery("^INSERT INTO \"items\" (.+)$").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
+		WithArgs(AnyTime{}, targetTripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^SAVEPOINT sp(.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	// The original item can't be deleted, so the copy and its activity entry
+	// are rolled back with it
+	s.mock.ExpectExec("^UPDATE \"items\" SET \"deleted_at\"(.+)$").
+		WithArgs(AnyTime{}, itemID).
+		WillReturnError(fmt.Errorf("connection reset"))
+	s.mock.ExpectRollback()
+
+	args := map[string]interface{}{"itemId": itemID, "storeId": targetStoreID}
+	item, err := MoveItemToStore(userID, args)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "connection reset", err.Error())
+	assert.Nil(s.T(), item)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+// Bulk item operations
+
+func (s *Suite) TestCompleteItems_NoItems() {
+	_, err := CompleteItems(uuid.NewV4(), map[string]interface{}{"itemIds": []interface{}{}})
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "no items provided", err.Error())
+}
+
+func (s *Suite) TestCompleteItems_ItemNotFound() {
+	userID := uuid.NewV4()
+	itemID := uuid.NewV4()
+	otherItemID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, otherItemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
+
+	args := map[string]interface{}{
+		"itemIds": []interface{}{itemID.String(), otherItemID.String()},
+	}
+	_, err := CompleteItems(userID, args)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "item not found", err.Error())
+}
+
+func (s *Suite) TestCompleteItems_Completed() {
+	userID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	itemID := uuid.NewV4()
+	otherItemID := uuid.NewV4()
+	categoryID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, otherItemID, userID, true).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "category_id", "name"}).
+			AddRow(itemID, tripID, categoryID, "Apples").
+			AddRow(otherItemID, tripID, categoryID, "Bananas"))
+	s.mock.ExpectQuery("^SELECT id, store_id, name FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name"}).AddRow(tripID, storeID, "Trip 1"))
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("^UPDATE \"items\" SET \"completed\"=(.+),\"updated_at\"=(.+) WHERE id IN (.+)$").
+		WithArgs(true, AnyTime{}, itemID, otherItemID).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	// The category is locked, then the completed items are ranked after the
+	// last of the other items in it, and nothing else is changed
+	s.mock.ExpectQuery("^SELECT \"id\" FROM \"items\" (.+) FOR UPDATE$").
+		WithArgs(categoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID).AddRow(otherItemID))
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\" (.+) ORDER BY items.rank DESC, items.id DESC LIMIT 1$").
+		WithArgs(categoryID, itemID, otherItemID).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow("r"))
+	s.mock.ExpectExec("^UPDATE items SET rank = ranked.rank (.+)$").
+		WithArgs(itemID, "u", otherItemID, "w").
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET \"updated_at\"=(.+)$").
+		WithArgs(AnyTime{}, tripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// A single activity entry for the trip
+	s.mock.ExpectExec("^SAVEPOINT sp(.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(storeID, userID, "items_completed", "trip", tripID, "Trip 1", `{"items":{"before":null,"after":"Apples, Bananas"}}`, AnyTime{}).
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\" WHERE id IN (.+)$").
+		WithArgs(itemID, otherItemID).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "category_id", "name", "completed", "rank"}).
+			AddRow(itemID, tripID, categoryID, "Apples", true, "u").
+			AddRow(otherItemID, tripID, categoryID, "Bananas", true, "w"))
+
+	args := map[string]interface{}{
+		"itemIds": []interface{}{itemID.String(), otherItemID.String()},
+	}
+	items, err := CompleteItems(userID, args)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), items, 2)
+	assert.True(s.T(), *items[0].Completed)
+	assert.Equal(s.T(), "w", items[1].Rank)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestDeleteItems_Deleted() {
+	userID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	itemID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "grocery_trip_id", "name"}).AddRow(itemID, tripID, "Apples"))
+	s.mock.ExpectQuery("^SELECT id, store_id, name FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name"}).AddRow(tripID, storeID, "Trip 1"))
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("^UPDATE \"items\" SET \"deleted_at\"=(.+) WHERE id IN (.+)$").
+		WithArgs(AnyTime{}, itemID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trip_categories\" SET \"deleted_at\"=(.+) WHERE grocery_trip_id IN (.+) AND \\(NOT EXISTS (.+)$").
+		WithArgs(AnyTime{}, tripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET \"updated_at\"=(.+)$").
+		WithArgs(AnyTime{}, tripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^SAVEPOINT sp(.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(storeID, userID, "items_deleted", "trip", tripID, "Trip 1", sqlmock.AnyArg(), AnyTime{}).
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	args := map[string]interface{}{
+		"itemIds": []interface{}{itemID.String()},
+	}
+	items, err := DeleteItems(userID, args)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), items, 1)
+	assert.True(s.T(), items[0].DeletedAt.Valid)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestMoveItems_DifferentStore() {
+	userID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	toTripID := uuid.NewV4()
+	itemID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "grocery_trip_id", "name"}).AddRow(itemID, tripID, "Apples"))
+	s.mock.ExpectQuery("^SELECT id, store_id, name FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name"}).AddRow(tripID, uuid.NewV4(), "Trip 1"))
+	s.mock.ExpectQuery("^SELECT grocery_trips.\\* FROM \"grocery_trips\"*").
+		WithArgs(toTripID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(toTripID, uuid.NewV4()))
+
+	args := map[string]interface{}{
+		"itemIds":  []interface{}{itemID.String()},
+		"toTripId": toTripID,
+	}
+	_, err := MoveItems(userID, args)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "items can only be moved to a trip in the same store", err.Error())
+}
+
+func (s *Suite) TestMoveItems_CompletedTrip() {
+	userID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	toTripID := uuid.NewV4()
+	itemID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "grocery_trip_id", "name"}).AddRow(itemID, tripID, "Apples"))
+	s.mock.ExpectQuery("^SELECT id, store_id, name FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name"}).AddRow(tripID, storeID, "Trip 1"))
+	s.mock.ExpectQuery("^SELECT grocery_trips.\\* FROM \"grocery_trips\"*").
+		WithArgs(toTripID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "completed"}).AddRow(toTripID, storeID, true))
+
+	args := map[string]interface{}{
+		"itemIds":  []interface{}{itemID.String()},
+		"toTripId": toTripID,
+	}
+	_, err := MoveItems(userID, args)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "items can't be moved to a completed trip", err.Error())
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestMoveItems_Moved() {
+	userID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	toTripID := uuid.NewV4()
+	itemID := uuid.NewV4()
+	categoryID := uuid.NewV4()
+	storeCategoryID := uuid.NewV4()
+	newCategoryID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "category_id", "name"}).
+			AddRow(itemID, tripID, categoryID, "Apples"))
+	s.mock.ExpectQuery("^SELECT id, store_id, name FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name"}).AddRow(tripID, storeID, "Trip 1"))
+	s.mock.ExpectQuery("^SELECT grocery_trips.\\* FROM \"grocery_trips\"*").
+		WithArgs(toTripID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(toTripID, storeID))
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT id, store_category_id FROM \"grocery_trip_categories\"*").
+		WithArgs(categoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_category_id"}).AddRow(categoryID, storeCategoryID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trip_categories\"*").
+		WithArgs(toTripID, storeCategoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "grocery_trip_id", "store_category_id"}).AddRow(newCategoryID, toTripID, storeCategoryID))
+	s.mock.ExpectExec("^UPDATE \"items\" SET (.+) WHERE id IN (.+)$").
+		WithArgs(newCategoryID, toTripID, AnyTime{}, itemID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trip_categories\" SET \"deleted_at\"=(.+)$").
+		WithArgs(AnyTime{}, tripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// The moved items go to the top of their category in the trip they're
+	// moved to
+	s.mock.ExpectQuery("^SELECT \"id\" FROM \"items\" (.+) FOR UPDATE$").
+		WithArgs(newCategoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\" (.+) ORDER BY items.rank, items.id LIMIT 1$").
+		WithArgs(newCategoryID, itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}).AddRow("5"))
+	s.mock.ExpectExec("^UPDATE items SET rank = ranked.rank (.+)$").
+		WithArgs(itemID, "3").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET \"updated_at\"=(.+)$").
+		WithArgs(AnyTime{}, tripID, toTripID).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	s.mock.ExpectExec("^SAVEPOINT sp(.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(storeID, userID, "items_moved", "trip", tripID, "Trip 1", sqlmock.AnyArg(), AnyTime{}).
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\" WHERE id IN (.+)$").
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "category_id", "name", "rank"}).
+			AddRow(itemID, toTripID, newCategoryID, "Apples", "3"))
+
+	args := map[string]interface{}{
+		"itemIds":  []interface{}{itemID.String()},
+		"toTripId": toTripID,
+	}
+	items, err := MoveItems(userID, args)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), items, 1)
+	assert.Equal(s.T(), toTripID, items[0].GroceryTripID)
+	assert.Equal(s.T(), newCategoryID, *items[0].CategoryID)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestSetItemsCategory_CategoryNotInStore() {
+	userID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	itemID := uuid.NewV4()
+	storeCategoryID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "grocery_trip_id", "name"}).AddRow(itemID, tripID, "Apples"))
+	s.mock.ExpectQuery("^SELECT id, store_id, name FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name"}).AddRow(tripID, uuid.NewV4(), "Trip 1"))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_categories\"*").
+		WithArgs(storeCategoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name"}).AddRow(storeCategoryID, uuid.NewV4(), "Produce"))
+
+	args := map[string]interface{}{
+		"itemIds":         []interface{}{itemID.String()},
+		"storeCategoryId": storeCategoryID,
+	}
+	_, err := SetItemsCategory(userID, args)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "category not found", err.Error())
+}
+
+func (s *Suite) TestSetItemsCategory_KeptWhenItemUpdated() {
+	userID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	itemID := uuid.NewV4()
+	categoryID := uuid.NewV4()
+	storeCategoryID := uuid.NewV4()
+	newCategoryID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.\\* FROM \"items\"*").
+		WithArgs(itemID, userID, true).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "category_id", "category_source", "user_id", "name"}).
+			AddRow(itemID, tripID, categoryID, "classifier", userID, "Apples"))
+	s.mock.ExpectQuery("^SELECT id, store_id, name FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name"}).AddRow(tripID, storeID, "Trip 1"))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_categories\"*").
+		WithArgs(storeCategoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name"}).AddRow(storeCategoryID, storeID, "Bakery"))
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trip_categories\"*").
+		WithArgs(tripID, storeCategoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "grocery_trip_id", "store_category_id"}).AddRow(newCategoryID, tripID, storeCategoryID))
+	s.mock.ExpectExec("^UPDATE \"items\" SET (.+) WHERE id IN (.+)$").
+		WithArgs(1, newCategoryID, models.CategorySourceUser, AnyTime{}, itemID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trip_categories\" SET \"deleted_at\"=(.+)$").
+		WithArgs(AnyTime{}, tripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectQuery("^SELECT \"id\" FROM \"items\" (.+) FOR UPDATE$").
+		WithArgs(newCategoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
+	s.mock.ExpectQuery("^SELECT \"rank\" FROM \"items\" (.+) ORDER BY items.rank, items.id LIMIT 1$").
+		WithArgs(newCategoryID, itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"rank"}))
+	s.mock.ExpectExec("^UPDATE items SET rank = ranked.rank (.+)$").
+		WithArgs(itemID, "i").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET \"updated_at\"=(.+)$").
+		WithArgs(AnyTime{}, tripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^SAVEPOINT sp(.+)$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery("^INSERT INTO \"store_activities\" (.+)$").
+		WithArgs(storeID, userID, "items_recategorised", "trip", tripID, "Trip 1", sqlmock.AnyArg(), AnyTime{}).
+		WillReturnRows(s.mock.NewRows([]string{"id"}).AddRow(uuid.NewV4()))
+	s.mock.ExpectCommit()
+
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\" WHERE id IN (.+)$").
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "category_id", "category_source", "user_id", "name", "rank"}).
+			AddRow(itemID, tripID, newCategoryID, "user", userID, "Apples", "i"))
+
+	args := map[string]interface{}{
+		"itemIds":         []interface{}{itemID.String()},
+		"storeCategoryId": storeCategoryID,
+	}
+	items, err := SetItemsCategory(userID, args)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), items, 1)
+	assert.Equal(s.T(), newCategoryID, *items[0].CategoryID)
+
+	// Renaming the item afterwards keeps the category it was moved to
 	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
-		WithArgs(nameArg, tripID).
-		WillReturnRows(rows)
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.
+			NewRows([]string{"id", "grocery_trip_id", "category_id", "category_source", "user_id", "name", "rank"}).
+			AddRow(itemID, tripID, newCategoryID, "user", userID, "Apples", "i"))
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(tripID).
+		WillReturnRows(s.mock.NewRows([]string{"id", "store_id"}).AddRow(tripID, storeID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(storeID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "user_id"}).AddRow(uuid.NewV4(), storeID, userID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\"*").
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "category_id", "rank"}).AddRow("Apples", newCategoryID, "i"))
+	s.mock.ExpectExec("^UPDATE \"items\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
 
-	item, err := SearchForItemByName(name, userID)
+	item, err := UpdateItem(userID, map[string]interface{}{"itemId": itemID, "name": "Apple pie"})
 	require.NoError(s.T(), err)
-	assert.Equal(s.T(), "Apples", item.Name)
+	assert.Equal(s.T(), newCategoryID, *item.(*models.Item).CategoryID)
+	assert.Equal(s.T(), models.CategorySourceUser, *item.(*models.Item).CategorySource)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+// Item search
+
+func (s *Suite) TestSearchItems_BlankTerm() {
+	_, err := SearchItems(uuid.NewV4(), map[string]interface{}{"query": "  "})
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), "search term can't be blank", err.Error())
+}
+
+func (s *Suite) TestSearchItems_NoMatches() {
+	userID := uuid.NewV4()
+	// Items in archived stores aren't searched
+	s.mock.ExpectQuery("^SELECT count(.+) FROM \"items\" (.+) stores.archived_at IS NULL(.+)$").
+		WithArgs(userID, true, "zonk", "%zonk%").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	results, err := SearchItems(userID, map[string]interface{}{"query": "zonk"})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(0), results.Total)
+	assert.Len(s.T(), results.Stores, 0)
+}
+
+func (s *Suite) TestSearchItems_GroupsByStoreAndTrip() {
+	userID := uuid.NewV4()
+	storeID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT count(.+) FROM \"items\"*").
+		WithArgs(userID, true, "brocoli", "%brocoli%", storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	item1, item2, item3 := uuid.NewV4(), uuid.NewV4(), uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT items.id, word_similarity(.+) AS score FROM \"items\"*").
+		WithArgs("brocoli", userID, true, "brocoli", "%brocoli%", storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "score"}).
+			AddRow(item1, 0.7).
+			AddRow(item2, 0.7).
+			AddRow(item3, 0.5))
+
+	trip1, trip2 := uuid.NewV4(), uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"items\" WHERE id IN*").
+		WithArgs(item1, item2, item3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "grocery_trip_id", "name", "completed"}).
+			AddRow(item2, trip2, "Broccoli", true).
+			AddRow(item1, trip1, "Broccoli", false).
+			AddRow(item3, trip1, "Broccolini", false))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\" WHERE id IN*").
+		WithArgs(trip2, trip1, trip1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "completed"}).
+			AddRow(trip1, storeID, "Trip 2", false).
+			AddRow(trip2, storeID, "Trip 1", true))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"stores\" WHERE id IN*").
+		WithArgs(storeID, storeID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(storeID, "Grocery Store"))
+
+	args := map[string]interface{}{"query": "brocoli", "storeId": storeID}
+	results, err := SearchItems(userID, args)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(3), results.Total)
+	require.Len(s.T(), results.Stores, 1)
+	assert.Equal(s.T(), "Grocery Store", results.Stores[0].Store.Name)
+	require.Len(s.T(), results.Stores[0].Trips, 2)
+	assert.Equal(s.T(), trip1, results.Stores[0].Trips[0].Trip.ID)
+	require.Len(s.T(), results.Stores[0].Trips[0].Hits, 2)
+	assert.Equal(s.T(), item3, results.Stores[0].Trips[0].Hits[1].Item.ID)
+	assert.Equal(s.T(), trip2, results.Stores[0].Trips[1].Trip.ID)
+	assert.True(s.T(), *results.Stores[0].Trips[1].Hits[0].Item.Completed)
 }
 
 // TODO: duplicated code with the store model... DRY this up
@@ -984,3 +2664,96 @@ func fetchCategories() [20]string {
 	}
 	return categories
 }
+
+// Store schedules
+
+func (s *Suite) TestSetStoreSchedule_InvalidWeekday() {
+	storeID := uuid.NewV4()
+	userID := uuid.NewV4()
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_users\"*").
+		WithArgs(storeID, userID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(uuid.NewV4(), storeID))
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"store_schedules\"*").
+		WithArgs(storeID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	args := map[string]interface{}{"weekday": 7, "timezone": "America/Toronto"}
+	_, e := SetStoreSchedule(storeID, userID, args)
+	require.Error(s.T(), e)
+	assert.Equal(s.T(), "weekday must be between 0 (Sunday) and 6 (Saturday)", e.Error())
+}
+
+func (s *Suite) TestNextShoppingDate() {
+	schedule := models.StoreSchedule{Weekday: int(time.Saturday), Timezone: "America/Toronto"}
+
+	// Thursday evening in Toronto is already Friday in UTC
+	next, err := schedule.NextShoppingDate(time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC))
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "2026-10-17", next.Format(models.ShoppingDateLayout))
+
+	next, err = schedule.NextShoppingDate(time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC))
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "2026-10-17", next.Format(models.ShoppingDateLayout))
+}
+
+func (s *Suite) TestSendShoppingReminders_LeavesOutArchivedStores() {
+	s.mock.ExpectQuery("^SELECT grocery_trips.\\* FROM \"grocery_trips\" INNER JOIN stores (.+) WHERE \\(stores.archived_at IS NULL AND stores.deleted_at IS NULL\\)(.+)$").
+		WithArgs(false, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	err := SendShoppingReminders()
+	require.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestRunStoreSchedule_TripAlreadyPlanned() {
+	storeID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	copyRemainingItems := true
+	schedule := models.StoreSchedule{StoreID: storeID, Weekday: int(time.Saturday), Timezone: "UTC", CopyRemainingItems: &copyRemainingItems}
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(storeID, false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "shopping_date"}).AddRow(tripID, storeID, "2026-10-24"))
+
+	err := runStoreSchedule(schedule, time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+	require.NoError(s.T(), err)
+}
+
+func (s *Suite) TestRunStoreSchedule_PlansUnplannedTrip() {
+	storeID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	copyRemainingItems := true
+	schedule := models.StoreSchedule{StoreID: storeID, Weekday: int(time.Saturday), Timezone: "UTC", CopyRemainingItems: &copyRemainingItems}
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(storeID, false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).AddRow(tripID, storeID))
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET \"shopping_date\"(.+)$").
+		WithArgs("2026-10-24", tripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := runStoreSchedule(schedule, time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+	require.NoError(s.T(), err)
+}
+
+func (s *Suite) TestRunStoreSchedule_EmptyTripMovedToNextShoppingDay() {
+	storeID := uuid.NewV4()
+	tripID := uuid.NewV4()
+	copyRemainingItems := true
+	schedule := models.StoreSchedule{StoreID: storeID, Weekday: int(time.Saturday), Timezone: "UTC", CopyRemainingItems: &copyRemainingItems}
+	s.mock.ExpectQuery("^SELECT (.+) FROM \"grocery_trips\"*").
+		WithArgs(storeID, false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "shopping_date"}).AddRow(tripID, storeID, "2026-10-17"))
+	s.mock.ExpectQuery("^SELECT count*").
+		WithArgs(tripID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("^UPDATE \"grocery_trips\" SET (.+)$").
+		WithArgs(nil, "2026-10-24", tripID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := runStoreSchedule(schedule, time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+	require.NoError(s.T(), err)
+}
@@ -4,21 +4,37 @@ import (
 	"encoding/json"
 	"strings"
 
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
 	uuid "github.com/satori/go.uuid"
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
-// UpdateItem updates an item by itemID
-func UpdateItem(args map[string]interface{}) (interface{}, error) {
+// UpdateItem updates an item by itemID on behalf of the user with userID
+func UpdateItem(userID uuid.UUID, args map[string]interface{}) (interface{}, error) {
 	item := &models.Item{}
 	if err := db.Manager.Where("id = ?", args["itemId"]).First(&item).Error; err != nil {
 		return nil, err
 	}
+	if args["position"] != nil {
+		position, err := item.PositionInCategory(db.Manager)
+		if err != nil {
+			return nil, err
+		}
+		item.Position = position
+	}
+	before := activity.ItemAttributes(*item)
 
-	if args["name"] != nil {
+	if args["locale"] != nil {
+		item.Locale = args["locale"].(string)
+	}
+	if args["name"] != nil && args["name"].(string) != item.Name {
+		// Only a new name is parsed, so that saving an item doesn't split its
+		// name again (i.e. "7 Up" into 7 of "Up")
 		item.Name = args["name"].(string)
+		item.ParseName()
 	}
 	if args["completed"] != nil {
 		completed := args["completed"].(bool)
@@ -27,13 +43,16 @@ func UpdateItem(args map[string]interface{}) (interface{}, error) {
 	if args["quantity"] != nil {
 		item.Quantity = args["quantity"].(int)
 	}
-	if args["position"] != nil {
-		item.Position = args["position"].(int)
-	}
 	if args["notes"] != nil {
 		notes := args["notes"].(string)
 		item.Notes = &notes
 	}
+	if err := setItemAmount(item, args); err != nil {
+		return nil, err
+	}
+	if err := setItemPrice(item, args); err != nil {
+		return nil, err
+	}
 	if args["storeCategoryId"] != nil {
 		storeCategoryID, _ := uuid.FromString(args["storeCategoryId"].(string))
 		groceryTripCategory := models.GroceryTripCategory{
@@ -46,6 +65,9 @@ func UpdateItem(args map[string]interface{}) (interface{}, error) {
 			return nil, err
 		}
 		item.CategoryID = &groceryTripCategory.ID
+		source, confidence := models.CategorySourceUser, 1.0
+		item.CategorySource = &source
+		item.CategoryConfidence = &confidence
 
 		// If the user opted to save the store category ID, add it to
 		// the store_item_category_settings table
@@ -57,28 +79,71 @@ func UpdateItem(args map[string]interface{}) (interface{}, error) {
 		}
 	}
 
-	if err := db.Manager.Save(&item).Error; err != nil {
+	var changes map[string]activity.Change
+	err := db.Manager.Transaction(func(tx *gorm.DB) error {
+		if args["position"] != nil && item.CategoryID != nil {
+			position := args["position"].(int)
+			if position < 1 {
+				position = 1
+			}
+			if err := models.LockCategory(tx, *item.CategoryID); err != nil {
+				return err
+			}
+			if err := setItemPosition(tx, item, position); err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(&item).Error; err != nil {
+			return err
+		}
+
+		changes = activity.Diff(before, activity.ItemAttributes(*item))
+		if len(changes) > 0 {
+			activity.RecordForTrip(tx, item.GroceryTripID, userID, itemUpdateAction(changes), itemEntity(item), changes)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
+
+	if item.UnitPrice != nil && changesPrice(changes) {
+		checkTripBudget(item.GroceryTripID)
+	}
+	if change, ok := changes["completed"]; ok && change.After == true {
+		if err := recordStaplePurchases([]*models.Item{item}); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := changes["name"]; ok && item.Barcode != nil {
+		rememberProductName(item, userID)
+	}
+
 	return item, nil
 }
 
-// GetNewPosition gets the new position of an updated item
-func GetNewPosition(tripID uuid.UUID, completed bool) int {
-	newPosition := 1
-	if completed {
-		// If the item was marked completed, move to the bottom of the store
-		// The BeforeUpdate hook on items will handle reordering the items around it
-		bottomItem := &models.Item{}
-		db.Manager.
-			Select("position").
-			Where("grocery_trip_id = ?", tripID).
-			Order("position DESC").
-			Limit(1).
-			Find(&bottomItem)
-		newPosition = bottomItem.Position
-	}
-	return newPosition
+// itemUpdateAction determines the activity action to record for the changes
+// made to an item, so that a simple check off or reorder reads as such
+func itemUpdateAction(changes map[string]activity.Change) string {
+	if len(changes) == 1 {
+		if change, ok := changes["completed"]; ok && change.After == true {
+			return models.ActivityItemCompleted
+		}
+		if _, ok := changes["position"]; ok {
+			return models.ActivityItemReordered
+		}
+	}
+	return models.ActivityItemUpdated
+}
+
+// changesPrice returns whether the changes made to an item affect its total price
+func changesPrice(changes map[string]activity.Change) bool {
+	for _, attribute := range []string{"unitPrice", "currency", "quantity", "decimalQuantity"} {
+		if _, ok := changes[attribute]; ok {
+			return true
+		}
+	}
+	return false
 }
 
 // SaveStoreCategorySelection updates the store item category settings
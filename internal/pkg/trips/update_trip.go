@@ -4,18 +4,21 @@ import (
 	"errors"
 	"time"
 
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/activity"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db/models"
 	uuid "github.com/satori/go.uuid"
 	"gorm.io/gorm"
 )
 
-// UpdateTrip updates a grocery trip with the given args by tripID
-func UpdateTrip(args map[string]interface{}) (interface{}, error) {
+// UpdateTrip updates a grocery trip with the given args by tripID on behalf
+// of the user with userID (which is uuid.Nil when the system updates a trip)
+func UpdateTrip(userID uuid.UUID, args map[string]interface{}) (interface{}, error) {
 	var trip models.GroceryTrip
 	if err := db.Manager.Where("id = ?", args["tripId"]).First(&trip).Error; err != nil {
 		return nil, errors.New("trip does not exist")
 	}
+	wasCompleted := trip.Completed
 	if args["name"] != nil {
 		trip.Name = args["name"].(string)
 	}
@@ -25,14 +28,42 @@ func UpdateTrip(args map[string]interface{}) (interface{}, error) {
 	if args["copyRemainingItems"] != nil {
 		trip.CopyRemainingItems = args["copyRemainingItems"].(bool)
 	}
+	if args["shoppingDate"] != nil {
+		shoppingDate, err := parseShoppingDate(args["shoppingDate"].(string))
+		if err != nil {
+			return nil, err
+		}
+		// Changing the planned date means members should be reminded again
+		trip.ShoppingDate = shoppingDate
+		trip.ReminderSentAt = nil
+	}
+	var newTripShoppingDate *string
+	if args["newTripShoppingDate"] != nil {
+		shoppingDate, err := parseShoppingDate(args["newTripShoppingDate"].(string))
+		if err != nil {
+			return nil, err
+		}
+		newTripShoppingDate = shoppingDate
+	}
 	if err := db.Manager.Save(&trip).Error; err != nil {
 		return nil, err
 	}
 
-	// If the trip was completed, create the next trip for the user
-	if trip.Completed {
+	if trip.Completed && !wasCompleted {
+		if err := recordAssigneeCompletion(trip); err != nil {
+			return nil, err
+		}
+		entity := activity.Entity{Type: activity.EntityTrip, ID: trip.ID, Name: trip.Name}
+		activity.Record(db.Manager, trip.StoreID, userID, models.ActivityTripCompleted, entity, nil)
+	}
+
+	// If the trip was just completed, create the next trip for the user. A
+	// trip that was already completed has already been rolled over
+	if trip.Completed && !wasCompleted {
 		var newTrip models.GroceryTrip
-		db.Manager.Transaction(func(tx *gorm.DB) error {
+		var completedItemIDs []uuid.UUID
+		copiedItemIDs := map[uuid.UUID]uuid.UUID{}
+		rolloverErr := db.Manager.Transaction(func(tx *gorm.DB) error {
 			var newTripName string
 			// If a newTripName argument is passed, use it instead of creating one from the
 			// current date in server time; typically this argument will be passed from
@@ -41,27 +72,40 @@ func UpdateTrip(args map[string]interface{}) (interface{}, error) {
 				newTripName = args["newTripName"].(string)
 			} else {
 				currentTime := time.Now()
-				newTripName = currentTime.Format("Jan 2, 2006")
+				newTripName = currentTime.Format(models.TripNameLayout)
 			}
-			newTrip = models.GroceryTrip{StoreID: trip.StoreID, Name: newTripName}
+			newTrip = models.GroceryTrip{StoreID: trip.StoreID, Name: newTripName, ShoppingDate: newTripShoppingDate}
 			if err := tx.Create(&newTrip).Error; err != nil {
 				return err
 			}
 
 			if trip.CopyRemainingItems {
-				if err := CopyRemainingItemsToNewTrip(trip, &newTrip, tx); err != nil {
+				copied, err := CopyRemainingItemsToNewTrip(trip, &newTrip, tx)
+				if err != nil {
 					return err
 				}
+				copiedItemIDs = copied
 			}
 
-			if err := MarkItemsInOldTripAsCompleted(trip, tx); err != nil {
+			completed, err := MarkItemsInOldTripAsCompleted(trip, tx)
+			if err != nil {
 				return err
 			}
+			completedItemIDs = completed
 
 			return nil
 		})
+		if rolloverErr != nil {
+			return trip, rolloverErr
+		}
+
+		stapleLastAddedAt, err := addStapleItemsToTrip(newTrip)
+		if err != nil {
+			return trip, err
+		}
 
-		if err := AddStapleItemsToNewTrip(newTrip); err != nil {
+		// Record what completing the trip did, so it can be reopened
+		if err := recordRollover(trip, newTrip, completedItemIDs, copiedItemIDs, stapleLastAddedAt); err != nil {
 			return trip, err
 		}
 	}
@@ -69,11 +113,27 @@ func UpdateTrip(args map[string]interface{}) (interface{}, error) {
 	return trip, nil
 }
 
+// parseShoppingDate validates a planned shopping date in the format YYYY-MM-DD.
+// A blank date clears the planned date
+func parseShoppingDate(date string) (*string, error) {
+	if len(date) == 0 {
+		return nil, nil
+	}
+	if _, err := time.Parse(models.ShoppingDateLayout, date); err != nil {
+		return nil, errors.New("shopping date must be in the format YYYY-MM-DD")
+	}
+	return &date, nil
+}
+
+// CopyRemainingItemsToNewTrip copies the incomplete items in a trip to the new
+// trip, and returns the ID of each copy mapped to the ID of its original
 func CopyRemainingItemsToNewTrip(
 	trip models.GroceryTrip,
 	newTrip *models.GroceryTrip,
 	tx *gorm.DB,
-) (err error) {
+) (copiedItemIDs map[uuid.UUID]uuid.UUID, err error) {
+	copiedItemIDs = map[uuid.UUID]uuid.UUID{}
+
 	// Fetch the remaining items, excluding those that are staple items (since these will be added anyway)
 	var remainingItems []models.Item
 	itemsQuery := tx.
@@ -83,7 +143,7 @@ func CopyRemainingItemsToNewTrip(
 		Find(&remainingItems).
 		Error
 	if err := itemsQuery; err != nil {
-		return err
+		return copiedItemIDs, err
 	}
 
 	var newItems []models.Item
@@ -99,7 +159,7 @@ func CopyRemainingItemsToNewTrip(
 			Find(&storeCategory).
 			Error
 		if err := storeCategoryQuery; err != nil {
-			return err
+			return copiedItemIDs, err
 		}
 		// Note: uses FirstOrCreate to handle the case where there are multiple items in same category
 		// that need to be moved over to the next trip
@@ -108,7 +168,7 @@ func CopyRemainingItemsToNewTrip(
 			StoreCategoryID: storeCategory.ID,
 		}
 		if err := tx.Where(groceryTripCategory).FirstOrCreate(&groceryTripCategory).Error; err != nil {
-			return err
+			return copiedItemIDs, err
 		}
 
 		// Copy old item to new item and update values
@@ -121,54 +181,130 @@ func CopyRemainingItemsToNewTrip(
 		newItems = append(newItems, newItem)
 	}
 
+	if len(newItems) == 0 {
+		return copiedItemIDs, nil
+	}
+
 	// Batch insert items in new trip
 	if err := tx.Create(&newItems).Error; err != nil {
-		return err
+		return copiedItemIDs, err
 	}
-	return
+	for i := range newItems {
+		copiedItemIDs[newItems[i].ID] = remainingItems[i].ID
+	}
+	return copiedItemIDs, nil
 }
 
-// MarkItemsInOldTripAsCompleted marks each item in the old trip as completed
+// MarkItemsInOldTripAsCompleted marks each item in the old trip as completed,
+// and returns the IDs of the items it marked
 //
 // This uses UpdateColumn to avoid hooks
 // (https://gorm.io/docs/update.html#Without-Hooks-Time-Tracking)
-func MarkItemsInOldTripAsCompleted(trip models.GroceryTrip, tx *gorm.DB) (err error) {
-	updateItemsQuery := tx.
+func MarkItemsInOldTripAsCompleted(trip models.GroceryTrip, tx *gorm.DB) (itemIDs []uuid.UUID, err error) {
+	itemsQuery := tx.
 		Model(&models.Item{}).
 		Where("grocery_trip_id = ? AND completed = ?", trip.ID, false).
+		Pluck("id", &itemIDs).
+		Error
+	if err := itemsQuery; err != nil {
+		return itemIDs, err
+	}
+	if len(itemIDs) == 0 {
+		return itemIDs, nil
+	}
+
+	updateItemsQuery := tx.
+		Model(&models.Item{}).
+		Where("id IN ?", itemIDs).
 		UpdateColumn("completed", true).
 		Error
 	if err := updateItemsQuery; err != nil {
-		return err
+		return itemIDs, err
 	}
-	return
+	return itemIDs, nil
 }
 
-// AddStapleItemsToNewTrip adds items set as staple items for this store to the new trip
+// AddStapleItemsToNewTrip adds the staple items for this store that are due
+// (according to their cadence) to the new trip
 func AddStapleItemsToNewTrip(trip models.GroceryTrip) (err error) {
+	_, err = addStapleItemsToTrip(trip)
+	return err
+}
+
+// addStapleItemsToTrip adds the staple items that are due to a trip, and
+// returns when each staple item it added had last been added before
+func addStapleItemsToTrip(trip models.GroceryTrip) (lastAddedAt map[uuid.UUID]*time.Time, err error) {
+	lastAddedAt = map[uuid.UUID]*time.Time{}
 	var store models.Store
 	if err := db.Manager.Select("id, user_id").Where("id = ?", trip.StoreID).First(&store).Error; err != nil {
-		return err
+		return lastAddedAt, err
 	}
 
 	var stapleItems []models.StoreStapleItem
 	if err := db.Manager.Where("store_id = ?", store.ID).Find(&stapleItems).Error; err != nil {
-		return err
+		return lastAddedAt, err
+	}
+
+	// Staples are due based on the trip's planned shopping date, if it has one
+	date := time.Now()
+	if trip.ShoppingDate != nil {
+		if shoppingDate, err := time.Parse(models.ShoppingDateLayout, *trip.ShoppingDate); err == nil {
+			date = shoppingDate
+		}
 	}
 
 	for i := range stapleItems {
 		stapleItem := stapleItems[i]
-		args := map[string]interface{}{
-			"tripId":       trip.ID,
-			"name":         stapleItem.Name,
-			"stapleItemId": stapleItem.ID,
+		var tripsSinceAdded int64
+		if stapleItem.Cadence == models.StapleCadenceTrips {
+			if tripsSinceAdded, err = stapleItem.TripsSinceAdded(db.Manager); err != nil {
+				return lastAddedAt, err
+			}
+		}
+		if !stapleItem.DueOn(date, tripsSinceAdded) {
+			continue
 		}
+
+		args := stapleItemArgs(stapleItem)
+		args["tripId"] = trip.ID
+		// Staple items are added on behalf of the store creator, and aren't
+		// recorded in the activity log
 		userID := store.UserID
-		_, err := AddItem(userID, args)
+		item, _, err := addItem(db.Manager, userID, args)
 		if err != nil {
-			return err
+			return lastAddedAt, err
+		}
+		if item.UnitPrice != nil {
+			checkTripBudget(item.GroceryTripID)
+		}
+		lastAddedAt[stapleItem.ID] = stapleItem.LastAddedAt
+		if err := db.Manager.Model(&stapleItem).UpdateColumn("last_added_at", time.Now()).Error; err != nil {
+			return lastAddedAt, err
 		}
 	}
 
-	return
+	return lastAddedAt, nil
+}
+
+// stapleItemArgs builds the args for adding a staple item to a trip, so that
+// its quantity, notes and pinned category carry over to the item
+func stapleItemArgs(stapleItem models.StoreStapleItem) map[string]interface{} {
+	args := map[string]interface{}{
+		"name":         stapleItem.Name,
+		"quantity":     stapleItem.Quantity,
+		"stapleItemId": stapleItem.ID,
+	}
+	if stapleItem.DecimalQuantity != nil {
+		args["decimalQuantity"] = *stapleItem.DecimalQuantity
+	}
+	if stapleItem.Unit != nil {
+		args["unit"] = *stapleItem.Unit
+	}
+	if stapleItem.Notes != nil {
+		args["notes"] = *stapleItem.Notes
+	}
+	if stapleItem.StoreCategoryID != nil {
+		args["pinnedStoreCategoryId"] = *stapleItem.StoreCategoryID
+	}
+	return args
 }
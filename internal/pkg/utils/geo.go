@@ -0,0 +1,28 @@
+package utils
+
+import "math"
+
+// earthRadius is the mean radius of the Earth in metres
+const earthRadius = 6371000.0
+
+// HaversineDistance returns the great-circle distance in metres between two
+// points given in decimal degrees
+func HaversineDistance(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := degreesToRadians(lat2 - lat1)
+	dLng := degreesToRadians(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(degreesToRadians(lat1))*math.Cos(degreesToRadians(lat2))*
+			math.Sin(dLng/2)*math.Sin(dLng/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	return earthRadius * c
+}
+
+// ValidCoordinates returns whether the latitude and longitude provided are
+// within range
+func ValidCoordinates(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+func degreesToRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
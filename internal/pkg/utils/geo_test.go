@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHaversineDistance_SamePoint(t *testing.T) {
+	assert.Equal(t, 0.0, HaversineDistance(43.6532, -79.3832, 43.6532, -79.3832))
+}
+
+func TestHaversineDistance_KnownDistance(t *testing.T) {
+	// Toronto to Montreal is roughly 504km as the crow flies
+	distance := HaversineDistance(43.6532, -79.3832, 45.5017, -73.5673)
+	assert.InDelta(t, 504000, distance, 2000)
+}
+
+func TestHaversineDistance_Symmetric(t *testing.T) {
+	there := HaversineDistance(51.5074, -0.1278, 48.8566, 2.3522)
+	back := HaversineDistance(48.8566, 2.3522, 51.5074, -0.1278)
+	assert.InDelta(t, there, back, 0.001)
+}
+
+func TestHaversineDistance_AcrossAntimeridian(t *testing.T) {
+	// 0.2 degrees of longitude at the equator is roughly 22km
+	distance := HaversineDistance(0, 179.9, 0, -179.9)
+	assert.InDelta(t, 22239, distance, 10)
+}
+
+func TestValidCoordinates(t *testing.T) {
+	assert.True(t, ValidCoordinates(43.6532, -79.3832))
+	assert.True(t, ValidCoordinates(-90, 180))
+	assert.False(t, ValidCoordinates(90.1, 0))
+	assert.False(t, ValidCoordinates(0, -180.5))
+}
@@ -11,17 +11,24 @@ import (
 	// Autoload env variables from .env
 	_ "github.com/joho/godotenv/autoload"
 
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/autocomplete"
 	"github.com/bradpurchase/grocerytime-backend/internal/pkg/db"
+	"github.com/bradpurchase/grocerytime-backend/internal/pkg/jobs"
 
 	"github.com/gorilla/mux"
 )
 
 func main() {
 	db.Factory()
+	if err := autocomplete.RegisterCallbacks(db.Manager); err != nil {
+		log.Fatal("[main] Couldn't register autocomplete callbacks! ", err)
+	}
+	jobs.Start()
 
 	router := mux.NewRouter().StrictSlash(true)
 	router.HandleFunc("/", heartbeat)
 	router.Handle("/graphql", corsHandler(handlers.GraphQLHandler()))
+	router.PathPrefix("/blobs/").Handler(handlers.BlobHandler())
 
 	port := os.Getenv("PORT")
 	log.Println("[main] ⚡️...Listening on port " + port)